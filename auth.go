@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+// TryAuthorize выполняет авторизацию с указанными учётными данными, не прерывая тест при отказе.
+// Возвращает статус ответа и токен, если авторизация прошла успешно
+func TryAuthorize(t *testing.T, user, pass string) (int, string) {
+	requestBody := map[string]string{
+		"login":    user,
+		"password": pass,
+	}
+	resp, _ := makeRequest(t, "POST", apiURL("/api/authorize"), requestBody, map[string]string{
+		"Content-Type": "application/json",
+	})
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return resp.StatusCode, ""
+	}
+
+	var response AuthResponse
+	parseResponseBody(t, resp, &response)
+	return resp.StatusCode, response.RefreshToken
+}
+
+// AuthorizeAs выполняет авторизацию с указанными учётными данными и возвращает токен,
+// не меняя токен, которым пользуются остальные функции
+func AuthorizeAs(t *testing.T, user, pass string) string {
+	status, token := TryAuthorize(t, user, pass)
+	if status != http.StatusOK {
+		Fatalf(t, "Ожидался статус 200 при авторизации %s, получен: %d", user, status)
+	}
+	if token == "" {
+		Fatalf(t, "Поле refresh_token пустое в ответе API для %s", user)
+	}
+	return token
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"dbaas_testing_task/metrics"
+	"dbaas_testing_task/runstate"
+)
+
+// CreateDump создаёт дамп базы данных, дожидается статуса OK и возвращает его ID
+func CreateDump(t *testing.T, clusterId, dbId, name string) string {
+	started := time.Now()
+	resp, _ := makeRequest(t, "POST", apiURL("/api/clusters/%s/databases/%s/dumps", clusterId, dbId), CreateDumpRequest{Name: name}, authHeaders())
+	if resp.StatusCode != http.StatusCreated {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 201 при создании дампа, получен: %d", resp.StatusCode)
+	}
+
+	var dump Dump
+	parseResponseBody(t, resp, &dump)
+	Logf(t, "Database dump created with ID: %s", dump.Id)
+	JournalResource(t, runstate.KindDump, dump.Id, clusterId, name)
+
+	defer RecordOperation(t, metrics.OpDumpCreate, started)
+	WaitForStatus(t, "Dump", apiURL("/api/dumps/%s", dump.Id))
+	return dump.Id
+}
+
+// ListDumps возвращает дампы базы данных
+func ListDumps(t *testing.T, clusterId, dbId string) []Dump {
+	resp, _ := makeRequest(t, "GET", apiURL("/api/clusters/%s/databases/%s/dumps", clusterId, dbId), nil, authHeaders())
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 200 при получении списка дампов, получен: %d", resp.StatusCode)
+	}
+	var dumps []Dump
+	parseResponseBody(t, resp, &dumps)
+	return dumps
+}
+
+// GetDump возвращает дамп по ID
+func GetDump(t *testing.T, dumpId string) Dump {
+	resp, _ := makeRequest(t, "GET", apiURL("/api/dumps/%s", dumpId), nil, authHeaders())
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 200 при получении дампа %s, получен: %d", dumpId, resp.StatusCode)
+	}
+	var dump Dump
+	parseResponseBody(t, resp, &dump)
+	return dump
+}
+
+// RestoreDump восстанавливает базу данных из дампа и дожидается статуса OK дампа и базы данных
+func RestoreDump(t *testing.T, clusterId, dbId, dumpId string) {
+	request := RestoreDumpRequest{DumpID: dumpId, Mode: "full", RestoreUsers: false}
+	started := time.Now()
+	resp, _ := makeRequest(t, "POST", apiURL("/api/clusters/%s/databases/%s/dump_restore", clusterId, dbId), request, authHeaders())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusAccepted {
+		Fatalf(t, "Ожидался успешный статус при восстановлении из дампа %s, получен: %d", dumpId, resp.StatusCode)
+	}
+	defer RecordOperation(t, metrics.OpDumpRestore, started)
+	WaitForStatus(t, "Dump", apiURL("/api/dumps/%s", dumpId))
+	WaitForStatus(t, "Database", apiURL("/api/clusters/%s/databases/%s", clusterId, dbId))
+	Logf(t, "Database %s restored from dump %s", dbId, dumpId)
+}
+
+// DeleteDump удаляет дамп. Уже удалённый дамп не считается ошибкой
+func DeleteDump(t *testing.T, dumpId string) {
+	resp, _ := makeRequest(t, "DELETE", apiURL("/api/dumps/%s", dumpId), nil, authHeaders())
+	resp.Body.Close()
+	// Может быть удалён вместе с исходным кластером
+	if resp.StatusCode == http.StatusNotFound {
+		ForgetResource(t, runstate.KindDump, dumpId)
+		return
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		Errorf(t, "Ожидался статус 204 при удалении дампа %s, получен: %d", dumpId, resp.StatusCode)
+		return
+	}
+	ForgetResource(t, runstate.KindDump, dumpId)
+	Logf(t, "Deleted dump with ID: %s", dumpId)
+}
+
+// CreateBackup создаёт резервную копию кластера, дожидается статуса OK и возвращает её ID
+func CreateBackup(t *testing.T, clusterId string) string {
+	started := time.Now()
+	resp, _ := makeRequest(t, "POST", apiURL("/api/clusters/%s/backups", clusterId), nil, authHeaders())
+	if resp.StatusCode != http.StatusCreated {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 201 при создании резервной копии, получен: %d", resp.StatusCode)
+	}
+
+	var backup Backup
+	parseResponseBody(t, resp, &backup)
+	Logf(t, "Cluster backup created with ID: %s", backup.Id)
+	JournalResource(t, runstate.KindBackup, backup.Id, clusterId, "")
+
+	defer RecordOperation(t, metrics.OpBackupCreate, started)
+	WaitForStatus(t, "Backup", apiURL("/api/backups/%s", backup.Id))
+	return backup.Id
+}
+
+// ListBackups возвращает резервные копии кластера
+func ListBackups(t *testing.T, clusterId string) []Backup {
+	resp, _ := makeRequest(t, "GET", apiURL("/api/clusters/%s/backups", clusterId), nil, authHeaders())
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 200 при получении списка резервных копий, получен: %d", resp.StatusCode)
+	}
+	var backups []Backup
+	parseResponseBody(t, resp, &backups)
+	return backups
+}
+
+// DeleteBackup удаляет резервную копию. Уже удалённая копия не считается ошибкой
+func DeleteBackup(t *testing.T, backupId string) {
+	resp, _ := makeRequest(t, "DELETE", apiURL("/api/backups/%s", backupId), nil, authHeaders())
+	resp.Body.Close()
+	// Может быть удалён вместе с исходным кластером
+	if resp.StatusCode == http.StatusNotFound {
+		ForgetResource(t, runstate.KindBackup, backupId)
+		return
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		Errorf(t, "Ожидался статус 204 при удалении резервной копии %s, получен: %d", backupId, resp.StatusCode)
+		return
+	}
+	ForgetResource(t, runstate.KindBackup, backupId)
+	Logf(t, "Deleted backup with ID: %s", backupId)
+}
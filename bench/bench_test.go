@@ -0,0 +1,38 @@
+package bench
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteTable(t *testing.T) {
+	results := []Result{
+		{Flavor: "STD3-1-1", Clients: 8, Threads: 8, Transactions: 6000, TPS: 100, P95Ms: 95.5},
+		{Flavor: "STD3-2-4", Clients: 8, Threads: 2, Transactions: 12000, TPS: 200, Errors: 3},
+		{Flavor: "STD3-1-1", Clients: 1, Threads: 1, Transactions: 3000, TPS: 50},
+	}
+	var out bytes.Buffer
+	assert.NoError(t, WriteTable(&out, results))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if assert.Len(t, lines, 4) {
+		assert.Contains(t, lines[0], "ПОТОКОВ")
+		// Сначала меньшее число клиентов, внутри - по убыванию TPS
+		assert.True(t, strings.HasPrefix(lines[1], "STD3-1-1  1         1 "), lines[1])
+		assert.Contains(t, lines[1], "100%")
+		assert.True(t, strings.HasPrefix(lines[2], "STD3-2-4  8         2 "), lines[2])
+		assert.Contains(t, lines[2], "200.0")
+		assert.True(t, strings.HasPrefix(lines[3], "STD3-1-1  8 "), lines[3])
+		assert.Contains(t, lines[3], "50%")
+		assert.Contains(t, lines[3], "95.50")
+	}
+
+	var data bytes.Buffer
+	assert.NoError(t, WriteJSON(&data, results))
+	var decoded []Result
+	assert.NoError(t, json.Unmarshal(data.Bytes(), &decoded))
+	assert.Equal(t, results, decoded)
+}
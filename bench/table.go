@@ -0,0 +1,46 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// WriteTable выводит таблицу сравнения результатов по flavor и числу клиентов. Для каждого числа
+// клиентов указывается TPS относительно лучшего flavor
+func WriteTable(w io.Writer, results []Result) error {
+	sorted := append([]Result(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Clients != sorted[j].Clients {
+			return sorted[i].Clients < sorted[j].Clients
+		}
+		return sorted[i].TPS > sorted[j].TPS
+	})
+	best := make(map[int]float64)
+	for _, r := range sorted {
+		if r.TPS > best[r.Clients] {
+			best[r.Clients] = r.TPS
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAVOR\tКЛИЕНТОВ\tПОТОКОВ\tТРАНЗАКЦИЙ\tОШИБОК\tTPS\tОТ ЛУЧШЕГО\tСРЕДНЕЕ МС\tP50 МС\tP95 МС\tP99 МС\tMAX МС")
+	for _, r := range sorted {
+		relative := 0.0
+		if best[r.Clients] > 0 {
+			relative = r.TPS / best[r.Clients] * 100
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.1f\t%.0f%%\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\n",
+			r.Flavor, r.Clients, r.Threads, r.Transactions, r.Errors, r.TPS, relative, r.MeanMs, r.P50Ms, r.P95Ms, r.P99Ms, r.MaxMs)
+	}
+	return tw.Flush()
+}
+
+// WriteJSON выводит результаты в JSON
+func WriteJSON(w io.Writer, results []Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
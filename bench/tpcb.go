@@ -0,0 +1,223 @@
+// Package bench выполняет нагрузочный тест в духе pgbench: создаёт схему TPC-B, запускает
+// транзакции TPC-B от нескольких клиентов и считает пропускную способность и перцентили задержки.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"dbaas_testing_task/soak"
+)
+
+// Schema - схема, в которой создаются таблицы теста
+const Schema = "bench"
+
+// Размер таблиц на единицу масштаба, как у pgbench
+const (
+	branchesPerScale = 1
+	tellersPerScale  = 10
+	accountsPerScale = 100000
+)
+
+// Config содержит параметры одного запуска.
+type Config struct {
+	// Scale - масштаб данных, заданный при Init
+	Scale int
+	// Clients - число клиентов, у каждого своё соединение на всё время запуска
+	Clients int
+	// Threads - число рабочих горутин, между которыми клиенты распределяются по кругу, как pgbench -j.
+	// Горутина выполняет транзакции своих клиентов по очереди, поэтому одновременно выполняется
+	// не больше Threads транзакций. 0 или значение больше Clients означает горутину на каждого клиента
+	Threads  int
+	Duration time.Duration
+}
+
+// Result представляет итоги запуска.
+type Result struct {
+	Flavor       string  `json:"flavor"`
+	Scale        int     `json:"scale"`
+	Clients      int     `json:"clients"`
+	Threads      int     `json:"threads"`
+	DurationSec  float64 `json:"duration_sec"`
+	Transactions int64   `json:"transactions"`
+	Errors       int64   `json:"errors"`
+	TPS          float64 `json:"tps"`
+	MeanMs       float64 `json:"mean_ms"`
+	P50Ms        float64 `json:"p50_ms"`
+	P95Ms        float64 `json:"p95_ms"`
+	P99Ms        float64 `json:"p99_ms"`
+	MaxMs        float64 `json:"max_ms"`
+	// LastError хранит текст последней ошибки для диагностики
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Init пересоздаёт таблицы TPC-B в схеме Schema и заполняет их данными масштаба scale
+func Init(ctx context.Context, pool *pgxpool.Pool, scale int) error {
+	if scale < 1 {
+		return fmt.Errorf("некорректный масштаб %d", scale)
+	}
+	statements := []string{
+		`CREATE SCHEMA IF NOT EXISTS ` + Schema,
+		`DROP TABLE IF EXISTS bench.pgbench_history, bench.pgbench_tellers, bench.pgbench_accounts, bench.pgbench_branches`,
+		`CREATE TABLE bench.pgbench_branches (bid INT PRIMARY KEY, bbalance INT NOT NULL, filler CHAR(88))`,
+		`CREATE TABLE bench.pgbench_tellers (tid INT PRIMARY KEY, bid INT NOT NULL, tbalance INT NOT NULL, filler CHAR(84))`,
+		`CREATE TABLE bench.pgbench_accounts (aid INT PRIMARY KEY, bid INT NOT NULL, abalance INT NOT NULL, filler CHAR(84))`,
+		`CREATE TABLE bench.pgbench_history (tid INT, bid INT, aid INT, delta INT, mtime TIMESTAMP, filler CHAR(22))`,
+		fmt.Sprintf(`INSERT INTO bench.pgbench_branches (bid, bbalance) SELECT bid, 0 FROM generate_series(1, %d) AS bid`,
+			branchesPerScale*scale),
+		fmt.Sprintf(`INSERT INTO bench.pgbench_tellers (tid, bid, tbalance) SELECT tid, (tid - 1) / %d + 1, 0 FROM generate_series(1, %d) AS tid`,
+			tellersPerScale, tellersPerScale*scale),
+		fmt.Sprintf(`INSERT INTO bench.pgbench_accounts (aid, bid, abalance, filler) SELECT aid, (aid - 1) / %d + 1, 0, '' FROM generate_series(1, %d) AS aid`,
+			accountsPerScale, accountsPerScale*scale),
+		`VACUUM ANALYZE bench.pgbench_branches, bench.pgbench_tellers, bench.pgbench_accounts, bench.pgbench_history`,
+	}
+	for _, statement := range statements {
+		if _, err := pool.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("не удалось подготовить данные TPC-B: %w", err)
+		}
+	}
+	return nil
+}
+
+// Run выполняет транзакции TPC-B от cfg.Clients клиентов в cfg.Threads горутинах в течение
+// cfg.Duration или до отмены ctx. Транзакции, прерванные окончанием теста, не учитываются
+func Run(ctx context.Context, pool *pgxpool.Pool, cfg Config) Result {
+	threads := cfg.Threads
+	if threads <= 0 || threads > cfg.Clients {
+		threads = cfg.Clients
+	}
+	result := Result{Scale: cfg.Scale, Clients: cfg.Clients, Threads: threads}
+
+	// Соединения берутся из пула до начала отсчёта времени
+	conns := make([]*pgxpool.Conn, 0, cfg.Clients)
+	defer func() {
+		for _, conn := range conns {
+			conn.Release()
+		}
+	}()
+	for c := 0; c < cfg.Clients; c++ {
+		conn, err := pool.Acquire(ctx)
+		if err != nil {
+			result.Errors++
+			result.LastError = fmt.Sprintf("не удалось получить соединение клиента %d: %v", c+1, err)
+			return result
+		}
+		conns = append(conns, conn)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	var mu sync.Mutex
+	var latency soak.Histogram
+	var wg sync.WaitGroup
+	started := time.Now()
+	for w := 0; w < threads; w++ {
+		var clients []*pgxpool.Conn
+		for c := w; c < len(conns); c += threads {
+			clients = append(clients, conns[c])
+		}
+		wg.Add(1)
+		go func(workerID int, clients []*pgxpool.Conn) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(time.Now().UnixNano() + int64(workerID)))
+			var local soak.Histogram
+			var errorCount int64
+			var lastErr error
+			for ctx.Err() == nil {
+				for _, conn := range clients {
+					txStarted := time.Now()
+					err := transaction(ctx, conn, r, cfg.Scale)
+					if ctx.Err() != nil {
+						break
+					}
+					if err != nil {
+						errorCount++
+						lastErr = err
+						continue
+					}
+					local.Add(time.Since(txStarted))
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			latency.Merge(&local)
+			result.Errors += errorCount
+			if lastErr != nil {
+				result.LastError = lastErr.Error()
+			}
+		}(w, clients)
+	}
+	wg.Wait()
+	elapsed := time.Since(started)
+
+	result.DurationSec = elapsed.Seconds()
+	result.Transactions = latency.Count()
+	if elapsed > 0 {
+		result.TPS = float64(result.Transactions) / elapsed.Seconds()
+	}
+	result.MeanMs = milliseconds(latency.Mean())
+	result.P50Ms = milliseconds(latency.Quantile(0.5))
+	result.P95Ms = milliseconds(latency.Quantile(0.95))
+	result.P99Ms = milliseconds(latency.Quantile(0.99))
+	result.MaxMs = milliseconds(latency.Max())
+	return result
+}
+
+// transaction выполняет транзакцию TPC-B встроенного сценария pgbench
+func transaction(ctx context.Context, conn *pgxpool.Conn, r *rand.Rand, scale int) error {
+	aid := r.Intn(accountsPerScale*scale) + 1
+	bid := r.Intn(branchesPerScale*scale) + 1
+	tid := r.Intn(tellersPerScale*scale) + 1
+	delta := r.Intn(10001) - 5000
+	return conn.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `UPDATE bench.pgbench_accounts SET abalance = abalance + $1 WHERE aid = $2`, delta, aid); err != nil {
+			return err
+		}
+		var balance int
+		if err := tx.QueryRow(ctx, `SELECT abalance FROM bench.pgbench_accounts WHERE aid = $1`, aid).Scan(&balance); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE bench.pgbench_tellers SET tbalance = tbalance + $1 WHERE tid = $2`, delta, tid); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE bench.pgbench_branches SET bbalance = bbalance + $1 WHERE bid = $2`, delta, bid); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `INSERT INTO bench.pgbench_history (tid, bid, aid, delta, mtime) VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)`,
+			tid, bid, aid, delta)
+		return err
+	})
+}
+
+// Verify проверяет согласованность данных после запуска: суммы балансов счетов, кассиров и
+// отделений совпадают с суммой изменений в истории
+func Verify(ctx context.Context, pool *pgxpool.Pool) error {
+	var accounts, tellers, branches, history int64
+	err := pool.QueryRow(ctx, `
+		SELECT
+			(SELECT coalesce(sum(abalance), 0) FROM bench.pgbench_accounts),
+			(SELECT coalesce(sum(tbalance), 0) FROM bench.pgbench_tellers),
+			(SELECT coalesce(sum(bbalance), 0) FROM bench.pgbench_branches),
+			(SELECT coalesce(sum(delta), 0) FROM bench.pgbench_history)
+	`).Scan(&accounts, &tellers, &branches, &history)
+	if err != nil {
+		return fmt.Errorf("не удалось проверить данные TPC-B: %w", err)
+	}
+	if accounts != history || tellers != history || branches != history {
+		return fmt.Errorf("данные TPC-B несогласованы: счета %d, кассиры %d, отделения %d, история %d",
+			accounts, tellers, branches, history)
+	}
+	return nil
+}
+
+// milliseconds переводит длительность в миллисекунды с точностью до микросекунды
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
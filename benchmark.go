@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// BenchConfig содержит параметры сравнения flavor по пропускной способности.
+type BenchConfig struct {
+	// Flavors - имена сравниваемых flavor; пустой список выключает сравнение, "all" выбирает все
+	Flavors []string
+	// Clients - числа клиентов, с которыми выполняется тест на каждом flavor
+	Clients []int
+	// Threads - число рабочих горутин клиентов (как pgbench -j); 0 - горутина на каждого клиента
+	Threads  int
+	Scale    int
+	Duration time.Duration
+	// ReportFile - путь к результатам в JSON; пустое значение означает только вывод в лог
+	ReportFile string
+}
+
+// LoadBenchConfig считывает параметры сравнения flavor из переменных окружения
+func LoadBenchConfig(t *testing.T) BenchConfig {
+	cfg := BenchConfig{
+		Clients:    []int{1, 8},
+		Scale:      1,
+		Duration:   time.Minute,
+		ReportFile: os.Getenv("BENCH_REPORT"),
+	}
+	for _, name := range strings.Split(os.Getenv("BENCH_FLAVORS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.Flavors = append(cfg.Flavors, name)
+		}
+	}
+	if v := os.Getenv("BENCH_CLIENTS"); v != "" {
+		cfg.Clients = nil
+		for _, item := range strings.Split(v, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(item))
+			if err != nil || n < 1 {
+				Fatalf(t, "Некорректное значение BENCH_CLIENTS: %q", v)
+			}
+			cfg.Clients = append(cfg.Clients, n)
+		}
+	}
+	if v := os.Getenv("BENCH_THREADS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			Fatalf(t, "Некорректное значение BENCH_THREADS: %q", v)
+		}
+		cfg.Threads = n
+	}
+	if v := os.Getenv("BENCH_SCALE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			Fatalf(t, "Некорректное значение BENCH_SCALE: %q", v)
+		}
+		cfg.Scale = n
+	}
+	if v := os.Getenv("BENCH_DURATION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			Fatalf(t, "Некорректное значение BENCH_DURATION: %q", v)
+		}
+		cfg.Duration = d
+	}
+	return cfg
+}
+
+// maxClients возвращает наибольшее число клиентов, под которое нужен пул соединений
+func (c BenchConfig) maxClients() int {
+	max := 1
+	for _, n := range c.Clients {
+		if n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+// SelectFlavors возвращает flavor из каталога API по именам из конфигурации
+func SelectFlavors(t *testing.T, names []string) []Flavor {
+	flavors := ListFlavors(t)
+	if len(names) == 1 && names[0] == "all" {
+		return flavors
+	}
+	var selected []Flavor
+	for _, name := range names {
+		found := false
+		for _, flavor := range flavors {
+			if flavor.Name == name {
+				selected = append(selected, flavor)
+				found = true
+				break
+			}
+		}
+		if !found {
+			Fatalf(t, "Flavor %s отсутствует в каталоге API", name)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"dbaas_testing_task/bench"
+)
+
+func TestFlavorBenchmark(t *testing.T) {
+	RequireAPIEnv(t)
+	cfg := LoadBenchConfig(t)
+	if len(cfg.Flavors) == 0 {
+		t.Skip("Сравнение flavor выключено: задайте BENCH_FLAVORS")
+	}
+	Authorize(t)
+	ctx := context.Background()
+
+	var results []bench.Result
+	for _, flavor := range SelectFlavors(t, cfg.Flavors) {
+		flavor := flavor
+		// Кластер каждого flavor удаляется по завершении подтеста, до создания следующего
+		t.Run(flavor.Name, func(t *testing.T) {
+			request := DefaultClusterRequest(t, "bench-"+RandomSuffix())
+			request.FlavorID = flavor.Id
+			clusterId := ProvisionCluster(t, request)
+
+			dbName := "benchDB"
+			CreateDatabase(t, clusterId, CreateDBRequest{Name: dbName, TableSpaceID: GetDefaultTableSpaceID(t, clusterId)})
+			dbUser := DBUserCredentials(t, "bench")
+			CreateClusterUser(t, clusterId, CreateClusterUserRequest{
+				Databases: []string{dbName},
+				Roles:     []string{"pg_write_all_data", "pg_read_all_data"},
+				Name:      dbUser.Username,
+				Password:  dbUser.Password,
+			})
+			// Заполнение данных большого масштаба может идти дольше обычного таймаута запроса
+			poolConfig := PoolConfig{MaxConns: int32(cfg.maxClients()), HealthCheckPeriod: time.Minute}
+			session := NewDBSession(t, ctx, ConnectionString(t, clusterId, dbName, dbUser.Username, dbUser.Password), poolConfig)
+
+			if err := bench.Init(ctx, session.Pool(), cfg.Scale); err != nil {
+				Fatalf(t, "%v", err)
+			}
+			Logf(t, "TPC-B data initialized with scale %d", cfg.Scale)
+
+			for _, clients := range cfg.Clients {
+				result := bench.Run(ctx, session.Pool(), bench.Config{Scale: cfg.Scale, Clients: clients, Threads: cfg.Threads, Duration: cfg.Duration})
+				result.Flavor = flavor.Name
+				results = append(results, result)
+				Logf(t, "Flavor %s, %d clients, %d threads: %.1f TPS, p95 %.2f ms, %d errors", flavor.Name, clients, result.Threads, result.TPS, result.P95Ms, result.Errors)
+				if result.Errors > 0 {
+					Errorf(t, "Транзакции TPC-B завершились с ошибками: %d (последняя: %s)", result.Errors, result.LastError)
+				}
+			}
+			if err := bench.Verify(ctx, session.Pool()); err != nil {
+				Errorf(t, "%v", err)
+			}
+		})
+	}
+
+	var table strings.Builder
+	if err := bench.WriteTable(&table, results); err != nil {
+		Errorf(t, "Не удалось сформировать таблицу сравнения: %v", err)
+	}
+	Logf(t, "Сравнение flavor (TPC-B, масштаб %d, %s на запуск):\n%s", cfg.Scale, cfg.Duration, table.String())
+	if cfg.ReportFile != "" {
+		if err := writeBenchReport(cfg.ReportFile, results); err != nil {
+			Errorf(t, "%v", err)
+		}
+	}
+}
+
+// writeBenchReport сохраняет результаты сравнения в JSON
+func writeBenchReport(path string, results []bench.Result) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("не удалось создать файл результатов сравнения: %w", err)
+	}
+	err = bench.WriteJSON(file, results)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("не удалось записать результаты сравнения %s: %w", path, err)
+	}
+	return nil
+}
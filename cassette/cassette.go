@@ -0,0 +1,259 @@
+// Package cassette записывает HTTP-обмен с API в файл-кассету и воспроизводит его без обращения
+// к API, чтобы повторить упавший запуск офлайн и выполнять тесты детерминированно.
+//
+// Кассета хранится в формате JSON Lines: по одному обмену запросом и ответом в строке, в порядке
+// выполнения. Запись дописывает строку сразу после каждого ответа, поэтому кассета сохраняется
+// и при аварийном завершении процесса. Заголовок Authorization и секретные поля JSON-тел
+// (пароли и токены) в кассету не попадают.
+//
+// Первая строка кассеты - заголовок с seed генератора имён ресурсов: при воспроизведении тесты
+// получают те же случайные имена, что и при записи, и находят записанные ресурсы по имени.
+package cassette
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Cassette представляет записанный запуск
+type Cassette struct {
+	// Seed - seed генератора имён ресурсов при записи; 0, если кассета записана без заголовка
+	Seed         int64
+	Interactions []Interaction
+}
+
+// header представляет первую строку кассеты
+type header struct {
+	Seed int64 `json:"seed"`
+}
+
+// Interaction представляет обмен запросом и ответом
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+	// LatencyMs - время ответа при записи
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// Request представляет записанный запрос. URL содержит путь и строку запроса без адреса API
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response представляет записанный ответ
+type Response struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Load читает кассету из файла
+func Load(path string) (Cassette, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Cassette{}, fmt.Errorf("не удалось открыть кассету: %w", err)
+	}
+	defer f.Close()
+
+	var c Cassette
+	scanner := bufio.NewScanner(f)
+	// Тела ответов со списками ресурсов не помещаются в буфер по умолчанию
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var i struct {
+			Interaction
+			header
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &i); err != nil {
+			return Cassette{}, fmt.Errorf("%s:%d: не удалось разобрать обмен: %w", path, line, err)
+		}
+		if i.Request.Method == "" {
+			c.Seed = i.Seed
+			continue
+		}
+		c.Interactions = append(c.Interactions, i.Interaction)
+	}
+	if err := scanner.Err(); err != nil {
+		return Cassette{}, fmt.Errorf("не удалось прочитать кассету: %w", err)
+	}
+	return c, nil
+}
+
+// Recorder - RoundTripper, который выполняет запросы через Next и дописывает каждый обмен в кассету
+type Recorder struct {
+	// Next выполняет запросы; по умолчанию http.DefaultTransport
+	Next http.RoundTripper
+
+	mu   sync.Mutex
+	file *os.File
+	seed int64
+}
+
+// NewRecorder создаёт кассету path, перезаписывая существующую, и записывает в неё заголовок
+// со случайным seed
+func NewRecorder(path string, next http.RoundTripper) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать кассету: %w", err)
+	}
+	r := &Recorder{Next: next, file: f, seed: rand.Int63()}
+	if err := r.write(header{Seed: r.seed}); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Seed возвращает seed генератора имён ресурсов, записанный в заголовок кассеты
+func (r *Recorder) Seed() int64 {
+	return r.seed
+}
+
+// RoundTrip выполняет запрос и записывает обмен. Ошибки соединения не записываются:
+// воспроизвести их нечем
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	requestBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	started := time.Now()
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	latency := time.Since(started)
+	responseBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     req.URL.RequestURI(),
+			Headers: redactHeaders(req.Header),
+			Body:    redactBody(requestBody),
+		},
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: redactHeaders(resp.Header),
+			Body:    redactBody(responseBody),
+		},
+		LatencyMs: float64(latency) / float64(time.Millisecond),
+	}
+	if err := r.write(interaction); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (r *Recorder) write(line interface{}) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("не удалось записать кассету: %w", err)
+	}
+	return nil
+}
+
+// Close закрывает файл кассеты
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// Replayer - RoundTripper, который отвечает из кассеты без обращения к API. Запросу соответствует
+// первый ещё не использованный обмен с тем же методом и URL; тела запросов не сравниваются, так как
+// содержат случайные имена ресурсов
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer создаёт воспроизведение записанных обменов
+func NewReplayer(interactions []Interaction) *Replayer {
+	return &Replayer{interactions: interactions, used: make([]bool, len(interactions))}
+}
+
+// RoundTrip возвращает записанный ответ или ошибку, если в кассете его нет
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if _, err := readBody(&req.Body); err != nil {
+		return nil, err
+	}
+	uri := req.URL.RequestURI()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Request.Method != req.Method || interaction.Request.URL != uri {
+			continue
+		}
+		r.used[i] = true
+		headers := interaction.Response.Headers.Clone()
+		if headers == nil {
+			headers = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+			StatusCode:    interaction.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        headers,
+			Body:          io.NopCloser(bytes.NewBufferString(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("в кассете нет ответа на %s %s", req.Method, uri)
+}
+
+// Unused возвращает число обменов кассеты, которые не были воспроизведены
+func (r *Replayer) Unused() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, used := range r.used {
+		if !used {
+			count++
+		}
+	}
+	return count
+}
+
+// readBody читает тело и заменяет его копией, чтобы его можно было прочитать повторно
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать тело: %w", err)
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
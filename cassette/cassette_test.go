@@ -0,0 +1,109 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// get выполняет запрос через transport и возвращает статус и тело ответа
+func get(t *testing.T, transport http.RoundTripper, method, url, body string) (int, string) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret-token")
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if !assert.NoError(t, err) {
+		return 0, ""
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return resp.StatusCode, string(data)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/authorize":
+			io.WriteString(w, `{"refresh_token":"secret-token"}`)
+		case "/api/clusters/c1":
+			polls++
+			if polls == 1 {
+				io.WriteString(w, `{"id":"c1","status":"CREATING"}`)
+				return
+			}
+			io.WriteString(w, `{"id":"c1","status":"OK"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	recorder, err := NewRecorder(path, nil)
+	assert.NoError(t, err)
+	get(t, recorder, "POST", server.URL+"/api/authorize", `{"login":"user","password":"hunter2"}`)
+	get(t, recorder, "GET", server.URL+"/api/clusters/c1", "")
+	get(t, recorder, "GET", server.URL+"/api/clusters/c1", "")
+	status, _ := get(t, recorder, "DELETE", server.URL+"/api/dumps/d1?force=true", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.NoError(t, recorder.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	for _, secret := range []string{"hunter2", "secret-token"} {
+		assert.NotContains(t, string(data), secret)
+	}
+	c, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, recorder.Seed(), c.Seed)
+	interactions := c.Interactions
+	if !assert.Len(t, interactions, 4) {
+		return
+	}
+	assert.Equal(t, `{"login":"user","password":"REDACTED"}`, interactions[0].Request.Body)
+	assert.Equal(t, Redacted, interactions[0].Request.Headers.Get("Authorization"))
+	assert.Equal(t, "/api/dumps/d1?force=true", interactions[3].Request.URL)
+
+	// Воспроизведение не обращается к API: сервер остановлен
+	server.Close()
+	replayer := NewReplayer(interactions)
+	status, body := get(t, replayer, "POST", "http://replay.invalid/api/authorize", `{"login":"other","password":"x"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"refresh_token":"REDACTED"}`, body)
+	_, body = get(t, replayer, "GET", "http://replay.invalid/api/clusters/c1", "")
+	assert.Contains(t, body, "CREATING")
+	_, body = get(t, replayer, "GET", "http://replay.invalid/api/clusters/c1", "")
+	assert.Contains(t, body, `"OK"`)
+	assert.Equal(t, 1, replayer.Unused())
+
+	_, err = replayer.RoundTrip(httptestRequest(t, "GET", "http://replay.invalid/api/clusters/c1"))
+	if assert.Error(t, err, "обмены кассеты исчерпаны") {
+		assert.Contains(t, err.Error(), "GET /api/clusters/c1")
+	}
+}
+
+func TestRedactBody(t *testing.T) {
+	cases := map[string]string{
+		`{"users":[{"name":"u","password":"p"}],"id":12345678901234567890}`: `{"id":12345678901234567890,"users":[{"name":"u","password":"REDACTED"}]}`,
+		`{"name":"no secrets"}`: `{"name":"no secrets"}`,
+		`{"password":""}`:       `{"password":""}`,
+		`not json`:              `not json`,
+	}
+	for body, want := range cases {
+		assert.Equal(t, want, redactBody([]byte(body)), body)
+	}
+}
+
+func httptestRequest(t *testing.T, method, url string) *http.Request {
+	req, err := http.NewRequest(method, url, nil)
+	assert.NoError(t, err)
+	return req
+}
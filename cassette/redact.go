@@ -0,0 +1,37 @@
+package cassette
+
+import (
+	"net/http"
+
+	"dbaas_testing_task/redact"
+)
+
+// Redacted заменяет секретные значения в кассете
+const Redacted = redact.Mask
+
+// secretHeaders - заголовки, значения которых не записываются
+var secretHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+func redactHeaders(headers http.Header) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	redacted := headers.Clone()
+	for name, values := range redacted {
+		for i, value := range values {
+			values[i] = redact.String(value)
+		}
+		redacted[name] = values
+	}
+	for _, name := range secretHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, Redacted)
+		}
+	}
+	return redacted
+}
+
+// redactBody заменяет значения секретных полей в JSON-теле и известные секреты в любом теле
+func redactBody(body []byte) string {
+	return redact.JSON(body)
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"dbaas_testing_task/metrics"
+	"dbaas_testing_task/runstate"
+)
+
+// Режимы создания кластера (CreateClusterRequest.CreationMode)
+const (
+	CreationModeEmpty  = "empty"
+	CreationModeBackup = "backup"
+	CreationModeClone  = "clone"
+	CreationModeDump   = "dump"
+	CreationModePITR   = "pitr"
+)
+
+// DefaultClusterRequest возвращает запрос на создание кластера с параметрами, используемыми в TestEndToEnd
+func DefaultClusterRequest(t *testing.T, name string) CreateClusterRequest {
+	return CreateClusterRequest{
+		TypeID: GetTypeID(t),
+		Options: Options{
+			MaximumLagOnFailover: 1048576,
+		},
+		DiskSize:      3221225472,
+		Mode:          "create",
+		ReplicasCount: 1,
+		CreationMode:  CreationModeEmpty,
+		Name:          name,
+		FlavorID:      GetFlavorID(t),
+		TypeName:      "Postgres Pro Enterprise",
+		Az:            "GZ1",
+		HAManager:     "patroni",
+		HA:            false,
+	}
+}
+
+// ProvisionCluster создаёт кластер по запросу, дожидается статуса OK и возвращает его ID.
+// Кластер удаляется автоматически по завершении теста
+func ProvisionCluster(t *testing.T, request CreateClusterRequest) string {
+	started := time.Now()
+	resp, _ := makeRequest(t, "POST", apiURL("/api/clusters"), request, authHeaders())
+	if resp.StatusCode != http.StatusCreated {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 201, получен: %d", resp.StatusCode)
+	}
+
+	var response CreateClusterResponse
+	parseResponseBody(t, resp, &response)
+	if len(response.Instances) == 0 {
+		Fatalf(t, "В ответе на создание кластера нет ни одного экземпляра")
+	}
+	id := response.Instances[0].ClusterID
+	Logf(t, "Cluster created with ID: %s", id)
+	JournalResource(t, runstate.KindCluster, id, "", request.Name)
+	t.Cleanup(func() { DeleteCluster(t, id) })
+
+	defer RecordOperation(t, metrics.OpClusterCreate, started)
+	WaitForStatus(t, "Cluster", apiURL("/api/clusters/%s", id))
+	return id
+}
+
+// TryCreateCluster отправляет запрос на создание кластера с произвольным телом и возвращает статус ответа
+// и тело ошибки, не прерывая тест. Используется в негативных тестах; если кластер всё же создан,
+// он удаляется по завершении теста
+func TryCreateCluster(t *testing.T, body interface{}) (int, APIError) {
+	resp, _ := makeRequest(t, "POST", apiURL("/api/clusters"), body, authHeaders())
+	if resp.StatusCode == http.StatusCreated {
+		var response CreateClusterResponse
+		parseResponseBody(t, resp, &response)
+		for _, instance := range response.Instances {
+			id := instance.ClusterID
+			JournalResource(t, runstate.KindCluster, id, "", "")
+			t.Cleanup(func() { DeleteCluster(t, id) })
+		}
+		return resp.StatusCode, APIError{}
+	}
+
+	var apiError APIError
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&apiError); err != nil {
+		Logf(t, "Error response is not a JSON object: %v", err)
+	}
+	return resp.StatusCode, apiError
+}
+
+// DeleteCluster удаляет кластер. Уже удалённый кластер не считается ошибкой
+func DeleteCluster(t *testing.T, id string) {
+	resp, _ := makeRequest(t, "DELETE", apiURL("/api/clusters/%s", id), nil, authHeaders())
+	resp.Body.Close()
+	// Кластер мог быть уже удалён самим тестом
+	if resp.StatusCode == http.StatusNotFound {
+		ForgetResource(t, runstate.KindCluster, id)
+		return
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		Errorf(t, "Ожидался статус 204 при удалении кластера %s, получен: %d", id, resp.StatusCode)
+		return
+	}
+	ForgetResource(t, runstate.KindCluster, id)
+	Logf(t, "Deleted cluster with ID: %s", id)
+}
+
+// GetCluster возвращает кластер по ID
+func GetCluster(t *testing.T, id string) Cluster {
+	resp, _ := makeRequest(t, "GET", apiURL("/api/clusters/%s", id), nil, authHeaders())
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 200 при получении кластера %s, получен: %d", id, resp.StatusCode)
+	}
+	var cluster Cluster
+	parseResponseBody(t, resp, &cluster)
+	return cluster
+}
+
+// UpdateCluster отправляет запрос на изменение кластера. Дождаться применения изменений можно через WaitForCluster
+func UpdateCluster(t *testing.T, id string, request UpdateClusterRequest) {
+	resp, _ := makeRequest(t, "PATCH", apiURL("/api/clusters/%s", id), request, authHeaders())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		Fatalf(t, "Ожидался статус 200 или 202 при изменении кластера %s, получен: %d", id, resp.StatusCode)
+	}
+}
+
+// WaitForCluster опрашивает кластер, пока он не окажется в статусе OK и не будет выполнено условие ready.
+// Интервал опроса тот же, что у WaitForStatus, а число попыток задаётся явно,
+// так как изменение ресурсов кластера занимает больше времени, чем создание базы данных
+func WaitForCluster(t *testing.T, id string, attempts int, ready func(Cluster) bool) Cluster {
+	var cluster Cluster
+	for i := 0; i < attempts; i++ {
+		cluster = GetCluster(t, id)
+		if cluster.Status == "OK" && ready(cluster) {
+			return cluster
+		}
+		Logf(t, "Cluster status is %s, waiting for changes to apply at %s", cluster.Status, time.Now().Format("2006-01-02 15:04:05.000"))
+		time.Sleep(statusPollInterval)
+	}
+	Fatalf(t, "Изменения кластера %s не применились, последний статус: %s", id, cluster.Status)
+	return cluster
+}
+
+// ResizeCluster меняет flavor кластера и дожидается применения изменений
+func ResizeCluster(t *testing.T, id, flavorId string) Cluster {
+	started := time.Now()
+	UpdateCluster(t, id, UpdateClusterRequest{FlavorID: flavorId})
+	Logf(t, "Cluster %s resize to flavor %s requested", id, flavorId)
+	defer RecordOperation(t, metrics.OpClusterResize, started)
+	cluster := WaitForCluster(t, id, 4*statusPollAttempts, func(c Cluster) bool { return c.FlavorID == flavorId })
+	return cluster
+}
+
+// ExpandClusterDisk увеличивает размер диска кластера до diskSize байт и дожидается применения изменений
+func ExpandClusterDisk(t *testing.T, id string, diskSize int64) Cluster {
+	started := time.Now()
+	UpdateCluster(t, id, UpdateClusterRequest{DiskSize: diskSize})
+	Logf(t, "Cluster %s disk resize to %d bytes requested", id, diskSize)
+	defer RecordOperation(t, metrics.OpClusterDiskExpand, started)
+	cluster := WaitForCluster(t, id, 4*statusPollAttempts, func(c Cluster) bool { return c.DiskSize == diskSize })
+	return cluster
+}
+
+// ScaleClusterReplicas меняет число реплик кластера и дожидается применения изменений
+func ScaleClusterReplicas(t *testing.T, id string, replicas int) Cluster {
+	started := time.Now()
+	UpdateCluster(t, id, UpdateClusterRequest{ReplicasCount: &replicas})
+	Logf(t, "Cluster %s scale to %d replicas requested", id, replicas)
+	defer RecordOperation(t, metrics.OpClusterReplicasScale, started)
+	cluster := WaitForCluster(t, id, 4*statusPollAttempts, func(c Cluster) bool { return c.ReplicasCount == replicas })
+	return cluster
+}
+
+// UpdateClusterOptions меняет опции кластера и дожидается их применения
+func UpdateClusterOptions(t *testing.T, id string, options Options) Cluster {
+	started := time.Now()
+	UpdateCluster(t, id, UpdateClusterRequest{Options: &options})
+	Logf(t, "Cluster %s options update requested: %+v", id, options)
+	defer RecordOperation(t, metrics.OpClusterOptionsUpdate, started)
+	cluster := WaitForCluster(t, id, statusPollAttempts, func(c Cluster) bool { return c.Options == options })
+	return cluster
+}
+
+// ListClusterParameters возвращает параметры Postgres кластера
+func ListClusterParameters(t *testing.T, id string) []ClusterParameter {
+	resp, _ := makeRequest(t, "GET", apiURL("/api/clusters/%s/parameters", id), nil, authHeaders())
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 200 при получении параметров кластера %s, получен: %d", id, resp.StatusCode)
+	}
+	var parameters []ClusterParameter
+	parseResponseBody(t, resp, &parameters)
+	return parameters
+}
+
+// FindClusterParameter ищет параметр Postgres кластера по имени. Второе значение равно false, если параметр не найден
+func FindClusterParameter(t *testing.T, id, name string) (ClusterParameter, bool) {
+	for _, parameter := range ListClusterParameters(t, id) {
+		if parameter.Name == name {
+			return parameter, true
+		}
+	}
+	return ClusterParameter{}, false
+}
+
+// UpdateClusterParameters меняет параметры Postgres кластера и возвращает их состояние после изменения.
+// Применение параметров на сервере нужно дожидаться отдельно, например через WaitForSetting
+func UpdateClusterParameters(t *testing.T, id string, parameters map[string]string) []ClusterParameter {
+	request := UpdateClusterParametersRequest{Parameters: parameters}
+	resp, _ := makeRequest(t, "PATCH", apiURL("/api/clusters/%s/parameters", id), request, authHeaders())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		Fatalf(t, "Ожидался статус 200 или 202 при изменении параметров кластера %s, получен: %d", id, resp.StatusCode)
+	}
+	Logf(t, "Cluster %s parameters update requested: %v", id, parameters)
+	return ListClusterParameters(t, id)
+}
+
+// RestartCluster перезапускает кластер и дожидается статуса OK
+func RestartCluster(t *testing.T, id string) Cluster {
+	started := time.Now()
+	resp, _ := makeRequest(t, "POST", apiURL("/api/clusters/%s/restart", id), nil, authHeaders())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		Fatalf(t, "Ожидался статус 200 или 202 при перезапуске кластера %s, получен: %d", id, resp.StatusCode)
+	}
+	Logf(t, "Cluster %s restart requested", id)
+	defer RecordOperation(t, metrics.OpClusterRestart, started)
+	cluster := WaitForCluster(t, id, 4*statusPollAttempts, func(c Cluster) bool { return !c.PendingRestart })
+	return cluster
+}
+
+// ListFlavors возвращает каталог flavor
+func ListFlavors(t *testing.T) []Flavor {
+	resp, _ := makeRequest(t, "GET", apiURL("/api/flavors"), nil, authHeaders())
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 200 при получении списка flavor, получен: %d", resp.StatusCode)
+	}
+	var flavors []Flavor
+	parseResponseBody(t, resp, &flavors)
+	return flavors
+}
+
+// FindFlavor ищет flavor по имени. Второе значение равно false, если flavor не найден
+func FindFlavor(t *testing.T, name string) (Flavor, bool) {
+	for _, flavor := range ListFlavors(t) {
+		if flavor.Name == name {
+			return flavor, true
+		}
+	}
+	return Flavor{}, false
+}
@@ -0,0 +1,35 @@
+// Команда apigen генерирует модели и клиент пакета dbaas по спецификации OpenAPI.
+//
+// Запускается через go generate из каталога dbaas:
+//
+//	go generate ./dbaas
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"dbaas_testing_task/internal/apigen"
+)
+
+func main() {
+	specPath := flag.String("spec", "api/openapi.yaml", "путь к спецификации OpenAPI")
+	outDir := flag.String("out", "dbaas", "каталог пакета, в который записываются сгенерированные файлы")
+	flag.Parse()
+
+	data, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatalf("Не удалось прочитать спецификацию: %v", err)
+	}
+	files, err := apigen.Generate(data)
+	if err != nil {
+		log.Fatalf("Не удалось сгенерировать код: %v", err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(*outDir, name), content, 0o644); err != nil {
+			log.Fatalf("Не удалось записать %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"dbaas_testing_task/manifest"
+)
+
+func runApply(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("apply")
+	file := fs.String("f", "", "файл манифеста")
+	dryRun := fs.Bool("dry-run", false, "только показать план")
+	prune := fs.Bool("prune", false, "удалить базы данных и пользователей кластеров манифеста, которых в нём нет")
+	if err := parse(fs, args, "f"); err != nil {
+		return err
+	}
+	m, err := manifest.Load(*file)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+	engine := a.newEngine()
+	engine.Prune = *prune
+	if *dryRun {
+		plan, err := engine.Plan(ctx, m)
+		if err != nil {
+			return err
+		}
+		return a.print(plan, actionsTable(plan))
+	}
+	applied, err := engine.Apply(ctx, m)
+	if printErr := a.print(applied, actionsTable(applied)); printErr != nil && err == nil {
+		err = printErr
+	}
+	return err
+}
+
+func runDestroy(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("destroy")
+	file := fs.String("f", "", "файл манифеста")
+	dryRun := fs.Bool("dry-run", false, "только показать план")
+	if err := parse(fs, args, "f"); err != nil {
+		return err
+	}
+	m, err := manifest.Load(*file)
+	if err != nil {
+		return usageError{err.Error()}
+	}
+	engine := a.newEngine()
+	if *dryRun {
+		plan, err := engine.PlanDestroy(ctx, m)
+		if err != nil {
+			return err
+		}
+		return a.print(plan, actionsTable(plan))
+	}
+	destroyed, err := engine.Destroy(ctx, m)
+	if printErr := a.print(destroyed, actionsTable(destroyed)); printErr != nil && err == nil {
+		err = printErr
+	}
+	return err
+}
+
+// newEngine создаёт движок манифестов, сообщающий о ходе выполнения в stderr
+func (a *app) newEngine() *manifest.Engine {
+	return &manifest.Engine{
+		Client:   a.client,
+		Interval: a.interval,
+		Log: func(action manifest.Action) {
+			fmt.Fprintf(a.stderr, "Выполнено: %s\n", action)
+		},
+	}
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"dbaas_testing_task/credentials"
+	"dbaas_testing_task/dbaas"
+	"dbaas_testing_task/redact"
+)
+
+// commands содержит подкоманды по имени вида "cluster create"
+var commands = map[string]command{
+	"login":          {usage: "получить токен по API_LOGIN и API_PASSWORD и сохранить его", run: runLogin},
+	"flavors list":   {usage: "каталог flavor", auth: true, run: runFlavorsList},
+	"types list":     {usage: "каталог типов кластеров", auth: true, run: runTypesList},
+	"cluster create": {usage: "создать кластер", auth: true, run: runClusterCreate},
+	"cluster get":    {usage: "показать кластер", auth: true, run: runClusterGet},
+	"cluster list":   {usage: "список кластеров", auth: true, run: runClusterList},
+	"cluster delete": {usage: "удалить кластер", auth: true, run: runClusterDelete},
+	"cluster wait":   {usage: "дождаться статуса кластера", auth: true, run: runClusterWait},
+	"db create":      {usage: "создать базу данных", auth: true, run: runDBCreate},
+	"db list":        {usage: "список баз данных кластера", auth: true, run: runDBList},
+	"user create":    {usage: "создать пользователя кластера", auth: true, run: runUserCreate},
+	"dump create":    {usage: "создать дамп базы данных", auth: true, run: runDumpCreate},
+	"dump list":      {usage: "список дампов базы данных", auth: true, run: runDumpList},
+	"dump delete":    {usage: "удалить дамп", auth: true, run: runDumpDelete},
+	"restore":        {usage: "восстановить базу данных из дампа", auth: true, run: runRestore},
+	"apply":          {usage: "привести окружение к манифесту", auth: true, run: runApply},
+	"destroy":        {usage: "удалить кластеры манифеста", auth: true, run: runDestroy},
+	"state list":     {usage: "ресурсы из журнала запуска тестов", run: runStateList},
+	"state destroy":  {usage: "удалить ресурсы из журнала запуска тестов", auth: true, run: runStateDestroy},
+}
+
+func runLogin(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("login")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+	login, password := os.Getenv("API_LOGIN"), os.Getenv("API_PASSWORD")
+	if login == "" || password == "" {
+		return usagef("не заданы API_LOGIN и/или API_PASSWORD")
+	}
+	redact.Secret(password)
+	if err := a.client.Login(ctx, login, password); err != nil {
+		return err
+	}
+	path, err := saveToken(a.client.Token)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stderr, "Токен сохранён в %s\n", path)
+	return nil
+}
+
+func runFlavorsList(ctx context.Context, a *app, args []string) error {
+	if err := parse(a.newFlagSet("flavors list"), args); err != nil {
+		return err
+	}
+	flavors, err := a.client.ListFlavors(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(flavors, flavorsTable(flavors))
+}
+
+func runTypesList(ctx context.Context, a *app, args []string) error {
+	if err := parse(a.newFlagSet("types list"), args); err != nil {
+		return err
+	}
+	types, err := a.client.ListTypes(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(types, typesTable(types))
+}
+
+func runClusterCreate(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("cluster create")
+	name := fs.String("name", "", "имя кластера")
+	flavor := fs.String("flavor", dbaas.DefaultFlavor, "имя или ID flavor")
+	typ := fs.String("type", dbaas.DefaultTypeVersion, "версия или ID типа кластера")
+	disk := fs.Int64("disk", dbaas.DefaultDiskSize, "размер диска в байтах")
+	replicas := fs.Int("replicas", 1, "число реплик")
+	az := fs.String("az", dbaas.DefaultAz, "зона доступности")
+	creationMode := fs.String("creation-mode", "empty", "режим создания: empty, backup, clone, dump или pitr")
+	sourceCluster := fs.String("source-cluster", "", "ID исходного кластера для backup, clone, dump и pitr")
+	backupID := fs.String("backup", "", "ID резервной копии для режима backup")
+	dumpID := fs.String("dump", "", "ID дампа для режима dump")
+	targetTime := fs.String("target-time", "", "момент восстановления в формате RFC 3339 для режима pitr")
+	targetLSN := fs.String("target-lsn", "", "позиция WAL для режима pitr")
+	wait := fs.Bool("wait", false, "дождаться статуса OK")
+	if err := parse(fs, args, "name"); err != nil {
+		return err
+	}
+
+	foundFlavor, err := a.client.FindFlavor(ctx, *flavor)
+	if err != nil {
+		return err
+	}
+	foundType, err := a.client.FindType(ctx, *typ)
+	if err != nil {
+		return err
+	}
+	request := dbaas.NewClusterRequest(*name, foundFlavor.Id, foundType.Id)
+	request.DiskSize = *disk
+	request.ReplicasCount = *replicas
+	request.CreationMode = *creationMode
+	request.Az = *az
+	if *creationMode != "empty" {
+		recovery := &dbaas.RecoveryTarget{SourceClusterID: *sourceCluster, BackupID: *backupID, DumpID: *dumpID, TargetLSN: *targetLSN}
+		if *targetTime != "" {
+			at, err := time.Parse(time.RFC3339, *targetTime)
+			if err != nil {
+				return usagef("неверный формат -target-time: %v", err)
+			}
+			recovery.TargetTime = &at
+		}
+		request.Recovery = recovery
+	}
+
+	response, err := a.client.CreateCluster(ctx, request)
+	if err != nil {
+		return err
+	}
+	if len(response.Instances) == 0 {
+		return fmt.Errorf("в ответе на создание кластера нет ни одного экземпляра")
+	}
+	id := response.Instances[0].ClusterID
+	var cluster dbaas.Cluster
+	if *wait {
+		cluster, err = a.client.WaitCluster(ctx, id, dbaas.StatusOK, a.interval)
+	} else {
+		cluster, err = a.client.GetCluster(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+	return a.print(cluster, clustersTable(cluster))
+}
+
+func runClusterGet(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("cluster get")
+	id := fs.String("id", "", "ID кластера")
+	if err := parse(fs, args, "id"); err != nil {
+		return err
+	}
+	cluster, err := a.client.GetCluster(ctx, *id)
+	if err != nil {
+		return err
+	}
+	return a.print(cluster, clustersTable(cluster))
+}
+
+func runClusterList(ctx context.Context, a *app, args []string) error {
+	if err := parse(a.newFlagSet("cluster list"), args); err != nil {
+		return err
+	}
+	clusters, err := a.client.ListClusters(ctx)
+	if err != nil {
+		return err
+	}
+	return a.print(clusters, clustersTable(clusters...))
+}
+
+func runClusterDelete(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("cluster delete")
+	id := fs.String("id", "", "ID кластера")
+	wait := fs.Bool("wait", false, "дождаться, пока кластер исчезнет из API")
+	if err := parse(fs, args, "id"); err != nil {
+		return err
+	}
+	if err := a.client.DeleteCluster(ctx, *id); err != nil {
+		return err
+	}
+	if *wait {
+		err := dbaas.WaitDeleted(ctx, a.interval, func(ctx context.Context) error {
+			_, err := a.client.GetCluster(ctx, *id)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(a.stderr, "Кластер %s удалён\n", *id)
+	return nil
+}
+
+func runClusterWait(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("cluster wait")
+	id := fs.String("id", "", "ID кластера")
+	status := fs.String("status", dbaas.StatusOK, "ожидаемый статус")
+	if err := parse(fs, args, "id", "status"); err != nil {
+		return err
+	}
+	cluster, err := a.client.WaitCluster(ctx, *id, *status, a.interval)
+	if err != nil {
+		return err
+	}
+	return a.print(cluster, clustersTable(cluster))
+}
+
+func runDBCreate(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("db create")
+	clusterID := fs.String("cluster", "", "ID кластера")
+	name := fs.String("name", "", "имя базы данных")
+	tableSpace := fs.String("tablespace", "", "имя или ID tablespace, по умолчанию tablespace с флагом default")
+	owner := fs.String("owner", "", "владелец базы данных")
+	encoding := fs.String("encoding", "", "кодировка")
+	lcCollate := fs.String("lc-collate", "", "LC_COLLATE")
+	lcCtype := fs.String("lc-ctype", "", "LC_CTYPE")
+	wait := fs.Bool("wait", false, "дождаться статуса OK")
+	if err := parse(fs, args, "cluster", "name"); err != nil {
+		return err
+	}
+
+	found, err := a.client.FindTableSpace(ctx, *clusterID, *tableSpace)
+	if err != nil {
+		return err
+	}
+	response, err := a.client.CreateDatabase(ctx, *clusterID, dbaas.CreateDBRequest{
+		Name:         *name,
+		TableSpaceID: found.Id,
+		Owner:        *owner,
+		Encoding:     *encoding,
+		LcCollate:    *lcCollate,
+		LcCtype:      *lcCtype,
+	})
+	if err != nil {
+		return err
+	}
+	var database dbaas.Database
+	if *wait {
+		database, err = a.client.WaitDatabase(ctx, *clusterID, response.Id, a.interval)
+	} else {
+		database, err = a.client.GetDatabase(ctx, *clusterID, response.Id)
+	}
+	if err != nil {
+		return err
+	}
+	return a.print(database, databasesTable(database))
+}
+
+func runDBList(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("db list")
+	clusterID := fs.String("cluster", "", "ID кластера")
+	if err := parse(fs, args, "cluster"); err != nil {
+		return err
+	}
+	databases, err := a.client.ListDatabases(ctx, *clusterID)
+	if err != nil {
+		return err
+	}
+	return a.print(databases, databasesTable(databases...))
+}
+
+func runUserCreate(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("user create")
+	clusterID := fs.String("cluster", "", "ID кластера")
+	name := fs.String("name", "", "имя пользователя")
+	passwordStdin := fs.Bool("password-stdin", false, "прочитать пароль пользователя из первой строки stdin")
+	databases := fs.String("databases", "", "базы данных через запятую")
+	roles := fs.String("roles", "", "роли через запятую, например pg_read_all_data,pg_write_all_data")
+	wait := fs.Bool("wait", false, "дождаться статуса OK")
+	if err := parse(fs, args, "cluster", "name"); err != nil {
+		return err
+	}
+	password, generated, err := a.userPassword(*passwordStdin)
+	if err != nil {
+		return err
+	}
+	redact.Secret(password)
+	user, err := a.client.CreateClusterUser(ctx, *clusterID, dbaas.CreateClusterUserRequest{
+		Databases: splitList(*databases),
+		Roles:     splitList(*roles),
+		Name:      *name,
+		Password:  password,
+	})
+	if err != nil {
+		return err
+	}
+	if generated {
+		// Сгенерированный пароль больше нигде не сохраняется
+		fmt.Fprintf(a.stderr, "Пароль пользователя %s: %s\n", *name, password)
+	}
+	if *wait {
+		if user, err = a.client.WaitClusterUser(ctx, *clusterID, user.Id, a.interval); err != nil {
+			return err
+		}
+	}
+	return a.print(user, usersTable(user))
+}
+
+// userPassword возвращает пароль нового пользователя: из stdin, из DBAASCTL_USER_PASSWORD или
+// сгенерированный. Пароль не принимается флагом, чтобы он не попал в список процессов и историю команд
+func (a *app) userPassword(fromStdin bool) (password string, generated bool, err error) {
+	if fromStdin {
+		line, err := bufio.NewReader(a.stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", false, fmt.Errorf("не удалось прочитать пароль из stdin: %w", err)
+		}
+		if password = strings.TrimRight(line, "\r\n"); password == "" {
+			return "", false, usagef("-password-stdin: пароль в stdin пуст")
+		}
+		return password, false, nil
+	}
+	if password = os.Getenv("DBAASCTL_USER_PASSWORD"); password != "" {
+		return password, false, nil
+	}
+	return credentials.Generate(credentials.DefaultPolicy), true, nil
+}
+
+func runDumpCreate(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("dump create")
+	clusterID := fs.String("cluster", "", "ID кластера")
+	databaseID := fs.String("db", "", "ID базы данных")
+	name := fs.String("name", "", "имя дампа")
+	wait := fs.Bool("wait", false, "дождаться статуса OK")
+	if err := parse(fs, args, "cluster", "db", "name"); err != nil {
+		return err
+	}
+	dump, err := a.client.CreateDump(ctx, *clusterID, *databaseID, dbaas.CreateDumpRequest{Name: *name})
+	if err != nil {
+		return err
+	}
+	if *wait {
+		if dump, err = a.client.WaitDump(ctx, dump.Id, a.interval); err != nil {
+			return err
+		}
+	}
+	return a.print(dump, dumpsTable(dump))
+}
+
+func runDumpList(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("dump list")
+	clusterID := fs.String("cluster", "", "ID кластера")
+	databaseID := fs.String("db", "", "ID базы данных")
+	if err := parse(fs, args, "cluster", "db"); err != nil {
+		return err
+	}
+	dumps, err := a.client.ListDumps(ctx, *clusterID, *databaseID)
+	if err != nil {
+		return err
+	}
+	return a.print(dumps, dumpsTable(dumps...))
+}
+
+func runDumpDelete(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("dump delete")
+	id := fs.String("id", "", "ID дампа")
+	if err := parse(fs, args, "id"); err != nil {
+		return err
+	}
+	if err := a.client.DeleteDump(ctx, *id); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stderr, "Дамп %s удалён\n", *id)
+	return nil
+}
+
+func runRestore(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("restore")
+	clusterID := fs.String("cluster", "", "ID кластера")
+	databaseID := fs.String("db", "", "ID базы данных")
+	dumpID := fs.String("dump", "", "ID дампа")
+	restoreUsers := fs.Bool("restore-users", false, "восстановить пользователей из дампа")
+	wait := fs.Bool("wait", false, "дождаться статуса OK базы данных")
+	if err := parse(fs, args, "cluster", "db", "dump"); err != nil {
+		return err
+	}
+	dump, err := a.client.RestoreDump(ctx, *clusterID, *databaseID, dbaas.RestoreDumpRequest{DumpID: *dumpID, Mode: "full", RestoreUsers: *restoreUsers})
+	if err != nil {
+		return err
+	}
+	if !*wait {
+		return a.print(dump, dumpsTable(dump))
+	}
+	database, err := a.client.WaitDatabase(ctx, *clusterID, *databaseID, a.interval)
+	if err != nil {
+		return err
+	}
+	return a.print(database, databasesTable(database))
+}
@@ -0,0 +1,224 @@
+// Команда dbaasctl выполняет операции API DBaaS из командной строки.
+//
+//	dbaasctl [-url URL] [-o table|json] [-timeout 30m] <команда> [флаги]
+//
+// Адрес API берётся из -url или API_BASE_URL. Токен, полученный командой login, сохраняется
+// в файле DBAASCTL_TOKEN_FILE (по умолчанию в каталоге настроек пользователя) и используется
+// остальными командами; его можно передать и через DBAASCTL_TOKEN. Если токена нет, но заданы
+// API_LOGIN и API_PASSWORD, dbaasctl авторизуется перед выполнением команды.
+//
+// Коды завершения:
+//
+//	0 - успех
+//	1 - ошибка, не связанная с ответом API (сеть, файлы)
+//	2 - неверные аргументы
+//	3 - API отказал в доступе (401, 403)
+//	4 - ресурс не найден (404)
+//	5 - запрос отклонён API (остальные 4xx)
+//	6 - ошибка на стороне API (5xx)
+//	7 - истекло время ожидания
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"dbaas_testing_task/dbaas"
+	"dbaas_testing_task/redact"
+)
+
+// Коды завершения
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitUnauthorized
+	exitNotFound
+	exitRejected
+	exitServerError
+	exitTimeout
+)
+
+// usageError сообщает о неверных аргументах команды
+type usageError struct {
+	message string
+}
+
+func (e usageError) Error() string { return e.message }
+
+func usagef(format string, args ...interface{}) error {
+	return usageError{fmt.Sprintf(format, args...)}
+}
+
+// app содержит общее состояние команд
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	client *dbaas.Client
+	output string
+	// interval задаёт интервал опроса статуса при ожидании
+	interval time.Duration
+}
+
+// command описывает подкоманду
+type command struct {
+	usage string
+	// auth равно true, если команде нужен токен
+	auth bool
+	run  func(ctx context.Context, a *app, args []string) error
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run выполняет команду с аргументами args и возвращает код завершения
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	global := flag.NewFlagSet("dbaasctl", flag.ContinueOnError)
+	global.SetOutput(stderr)
+	baseURL := global.String("url", os.Getenv("API_BASE_URL"), "адрес API")
+	output := global.String("o", "table", "формат вывода: table или json")
+	timeout := global.Duration("timeout", 30*time.Minute, "ограничение времени выполнения команды")
+	interval := global.Duration("interval", dbaas.DefaultPollInterval, "интервал опроса статуса при ожидании")
+	global.Usage = func() { printUsage(stderr, global) }
+	if err := global.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(stderr, "Неизвестный формат вывода %q\n", *output)
+		return exitUsage
+	}
+
+	name, rest := commandName(global.Args())
+	cmd, ok := commands[name]
+	if !ok {
+		if name != "" {
+			fmt.Fprintf(stderr, "Неизвестная команда %q\n\n", name)
+		}
+		printUsage(stderr, global)
+		return exitUsage
+	}
+	if *baseURL == "" {
+		fmt.Fprintln(stderr, "Не задан адрес API: укажите -url или API_BASE_URL")
+		return exitUsage
+	}
+
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr, client: dbaas.NewClient(*baseURL), output: *output, interval: *interval}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	err := func() error {
+		if cmd.auth {
+			if err := a.authenticate(ctx); err != nil {
+				return err
+			}
+		}
+		return cmd.run(ctx, a, rest)
+	}()
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	// Ошибки API и сети могут содержать пароль или токен
+	fmt.Fprintf(stderr, "Ошибка: %v\n", redact.Error(err))
+	return exitCode(err)
+}
+
+// commandName выделяет имя подкоманды из одного или двух слов
+func commandName(args []string) (string, []string) {
+	if len(args) == 0 {
+		return "", nil
+	}
+	if _, ok := commands[args[0]]; ok {
+		return args[0], args[1:]
+	}
+	if len(args) > 1 {
+		return args[0] + " " + args[1], args[2:]
+	}
+	return args[0], nil
+}
+
+// exitCode возвращает код завершения для ошибки команды
+func exitCode(err error) int {
+	var usage usageError
+	switch status := dbaas.StatusCode(err); {
+	case errors.As(err, &usage):
+		return exitUsage
+	case status == 401 || status == 403:
+		return exitUnauthorized
+	case dbaas.IsNotFound(err):
+		return exitNotFound
+	case status >= 400 && status < 500:
+		return exitRejected
+	case status >= 500:
+		return exitServerError
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitTimeout
+	}
+	return exitError
+}
+
+func printUsage(w io.Writer, global *flag.FlagSet) {
+	fmt.Fprintln(w, "Использование: dbaasctl [флаги] <команда> [флаги команды]")
+	fmt.Fprintln(w, "\nКоманды:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-16s %s\n", name, commands[name].usage)
+	}
+	fmt.Fprintln(w, "\nФлаги:")
+	global.PrintDefaults()
+}
+
+// newFlagSet создаёт набор флагов подкоманды, ошибки разбора которого считаются ошибками аргументов
+func (a *app) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("dbaasctl "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	return fs
+}
+
+// parse разбирает флаги подкоманды и проверяет, что заданы обязательные
+func parse(fs *flag.FlagSet, args []string, required ...string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{err.Error()}
+	}
+	if fs.NArg() > 0 {
+		return usagef("лишние аргументы: %s", strings.Join(fs.Args(), " "))
+	}
+	for _, name := range required {
+		if fs.Lookup(name).Value.String() == "" {
+			return usagef("не задан флаг -%s", name)
+		}
+	}
+	return nil
+}
+
+// splitList разбивает список через запятую, пропуская пустые элементы
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"dbaas_testing_task/credentials"
+	"dbaas_testing_task/dbaas"
+	"dbaas_testing_task/dbaas/dbaastest"
+	"dbaas_testing_task/runstate"
+)
+
+// fakeAPI имитирует API с одним кластером c1, созданным со статусом CREATING
+func fakeAPI(t *testing.T) *httptest.Server {
+	clusterStatus := "CREATING"
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/authorize" && r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(dbaas.APIError{Message: "unauthorized"})
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/authorize":
+			_ = json.NewEncoder(w).Encode(dbaas.AuthResponse{RefreshToken: "token"})
+		case "GET /api/flavors":
+			_ = json.NewEncoder(w).Encode([]dbaas.Flavor{{Id: "f1", Name: "STD3-1-1", Vcpus: 1, Ram: 1024}})
+		case "GET /api/types":
+			_ = json.NewEncoder(w).Encode([]dbaas.ClusterType{{Id: "t1", Version: "17.2.2"}})
+		case "POST /api/clusters":
+			var request dbaas.CreateClusterRequest
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			if request.Name == "" || request.Name == "taken" {
+				w.WriteHeader(http.StatusConflict)
+				_ = json.NewEncoder(w).Encode(dbaas.APIError{Message: "name is taken", Field: "name"})
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(dbaas.CreateClusterResponse{Instances: []dbaas.Instance{{ClusterID: "c1"}}})
+		case "GET /api/clusters/c1":
+			cluster := dbaas.Cluster{Id: "c1", Name: "demo", Status: clusterStatus}
+			clusterStatus = "OK"
+			_ = json.NewEncoder(w).Encode(cluster)
+		case "GET /api/clusters":
+			_ = json.NewEncoder(w).Encode([]dbaas.Cluster{{Id: "c1", Name: "demo", Status: "OK"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(dbaas.APIError{Message: "not found"})
+		}
+	}))
+}
+
+func runCLI(args ...string) (int, string, string) {
+	return runCLIInput("", args...)
+}
+
+// runCLIInput выполняет команду с input в stdin
+func runCLIInput(input string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(input), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCLI(t *testing.T) {
+	server := fakeAPI(t)
+	defer server.Close()
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	t.Setenv("DBAASCTL_TOKEN_FILE", tokenPath)
+	t.Setenv("DBAASCTL_TOKEN", "")
+	t.Setenv("API_BASE_URL", server.URL)
+	t.Setenv("API_LOGIN", "")
+	t.Setenv("API_PASSWORD", "")
+
+	// Без токена и учётных данных команда не выполняется
+	code, _, _ := runCLI("cluster", "list")
+	assert.Equal(t, exitUsage, code)
+
+	t.Setenv("API_LOGIN", "login")
+	t.Setenv("API_PASSWORD", "password")
+	code, _, stderr := runCLI("login")
+	assert.Equal(t, exitOK, code, stderr)
+	token, err := os.ReadFile(tokenPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "token", strings.TrimSpace(string(token)))
+
+	// Дальше используется сохранённый токен
+	t.Setenv("API_LOGIN", "")
+	t.Setenv("API_PASSWORD", "")
+	code, stdout, stderr := runCLI("cluster", "list")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "ID")
+	assert.Contains(t, stdout, "demo")
+
+	code, stdout, stderr = runCLI("-o", "json", "-interval", "1ms", "cluster", "create", "-name", "demo", "-wait")
+	assert.Equal(t, exitOK, code, stderr)
+	var cluster dbaas.Cluster
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &cluster))
+	assert.Equal(t, "OK", cluster.Status)
+
+	cases := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"unknown command", []string{"cluster", "explode"}, exitUsage},
+		{"missing flag", []string{"cluster", "get"}, exitUsage},
+		{"unknown output", []string{"-o", "xml", "cluster", "list"}, exitUsage},
+		{"unknown flavor", []string{"cluster", "create", "-name", "demo", "-flavor", "nope"}, exitNotFound},
+		{"not found", []string{"cluster", "get", "-id", "c2"}, exitNotFound},
+		{"unknown type", []string{"cluster", "create", "-name", "demo", "-type", "nope"}, exitNotFound},
+		{"rejected", []string{"cluster", "create", "-name", "taken"}, exitRejected},
+	}
+	for _, tc := range cases {
+		code, _, _ := runCLI(tc.args...)
+		assert.Equal(t, tc.code, code, tc.name)
+	}
+
+	t.Setenv("DBAASCTL_TOKEN", "expired")
+	code, _, stderr = runCLI("flavors", "list")
+	assert.Equal(t, exitUnauthorized, code)
+	assert.Contains(t, stderr, "401")
+}
+
+func TestCLIApply(t *testing.T) {
+	server := dbaastest.NewServer(t)
+	t.Setenv("API_BASE_URL", server.URL)
+	t.Setenv("DBAASCTL_TOKEN", dbaastest.Token)
+	path := filepath.Join(t.TempDir(), "env.yaml")
+	err := os.WriteFile(path, []byte(`
+clusters:
+  - name: demo
+    databases: [{name: app}]
+    users: [{name: app, password: secret, databases: [app], roles: [pg_read_all_data]}]
+`), 0o600)
+	assert.NoError(t, err)
+
+	code, stdout, stderr := runCLI("-interval", "1ms", "apply", "-f", path, "-dry-run")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, "create")
+	_, created := server.Cluster("demo")
+	assert.False(t, created, "-dry-run создал кластер")
+
+	code, _, stderr = runCLI("-interval", "1ms", "apply", "-f", path)
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stderr, "Выполнено: create user demo/app")
+
+	code, stdout, stderr = runCLI("-o", "json", "apply", "-f", path, "-dry-run")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.JSONEq(t, "null", stdout, "повторное применение не должно ничего менять")
+
+	code, _, stderr = runCLI("-interval", "1ms", "destroy", "-f", path)
+	assert.Equal(t, exitOK, code, stderr)
+	_, created = server.Cluster("demo")
+	assert.False(t, created, "кластер не удалён")
+
+	code, _, _ = runCLI("apply", "-f", filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Equal(t, exitUsage, code)
+}
+
+func TestCLIStateDestroy(t *testing.T) {
+	server := dbaastest.NewServer(t)
+	t.Setenv("API_BASE_URL", server.URL)
+	t.Setenv("DBAASCTL_TOKEN", dbaastest.Token)
+	created, err := server.Client().CreateCluster(context.Background(), dbaas.NewClusterRequest("crashed", "flavor-1", "type-1"))
+	assert.NoError(t, err)
+	clusterID := created.Instances[0].ClusterID
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	t.Setenv("STATE_FILE", path)
+	state, err := runstate.Open(path)
+	assert.NoError(t, err)
+	assert.NoError(t, state.Record(runstate.Resource{Kind: runstate.KindCluster, ID: clusterID, Name: "crashed", Test: "TestEndToEnd"}))
+
+	code, stdout, stderr := runCLI("state", "list")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Contains(t, stdout, clusterID)
+
+	code, _, stderr = runCLI("-interval", "1ms", "state", "destroy")
+	assert.Equal(t, exitOK, code, stderr)
+	_, exists := server.Cluster("crashed")
+	assert.False(t, exists, "кластер из журнала не удалён")
+
+	state, err = runstate.Open(path)
+	assert.NoError(t, err)
+	assert.Empty(t, state.List())
+}
+
+func TestCLIUserCreatePassword(t *testing.T) {
+	server := dbaastest.NewServer(t)
+	t.Setenv("API_BASE_URL", server.URL)
+	t.Setenv("DBAASCTL_TOKEN", dbaastest.Token)
+	t.Setenv("DBAASCTL_USER_PASSWORD", "")
+	created, err := server.Client().CreateCluster(context.Background(), dbaas.NewClusterRequest("demo", "flavor-1", "type-1"))
+	assert.NoError(t, err)
+	clusterID := created.Instances[0].ClusterID
+
+	// Флага -password нет: пароль не должен попадать в аргументы процесса
+	code, _, _ := runCLI("user", "create", "-cluster", clusterID, "-name", "flag", "-password", "secret")
+	assert.Equal(t, exitUsage, code)
+
+	code, _, stderr := runCLIInput("from-stdin\n", "user", "create", "-cluster", clusterID, "-name", "stdin", "-password-stdin")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "from-stdin", server.Password(clusterID, "stdin"))
+	assert.NotContains(t, stderr, "from-stdin")
+
+	code, _, _ = runCLIInput("", "user", "create", "-cluster", clusterID, "-name", "empty", "-password-stdin")
+	assert.Equal(t, exitUsage, code)
+
+	t.Setenv("DBAASCTL_USER_PASSWORD", "from-env")
+	code, _, stderr = runCLI("user", "create", "-cluster", clusterID, "-name", "env")
+	assert.Equal(t, exitOK, code, stderr)
+	assert.Equal(t, "from-env", server.Password(clusterID, "env"))
+
+	// Без пароля он генерируется и выводится один раз
+	t.Setenv("DBAASCTL_USER_PASSWORD", "")
+	code, stdout, stderr := runCLI("user", "create", "-cluster", clusterID, "-name", "generated")
+	assert.Equal(t, exitOK, code, stderr)
+	generated := server.Password(clusterID, "generated")
+	assert.NoError(t, credentials.DefaultPolicy.Check(generated))
+	assert.Contains(t, stderr, "Пароль пользователя generated: "+generated)
+	assert.NotContains(t, stdout, generated)
+}
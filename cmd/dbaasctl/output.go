@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"dbaas_testing_task/dbaas"
+	"dbaas_testing_task/manifest"
+	"dbaas_testing_task/runstate"
+)
+
+// table описывает табличное представление результата
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+// print выводит value в формате JSON или таблицу t
+func (a *app) print(value interface{}, t table) error {
+	if a.output == "json" {
+		encoder := json.NewEncoder(a.stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(t.headers, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func flavorsTable(flavors []dbaas.Flavor) table {
+	t := table{headers: []string{"ID", "NAME", "VCPUS", "RAM_MB"}}
+	for _, f := range flavors {
+		t.rows = append(t.rows, []string{f.Id, f.Name, strconv.Itoa(f.Vcpus), strconv.FormatInt(f.Ram, 10)})
+	}
+	return t
+}
+
+func typesTable(types []dbaas.ClusterType) table {
+	t := table{headers: []string{"ID", "NAME", "VERSION"}}
+	for _, typ := range types {
+		t.rows = append(t.rows, []string{typ.Id, typ.Name, typ.Version})
+	}
+	return t
+}
+
+func clustersTable(clusters ...dbaas.Cluster) table {
+	t := table{headers: []string{"ID", "NAME", "STATUS", "FLAVOR", "DISK", "REPLICAS"}}
+	for _, c := range clusters {
+		t.rows = append(t.rows, []string{c.Id, c.Name, c.Status, c.FlavorID, strconv.FormatInt(c.DiskSize, 10), strconv.Itoa(c.ReplicasCount)})
+	}
+	return t
+}
+
+func databasesTable(databases ...dbaas.Database) table {
+	t := table{headers: []string{"ID", "NAME", "STATUS", "OWNER", "TABLESPACE"}}
+	for _, d := range databases {
+		t.rows = append(t.rows, []string{d.Id, d.Name, d.Status, d.Owner, d.TableSpaceID})
+	}
+	return t
+}
+
+func usersTable(users ...dbaas.ClusterUser) table {
+	t := table{headers: []string{"ID", "NAME", "STATUS", "DATABASES", "ROLES"}}
+	for _, u := range users {
+		t.rows = append(t.rows, []string{u.Id, u.Name, u.Status, strings.Join(u.Databases, ","), strings.Join(u.Roles, ",")})
+	}
+	return t
+}
+
+func dumpsTable(dumps ...dbaas.Dump) table {
+	t := table{headers: []string{"ID", "NAME", "STATUS", "DATABASE"}}
+	for _, d := range dumps {
+		t.rows = append(t.rows, []string{d.Id, d.Name, d.Status, d.DatabaseID})
+	}
+	return t
+}
+
+func actionsTable(actions []manifest.Action) table {
+	t := table{headers: []string{"OP", "KIND", "CLUSTER", "NAME", "CHANGES"}}
+	for _, a := range actions {
+		t.rows = append(t.rows, []string{a.Op, a.Kind, a.Cluster, a.Name, strings.Join(a.Changes, "; ")})
+	}
+	return t
+}
+
+func resourcesTable(resources []runstate.Resource) table {
+	t := table{headers: []string{"KIND", "ID", "NAME", "CLUSTER", "TEST", "CREATED"}}
+	for _, r := range resources {
+		t.rows = append(t.rows, []string{r.Kind, r.ID, r.Name, r.ClusterID, r.Test, r.CreatedAt.Format(time.RFC3339)})
+	}
+	return t
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"dbaas_testing_task/runstate"
+)
+
+func runStateList(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("state list")
+	file := fs.String("f", os.Getenv("STATE_FILE"), "файл журнала запуска")
+	if err := parse(fs, args, "f"); err != nil {
+		return err
+	}
+	state, err := runstate.Open(*file)
+	if err != nil {
+		return err
+	}
+	resources := state.List()
+	return a.print(resources, resourcesTable(resources))
+}
+
+func runStateDestroy(ctx context.Context, a *app, args []string) error {
+	fs := a.newFlagSet("state destroy")
+	file := fs.String("f", os.Getenv("STATE_FILE"), "файл журнала запуска")
+	dryRun := fs.Bool("dry-run", false, "только показать, что будет удалено")
+	if err := parse(fs, args, "f"); err != nil {
+		return err
+	}
+	state, err := runstate.Open(*file)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		plan := state.Plan()
+		return a.print(plan, resourcesTable(plan))
+	}
+	destroyed, err := runstate.Destroy(ctx, a.client, state, a.interval, func(r runstate.Resource) {
+		fmt.Fprintf(a.stderr, "Удалён: %s\n", r)
+	})
+	if printErr := a.print(destroyed, resourcesTable(destroyed)); printErr != nil && err == nil {
+		err = printErr
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"dbaas_testing_task/redact"
+)
+
+// tokenFile возвращает путь к файлу, в котором хранится токен
+func tokenFile() (string, error) {
+	if path := os.Getenv("DBAASCTL_TOKEN_FILE"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("не удалось определить каталог настроек: %w", err)
+	}
+	return filepath.Join(dir, "dbaasctl", "token"), nil
+}
+
+// saveToken сохраняет токен в файл, доступный только владельцу
+func saveToken(token string) (string, error) {
+	path, err := tokenFile()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("не удалось создать каталог для токена: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("не удалось сохранить токен: %w", err)
+	}
+	return path, nil
+}
+
+// loadToken читает сохранённый токен. Пустая строка означает, что токен не сохранён
+func loadToken() (string, error) {
+	if token := os.Getenv("DBAASCTL_TOKEN"); token != "" {
+		return token, nil
+	}
+	path, err := tokenFile()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("не удалось прочитать токен: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// authenticate выставляет токен клиента: сохранённый или полученный по API_LOGIN и API_PASSWORD
+func (a *app) authenticate(ctx context.Context) error {
+	token, err := loadToken()
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		redact.Secret(token)
+		a.client.Token = token
+		return nil
+	}
+	login, password := os.Getenv("API_LOGIN"), os.Getenv("API_PASSWORD")
+	if login == "" || password == "" {
+		return usagef("нет токена: выполните dbaasctl login или задайте API_LOGIN и API_PASSWORD")
+	}
+	redact.Secret(password)
+	if err := a.client.Login(ctx, login, password); err != nil {
+		return err
+	}
+	redact.Secret(a.client.Token)
+	return nil
+}
@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultOpenAPISpecPath задаёт путь к спецификации API относительно корня репозитория
+const defaultOpenAPISpecPath = "api/openapi.yaml"
+
+// OpenAPISpec представляет подмножество спецификации OpenAPI 3, необходимое для проверки запросов и ответов.
+type OpenAPISpec struct {
+	Paths      map[string]map[string]*OpenAPIOperation `yaml:"paths"`
+	Components struct {
+		Schemas   map[string]*OpenAPISchema   `yaml:"schemas"`
+		Responses map[string]*OpenAPIResponse `yaml:"responses"`
+	} `yaml:"components"`
+}
+
+// OpenAPIOperation представляет операцию (метод) пути.
+type OpenAPIOperation struct {
+	RequestBody *struct {
+		Required bool                        `yaml:"required"`
+		Content  map[string]OpenAPIMediaType `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]*OpenAPIResponse `yaml:"responses"`
+}
+
+// OpenAPIResponse представляет описание ответа или ссылку на него.
+type OpenAPIResponse struct {
+	Ref     string                      `yaml:"$ref"`
+	Content map[string]OpenAPIMediaType `yaml:"content"`
+}
+
+// OpenAPIMediaType представляет тело запроса или ответа с конкретным типом содержимого.
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `yaml:"schema"`
+}
+
+// OpenAPISchema представляет поддерживаемое подмножество JSON Schema.
+type OpenAPISchema struct {
+	Ref                  string                    `yaml:"$ref"`
+	Type                 string                    `yaml:"type"`
+	Format               string                    `yaml:"format"`
+	Required             []string                  `yaml:"required"`
+	Properties           map[string]*OpenAPISchema `yaml:"properties"`
+	AdditionalProperties *bool                     `yaml:"additionalProperties"`
+	Items                *OpenAPISchema            `yaml:"items"`
+	Enum                 []interface{}             `yaml:"enum"`
+	Nullable             bool                      `yaml:"nullable"`
+}
+
+// LoadOpenAPISpec загружает спецификацию из YAML-файла
+func LoadOpenAPISpec(path string) (*OpenAPISpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать спецификацию: %w", err)
+	}
+	var spec OpenAPISpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать спецификацию: %w", err)
+	}
+	return &spec, nil
+}
+
+// FindOperation возвращает операцию и шаблон пути, соответствующие методу и пути запроса
+func (s *OpenAPISpec) FindOperation(method, path string) (*OpenAPIOperation, string, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	// Сортируем шаблоны, чтобы результат не зависел от порядка обхода map
+	templates := make([]string, 0, len(s.Paths))
+	for template := range s.Paths {
+		templates = append(templates, template)
+	}
+	sort.Strings(templates)
+
+	// Путь может совпасть с несколькими шаблонами (/x/{id} и /x/restore); шаблон без нужного метода
+	// не исключает остальные, а ошибка про метод возвращается, только если его нет ни в одном
+	matchedTemplate := ""
+	for _, template := range templates {
+		parts := strings.Split(strings.Trim(template, "/"), "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+		matched := true
+		for i, part := range parts {
+			isParam := strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}")
+			if !isParam && part != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		if operation, ok := s.Paths[template][strings.ToLower(method)]; ok {
+			return operation, template, nil
+		}
+		if matchedTemplate == "" {
+			matchedTemplate = template
+		}
+	}
+	if matchedTemplate != "" {
+		return nil, matchedTemplate, fmt.Errorf("метод %s не описан для %s", method, matchedTemplate)
+	}
+	return nil, "", fmt.Errorf("путь %s не описан в спецификации", path)
+}
+
+// ValidateRequest проверяет тело запроса по спецификации
+func (s *OpenAPISpec) ValidateRequest(method, path string, body []byte) []error {
+	operation, template, err := s.FindOperation(method, path)
+	if err != nil {
+		return []error{err}
+	}
+	where := fmt.Sprintf("запрос %s %s", method, template)
+
+	if operation.RequestBody == nil {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return []error{fmt.Errorf("%s: тело запроса не описано в спецификации", where)}
+		}
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if operation.RequestBody.Required {
+			return []error{fmt.Errorf("%s: отсутствует обязательное тело запроса", where)}
+		}
+		return nil
+	}
+	media, ok := operation.RequestBody.Content["application/json"]
+	if !ok || media.Schema == nil {
+		return []error{fmt.Errorf("%s: нет схемы application/json", where)}
+	}
+	return s.validateJSON(where, media.Schema, body)
+}
+
+// ValidateResponse проверяет статус и тело ответа по спецификации.
+// Для статусов ошибок, не описанных явно, используется ответ default
+func (s *OpenAPISpec) ValidateResponse(method, path string, status int, body []byte) []error {
+	operation, template, err := s.FindOperation(method, path)
+	if err != nil {
+		return []error{err}
+	}
+	where := fmt.Sprintf("ответ %d на %s %s", status, method, template)
+
+	response, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok && status >= 400 {
+		response, ok = operation.Responses["default"]
+	}
+	if !ok {
+		return []error{fmt.Errorf("%s: статус не описан в спецификации", where)}
+	}
+	response, err = s.resolveResponse(response)
+	if err != nil {
+		return []error{fmt.Errorf("%s: %w", where, err)}
+	}
+
+	media, ok := response.Content["application/json"]
+	if !ok || media.Schema == nil {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return []error{fmt.Errorf("%s: тело ответа не описано в спецификации", where)}
+		}
+		return nil
+	}
+	return s.validateJSON(where, media.Schema, body)
+}
+
+// resolveResponse разрешает ссылку на components/responses
+func (s *OpenAPISpec) resolveResponse(response *OpenAPIResponse) (*OpenAPIResponse, error) {
+	if response.Ref == "" {
+		return response, nil
+	}
+	name := strings.TrimPrefix(response.Ref, "#/components/responses/")
+	resolved, ok := s.Components.Responses[name]
+	if !ok {
+		return nil, fmt.Errorf("не найден ответ %s", response.Ref)
+	}
+	return resolved, nil
+}
+
+// resolveSchema разрешает ссылку на components/schemas
+func (s *OpenAPISpec) resolveSchema(schema *OpenAPISchema) (*OpenAPISchema, error) {
+	if schema.Ref == "" {
+		return schema, nil
+	}
+	name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+	resolved, ok := s.Components.Schemas[name]
+	if !ok {
+		return nil, fmt.Errorf("не найдена схема %s", schema.Ref)
+	}
+	return resolved, nil
+}
+
+// validateJSON разбирает тело как JSON и проверяет его по схеме
+func (s *OpenAPISpec) validateJSON(where string, schema *OpenAPISchema, body []byte) []error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return []error{fmt.Errorf("%s: тело не является JSON: %w", where, err)}
+	}
+	var errs []error
+	for _, err := range s.ValidateValue(schema, value, "$") {
+		errs = append(errs, fmt.Errorf("%s: %w", where, err))
+	}
+	return errs
+}
+
+// ValidateValue проверяет значение, полученное из JSON с UseNumber, по схеме. pointer указывает место в документе
+func (s *OpenAPISpec) ValidateValue(schema *OpenAPISchema, value interface{}, pointer string) []error {
+	schema, err := s.resolveSchema(schema)
+	if err != nil {
+		return []error{fmt.Errorf("%s: %w", pointer, err)}
+	}
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+		return []error{fmt.Errorf("%s: null вместо %s", pointer, schema.Type)}
+	}
+
+	var errs []error
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return []error{fmt.Errorf("%s: ожидался object, получен %T", pointer, value)}
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s: отсутствует обязательное поле %q", pointer, name))
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			if !ok {
+				if schema.AdditionalProperties != nil && !*schema.AdditionalProperties {
+					errs = append(errs, fmt.Errorf("%s: недокументированное поле %q", pointer, name))
+				}
+				continue
+			}
+			errs = append(errs, s.ValidateValue(property, object[name], pointer+"."+name)...)
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return []error{fmt.Errorf("%s: ожидался array, получен %T", pointer, value)}
+		}
+		if schema.Items != nil {
+			for i, item := range array {
+				errs = append(errs, s.ValidateValue(schema.Items, item, fmt.Sprintf("%s[%d]", pointer, i))...)
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return []error{fmt.Errorf("%s: ожидался string, получен %T", pointer, value)}
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return []error{fmt.Errorf("%s: ожидался integer, получен %T", pointer, value)}
+		}
+		if _, err := number.Int64(); err != nil {
+			return []error{fmt.Errorf("%s: ожидался integer, получено %s", pointer, number)}
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return []error{fmt.Errorf("%s: ожидался number, получен %T", pointer, value)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []error{fmt.Errorf("%s: ожидался boolean, получен %T", pointer, value)}
+		}
+	}
+
+	if len(schema.Enum) > 0 {
+		found := false
+		for _, allowed := range schema.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Errorf("%s: значение %v не входит в %v", pointer, value, schema.Enum))
+		}
+	}
+	return errs
+}
+
+var (
+	contractSpec     *OpenAPISpec
+	contractSpecErr  error
+	contractSpecOnce sync.Once
+
+	// Тесты, которые намеренно отправляют запросы, нарушающие спецификацию
+	uncheckedRequestsMu sync.Mutex
+	uncheckedRequests   = make(map[string]bool)
+)
+
+// contractChecksEnabled возвращает false, если проверка по спецификации отключена через DBAAS_OPENAPI_VALIDATION=off
+func contractChecksEnabled() bool {
+	return os.Getenv("DBAAS_OPENAPI_VALIDATION") != "off"
+}
+
+// loadContractSpec однократно загружает спецификацию из DBAAS_OPENAPI_SPEC или api/openapi.yaml
+func loadContractSpec() (*OpenAPISpec, error) {
+	contractSpecOnce.Do(func() {
+		path := os.Getenv("DBAAS_OPENAPI_SPEC")
+		if path == "" {
+			path = defaultOpenAPISpecPath
+		}
+		contractSpec, contractSpecErr = LoadOpenAPISpec(path)
+	})
+	return contractSpec, contractSpecErr
+}
+
+// SkipRequestContract отключает проверку тел запросов для теста и его подтестов.
+// Используется в негативных тестах, которые намеренно отправляют некорректные запросы; ответы по-прежнему проверяются
+func SkipRequestContract(t *testing.T) {
+	name := t.Name()
+	uncheckedRequestsMu.Lock()
+	uncheckedRequests[name] = true
+	uncheckedRequestsMu.Unlock()
+	t.Cleanup(func() {
+		uncheckedRequestsMu.Lock()
+		delete(uncheckedRequests, name)
+		uncheckedRequestsMu.Unlock()
+	})
+}
+
+// requestContractSkipped проверяет, отключена ли проверка запросов для теста или одного из его родителей
+func requestContractSkipped(t *testing.T) bool {
+	uncheckedRequestsMu.Lock()
+	defer uncheckedRequestsMu.Unlock()
+	for name := range uncheckedRequests {
+		if t.Name() == name || strings.HasPrefix(t.Name(), name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkContract проверяет запрос и ответ по спецификации и отмечает тест как проваленный при расхождениях
+func checkContract(t *testing.T, method, path string, requestBody []byte, status int, responseBody []byte) {
+	if !contractChecksEnabled() {
+		return
+	}
+	spec, err := loadContractSpec()
+	if err != nil {
+		Errorf(t, "Проверка по спецификации невозможна: %v", err)
+		return
+	}
+	// Пути в спецификации указаны без префикса, который может содержать API_BASE_URL
+	if base, err := url.Parse(apiBaseURL); err == nil {
+		path = strings.TrimPrefix(path, strings.TrimSuffix(base.Path, "/"))
+	}
+	var errs []error
+	if !requestContractSkipped(t) {
+		errs = append(errs, spec.ValidateRequest(method, path, requestBody)...)
+	}
+	errs = append(errs, spec.ValidateResponse(method, path, status, responseBody)...)
+	for _, err := range errs {
+		Errorf(t, "Нарушение спецификации API: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func loadTestSpec(t *testing.T) *OpenAPISpec {
+	spec, err := LoadOpenAPISpec(defaultOpenAPISpecPath)
+	if err != nil {
+		Fatalf(t, "Не удалось загрузить спецификацию: %v", err)
+	}
+	return spec
+}
+
+func TestOpenAPISpecCoversKnownEndpoints(t *testing.T) {
+	a := Assert(t)
+	spec := loadTestSpec(t)
+	endpoints := append([]Endpoint{{"POST", "/api/authorize"}}, KnownEndpoints...)
+	for _, e := range endpoints {
+		_, template, err := spec.FindOperation(e.Method, e.Path)
+		a.NoError(err, "%s не описан в спецификации", e)
+		a.Equal(e.Path, template, "%s сопоставлен с другим путём", e)
+	}
+}
+
+func TestFindOperationChecksEveryMatchingTemplate(t *testing.T) {
+	a := Assert(t)
+	spec := &OpenAPISpec{Paths: map[string]map[string]*OpenAPIOperation{
+		"/api/dumps/{dump_id}": {"get": {}},
+		"/api/dumps/restore":   {"post": {}},
+	}}
+	_, template, err := spec.FindOperation("POST", "/api/dumps/restore")
+	a.NoError(err)
+	a.Equal("/api/dumps/restore", template)
+	_, template, err = spec.FindOperation("GET", "/api/dumps/restore")
+	a.NoError(err)
+	a.Equal("/api/dumps/{dump_id}", template)
+	_, _, err = spec.FindOperation("DELETE", "/api/dumps/restore")
+	a.ErrorContains(err, "метод DELETE не описан")
+}
+
+func TestOpenAPIRequestsFromModels(t *testing.T) {
+	a := Assert(t)
+	spec := loadTestSpec(t)
+	replicas := 2
+	target := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		method, path string
+		body         interface{}
+	}{
+		{"POST", "/api/clusters", CreateClusterRequest{
+			TypeID: "type", DiskSize: 3221225472, Mode: "create", ReplicasCount: 1, CreationMode: CreationModePITR,
+			Name: "test", FlavorID: "flavor", TypeName: "Postgres Pro Enterprise", Az: "GZ1", HAManager: "patroni",
+			Recovery: &RecoveryTarget{SourceClusterID: "source", TargetTime: &target},
+		}},
+		{"PATCH", "/api/clusters/id", UpdateClusterRequest{ReplicasCount: &replicas, Options: &Options{AutoRestart: true}}},
+		{"PATCH", "/api/clusters/id/parameters", UpdateClusterParametersRequest{Parameters: map[string]string{"work_mem": "8MB"}}},
+		{"POST", "/api/clusters/id/tablespaces", CreateTableSpaceRequest{Name: "ts"}},
+		{"POST", "/api/clusters/id/databases", CreateDBRequest{Name: "db", TableSpaceID: "ts", Encoding: "UTF8"}},
+		{"PATCH", "/api/clusters/id/databases/db", UpdateDBRequest{Owner: "owner"}},
+		{"POST", "/api/clusters/id/users", CreateClusterUserRequest{Databases: []string{"db"}, Roles: []string{}, Name: "u", Password: "p"}},
+		{"PATCH", "/api/clusters/id/users/u", UpdateClusterUserRequest{Password: "p"}},
+		{"POST", "/api/clusters/id/databases/db/dumps", CreateDumpRequest{Name: "dump"}},
+		{"POST", "/api/clusters/id/databases/db/dump_restore", RestoreDumpRequest{DumpID: "dump", Mode: "full"}},
+	}
+	for _, tc := range cases {
+		body, err := json.Marshal(tc.body)
+		a.NoError(err)
+		a.Empty(spec.ValidateRequest(tc.method, tc.path, body), "%s %s: модель не соответствует спецификации", tc.method, tc.path)
+	}
+}
+
+func TestOpenAPIValidation(t *testing.T) {
+	a := Assert(t)
+	spec := loadTestSpec(t)
+
+	cases := []struct {
+		name    string
+		status  int
+		body    string
+		invalid bool
+	}{
+		{"valid", 200, `{"id": "c1", "status": "OK", "disk_size": 3221225472, "options": {"auto_restart": true}}`, false},
+		{"undocumented field", 200, `{"id": "c1", "status": "OK", "secret": "x"}`, true},
+		{"undocumented nested field", 200, `{"id": "c1", "status": "OK", "options": {"unknown": 1}}`, true},
+		{"missing required field", 200, `{"id": "c1"}`, true},
+		{"wrong type", 200, `{"id": "c1", "status": "OK", "disk_size": "3G"}`, true},
+		{"fractional integer", 200, `{"id": "c1", "status": "OK", "replicas_count": 1.5}`, true},
+		{"not json", 200, `status: OK`, true},
+		{"undocumented status", 201, `{"id": "c1", "status": "OK"}`, true},
+		{"error via default", 404, `{"code": "not_found", "message": "cluster not found"}`, false},
+		{"error with undocumented field", 404, `{"message": "not found", "trace": "..."}`, true},
+	}
+	for _, tc := range cases {
+		errs := spec.ValidateResponse("GET", "/api/clusters/c1", tc.status, []byte(tc.body))
+		if tc.invalid {
+			a.NotEmpty(errs, "%s: нарушение не обнаружено", tc.name)
+		} else {
+			a.Empty(errs, "%s: ложное нарушение", tc.name)
+		}
+	}
+
+	a.NotEmpty(spec.ValidateRequest("POST", "/api/clusters", []byte(`{"name": "x"}`)), "не обнаружено отсутствие обязательных полей")
+	a.NotEmpty(spec.ValidateRequest("POST", "/api/clusters/c1/backups", []byte(`{"x": 1}`)), "не обнаружено недокументированное тело запроса")
+	a.Empty(spec.ValidateResponse("DELETE", "/api/clusters/c1", 204, nil), "пустой ответ 204 отклонён")
+	_, _, err := spec.FindOperation("GET", "/api/unknown")
+	a.Error(err, "неизвестный путь найден в спецификации")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClusterCreationModes(t *testing.T) {
+	a := Assert(t)
+	RequireAPIEnv(t)
+	Authorize(t)
+
+	ctx := context.Background()
+	source := newScalingFixture(t, ctx, DefaultClusterRequest(t, "source-"+RandomSuffix()))
+	sourceUsers, err := FetchUsers(ctx, source.session)
+	a.NoError(err, "не удалось прочитать исходные данные")
+
+	backupId := CreateBackup(t, source.clusterId)
+	t.Cleanup(func() { DeleteBackup(t, backupId) })
+	dumpId := CreateDump(t, source.clusterId, source.dbId, "creationModes")
+	t.Cleanup(func() { DeleteDump(t, dumpId) })
+
+	cases := []struct {
+		mode     string
+		recovery RecoveryTarget
+	}{
+		{CreationModeBackup, RecoveryTarget{SourceClusterID: source.clusterId, BackupID: backupId}},
+		{CreationModeClone, RecoveryTarget{SourceClusterID: source.clusterId}},
+		{CreationModeDump, RecoveryTarget{SourceClusterID: source.clusterId, DumpID: dumpId}},
+	}
+
+	// Кластеры создаются в родительском тесте, чтобы пережить удаление источника в конце
+	sessions := make(map[string]*DBSession)
+	clusters := make(map[string]string)
+	for _, tc := range cases {
+		request := DefaultClusterRequest(t, tc.mode+"-"+RandomSuffix())
+		request.CreationMode = tc.mode
+		recovery := tc.recovery
+		request.Recovery = &recovery
+		clusterId := ProvisionCluster(t, request)
+
+		// Логический дамп не переносит пользователей кластера
+		if _, ok := FindClusterUser(t, clusterId, source.userName); !ok {
+			CreateClusterUser(t, clusterId, CreateClusterUserRequest{
+				Databases: []string{source.dbName},
+				Roles:     []string{"pg_write_all_data", "pg_read_all_data"},
+				Name:      source.userName,
+				Password:  source.userPassword,
+			})
+		}
+		clusters[tc.mode] = clusterId
+		sessions[tc.mode] = NewDBSession(t, ctx, ConnectionString(t, clusterId, source.dbName, source.userName, source.userPassword), LoadPoolConfig(t))
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.mode, func(t *testing.T) {
+			a := Assert(t)
+			session := sessions[tc.mode]
+
+			users, err := FetchUsers(ctx, session)
+			a.NoError(err, "не удалось прочитать данные нового кластера")
+			a.ElementsMatch(sourceUsers, users, "данные нового кластера не совпадают с источником")
+
+			// Запись в новый кластер не должна попадать в источник
+			_, err = session.Exec(ctx, `
+				INSERT INTO test_schema.users (name, email, age)
+				VALUES ($1, $2, $3)
+			`, tc.mode, tc.mode+"@example.com", 42)
+			a.NoError(err, "не удалось записать данные в новый кластер")
+			current, err := FetchUsers(ctx, source.session)
+			a.NoError(err, "не удалось прочитать исходные данные")
+			a.Equal(len(sourceUsers), len(current), "запись в новый кластер изменила источник")
+		})
+	}
+
+	// Удаление источника не должно затрагивать созданные из него кластеры
+	DeleteCluster(t, source.clusterId)
+	for _, tc := range cases {
+		a.Equal("OK", GetCluster(t, clusters[tc.mode]).Status, "кластер %s пострадал от удаления источника", tc.mode)
+		users, err := FetchUsers(ctx, sessions[tc.mode])
+		a.NoError(err, "данные кластера %s недоступны после удаления источника", tc.mode)
+		a.Equal(len(sourceUsers)+1, len(users), "данные кластера %s изменились после удаления источника", tc.mode)
+	}
+}
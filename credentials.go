@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"dbaas_testing_task/credentials"
+	"dbaas_testing_task/redact"
+)
+
+// Учётные данные пользователей баз данных хранятся в хранилище, выбранном SECRET_STORE
+// (см. credentials.FromEnv). Из хранилища они берутся и при продолжении прерванного сценария
+var (
+	secretStoreOnce sync.Once
+	secretStore     credentials.Store
+	secretStoreErr  error
+)
+
+// SecretStore возвращает хранилище учётных данных
+func SecretStore(t *testing.T) credentials.Store {
+	secretStoreOnce.Do(func() {
+		secretStore, secretStoreErr = credentials.FromEnv()
+	})
+	if secretStoreErr != nil {
+		Fatalf(t, "Хранилище учётных данных недоступно: %v", secretStoreErr)
+	}
+	return secretStore
+}
+
+// DBUserCredentials возвращает учётные данные пользователя базы данных role теста t. Если их нет
+// в хранилище, генерируются новые имя и пароль и сохраняются в нём. Учётные данные удаляются
+// из хранилища после успешного теста; после неудачного остаются для отладки и RESUME=1
+func DBUserCredentials(t *testing.T, role string) credentials.Credential {
+	store := SecretStore(t)
+	key := t.Name() + "/" + role
+	ctx := context.Background()
+	credential, err := store.Get(ctx, key)
+	switch {
+	case err == nil:
+		Logf(t, "Учётные данные %s взяты из хранилища", key)
+	case errors.Is(err, credentials.ErrNotFound):
+		// Шаги, создавшие пользователя в прерванном запуске, будут пропущены, а новый пароль
+		// к нему не подойдёт
+		if resume && !credentials.Persistent(store) {
+			Fatalf(t, "RESUME=1: учётных данных %s нет в хранилище, а SECRET_STORE=env не сохраняет их между запусками. "+
+				"Запускайте сценарий с SECRET_STORE=file или SECRET_STORE=vault либо задайте %sUSERNAME и %sPASSWORD",
+				key, credentials.EnvName(key), credentials.EnvName(key))
+		}
+		credential = credentials.Credential{Username: role + "_" + RandomSuffix(), Password: RandomPassword()}
+		if err := store.Put(ctx, key, credential); err != nil {
+			Fatalf(t, "Не удалось сохранить учётные данные %s: %v", key, err)
+		}
+	default:
+		Fatalf(t, "Не удалось получить учётные данные %s: %v", key, err)
+	}
+	redact.Secret(credential.Password)
+	t.Cleanup(func() {
+		if t.Failed() {
+			return
+		}
+		if err := store.Delete(ctx, key); err != nil {
+			Errorf(t, "Не удалось удалить учётные данные %s из хранилища: %v", key, err)
+		}
+	})
+	return credential
+}
+
+// RandomPassword возвращает случайный пароль для тестовых пользователей базы данных,
+// удовлетворяющий credentials.DefaultPolicy
+func RandomPassword() string {
+	password := credentials.Generate(credentials.DefaultPolicy)
+	redact.Secret(password)
+	return password
+}
@@ -0,0 +1,162 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"dbaas_testing_task/credentials/vaulttest"
+)
+
+func TestGenerate(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		password := Generate(DefaultPolicy)
+		assert.NoError(t, DefaultPolicy.Check(password))
+		assert.Len(t, password, DefaultPolicy.Length)
+		assert.False(t, seen[password], "пароль повторился")
+		seen[password] = true
+	}
+	// Минимумы по классам важнее длины
+	short := Policy{Length: 2, MinUpper: 1, MinLower: 1, MinDigits: 1, MinSymbols: 1, Symbols: "#"}
+	password := Generate(short)
+	assert.Len(t, password, 4)
+	assert.NoError(t, short.Check(password))
+	assert.Contains(t, password, "#")
+}
+
+func TestPolicyCheck(t *testing.T) {
+	cases := []struct {
+		password string
+		err      string
+	}{
+		{"Aa1!", "длина 4 меньше 24"},
+		{"abcdefghijklmnopqrstuvwxyz", "заглавных букв 0"},
+		{"ABCDEFGHIJKLMNOPQRSTUVWX12!!", "строчных букв 0"},
+		{"ABcdefghijklmnopqrstuvwxyz!!", "цифр 0"},
+		{"ABcdefghijklmnopqrstuvwxyz12", "специальных символов 0"},
+		{"ABcdefghijklmnopqrstuvwx12!! ", "недопустимый символ ' '"},
+		{"ABcdefghijklmnopqrstuvwx12!!", ""},
+	}
+	for _, tc := range cases {
+		err := DefaultPolicy.Check(tc.password)
+		if tc.err == "" {
+			assert.NoError(t, err, tc.password)
+			continue
+		}
+		if assert.Error(t, err, tc.password) {
+			assert.Contains(t, err.Error(), tc.err, tc.password)
+		}
+	}
+}
+
+// testStore проверяет общий контракт хранилища
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	_, err := store.Get(ctx, "TestStore/app")
+	assert.True(t, errors.Is(err, ErrNotFound), "ожидалась ErrNotFound, получено %v", err)
+
+	credential := Credential{Username: "app_x1", Password: Generate(DefaultPolicy)}
+	assert.NoError(t, store.Put(ctx, "TestStore/app", credential))
+	got, err := store.Get(ctx, "TestStore/app")
+	assert.NoError(t, err)
+	assert.Equal(t, credential, got)
+
+	assert.NoError(t, store.Delete(ctx, "TestStore/app"))
+	_, err = store.Get(ctx, "TestStore/app")
+	assert.True(t, errors.Is(err, ErrNotFound), "учётные данные не удалены")
+	assert.NoError(t, store.Delete(ctx, "TestStore/app"), "повторное удаление")
+}
+
+func TestEnvStore(t *testing.T) {
+	t.Setenv("DB_CREDENTIALS_TESTSTORE_APP_USERNAME", "")
+	t.Setenv("DB_CREDENTIALS_TESTSTORE_APP_PASSWORD", "")
+	testStore(t, EnvStore{})
+
+	assert.Equal(t, "DB_CREDENTIALS_TESTENDTOEND_APP_", EnvName("TestEndToEnd/app"))
+	t.Setenv("DB_CREDENTIALS_PRESET_USERNAME", "preset")
+	t.Setenv("DB_CREDENTIALS_PRESET_PASSWORD", "secret")
+	got, err := EnvStore{}.Get(context.Background(), "preset")
+	assert.NoError(t, err)
+	assert.Equal(t, Credential{Username: "preset", Password: "secret"}, got)
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	store, err := OpenFile(path)
+	assert.NoError(t, err)
+	testStore(t, store)
+
+	// Учётные данные переживают повторное открытие, а файл доступен только владельцу
+	assert.NoError(t, store.Put(context.Background(), "TestStore/kept", Credential{Username: "u", Password: "p"}))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	reopened, err := OpenFile(path)
+	assert.NoError(t, err)
+	got, err := reopened.Get(context.Background(), "TestStore/kept")
+	assert.NoError(t, err)
+	assert.Equal(t, "p", got.Password)
+
+	assert.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = OpenFile(path)
+	assert.Error(t, err)
+}
+
+func TestVaultStore(t *testing.T) {
+	server := vaulttest.NewServer(t)
+	store := &VaultStore{Addr: server.URL, Token: vaulttest.Token}
+	testStore(t, store)
+
+	assert.NoError(t, store.Put(context.Background(), "TestStore/app", Credential{Username: "u", Password: "p"}))
+	data, ok := server.Secret("dbaas-tests/TestStore/app")
+	if assert.True(t, ok, "ключ записан не по ожидаемому пути") {
+		assert.JSONEq(t, `{"username":"u","password":"p"}`, string(data))
+	}
+
+	denied := &VaultStore{Addr: server.URL, Token: "wrong"}
+	_, err := denied.Get(context.Background(), "TestStore/app")
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "403"), err.Error())
+	}
+}
+
+func TestPersistent(t *testing.T) {
+	assert.False(t, Persistent(EnvStore{}))
+	store, err := OpenFile(filepath.Join(t.TempDir(), "credentials.json"))
+	assert.NoError(t, err)
+	assert.True(t, Persistent(store))
+	assert.True(t, Persistent(&VaultStore{}))
+}
+
+func TestFromEnv(t *testing.T) {
+	cases := []struct {
+		env map[string]string
+		err string
+	}{
+		{map[string]string{"SECRET_STORE": ""}, ""},
+		{map[string]string{"SECRET_STORE": "file", "SECRET_STORE_FILE": ""}, "SECRET_STORE_FILE"},
+		{map[string]string{"SECRET_STORE": "file", "SECRET_STORE_FILE": filepath.Join(t.TempDir(), "c.json")}, ""},
+		{map[string]string{"SECRET_STORE": "vault", "VAULT_TOKEN": ""}, "VAULT_TOKEN"},
+		{map[string]string{"SECRET_STORE": "vault", "VAULT_TOKEN": "t"}, ""},
+		{map[string]string{"SECRET_STORE": "keychain"}, "ожидается env, file или vault"},
+	}
+	for _, tc := range cases {
+		for k, v := range tc.env {
+			t.Setenv(k, v)
+		}
+		_, err := FromEnv()
+		if tc.err == "" {
+			assert.NoError(t, err, tc.env)
+			continue
+		}
+		if assert.Error(t, err, tc.env) {
+			assert.Contains(t, err.Error(), tc.err, tc.env)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package credentials
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// EnvPrefix - префикс переменных окружения EnvStore
+const EnvPrefix = "DB_CREDENTIALS_"
+
+// EnvStore хранит учётные данные в переменных окружения процесса: ключ "TestEndToEnd/app"
+// соответствует DB_CREDENTIALS_TESTENDTOEND_APP_USERNAME и DB_CREDENTIALS_TESTENDTOEND_APP_PASSWORD.
+// Так учётные данные можно задать заранее; записанные значения живут только до завершения процесса
+type EnvStore struct{}
+
+// EnvName возвращает префикс переменных окружения для ключа
+func EnvName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key)
+	return EnvPrefix + strings.ToUpper(name) + "_"
+}
+
+// Get возвращает учётные данные из переменных окружения
+func (EnvStore) Get(ctx context.Context, key string) (Credential, error) {
+	name := EnvName(key)
+	credential := Credential{Username: os.Getenv(name + "USERNAME"), Password: os.Getenv(name + "PASSWORD")}
+	if credential.Username == "" || credential.Password == "" {
+		return Credential{}, ErrNotFound
+	}
+	return credential, nil
+}
+
+// Put записывает учётные данные в переменные окружения процесса
+func (EnvStore) Put(ctx context.Context, key string, credential Credential) error {
+	name := EnvName(key)
+	if err := os.Setenv(name+"USERNAME", credential.Username); err != nil {
+		return err
+	}
+	return os.Setenv(name+"PASSWORD", credential.Password)
+}
+
+// Delete удаляет переменные окружения ключа
+func (EnvStore) Delete(ctx context.Context, key string) error {
+	name := EnvName(key)
+	if err := os.Unsetenv(name + "USERNAME"); err != nil {
+		return err
+	}
+	return os.Unsetenv(name + "PASSWORD")
+}
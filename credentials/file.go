@@ -0,0 +1,94 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileStore хранит учётные данные в JSON-файле, доступном только владельцу. Файл переписывается
+// целиком после каждого изменения, поэтому учётные данные переживают аварийное завершение процесса
+type FileStore struct {
+	path string
+
+	mu          sync.Mutex
+	credentials map[string]Credential
+}
+
+// OpenFile открывает хранилище в файле path; отсутствующий файл означает пустое хранилище
+func OpenFile(path string) (*FileStore, error) {
+	s := &FileStore{path: path, credentials: make(map[string]Credential)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать хранилище учётных данных: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.credentials); err != nil {
+		return nil, fmt.Errorf("хранилище учётных данных %s повреждено: %w", path, err)
+	}
+	if s.credentials == nil {
+		s.credentials = make(map[string]Credential)
+	}
+	return s, nil
+}
+
+// Get возвращает учётные данные ключа
+func (s *FileStore) Get(ctx context.Context, key string) (Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	credential, ok := s.credentials[key]
+	if !ok {
+		return Credential{}, ErrNotFound
+	}
+	return credential, nil
+}
+
+// Put сохраняет учётные данные ключа
+func (s *FileStore) Put(ctx context.Context, key string, credential Credential) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.credentials[key] = credential
+	return s.save()
+}
+
+// Delete удаляет учётные данные ключа
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.credentials[key]; !ok {
+		return nil
+	}
+	delete(s.credentials, key)
+	return s.save()
+}
+
+func (s *FileStore) save() error {
+	data, err := json.MarshalIndent(s.credentials, "", "  ")
+	if err != nil {
+		return err
+	}
+	// os.CreateTemp создаёт файл с правами 0600
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("не удалось записать хранилище учётных данных: %w", err)
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("не удалось записать хранилище учётных данных: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Package credentials генерирует пароли пользователей баз данных по политике сложности и хранит
+// учётные данные в подключаемом хранилище: переменных окружения, файле или KV-хранилище Vault.
+package credentials
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Наборы символов паролей. Символы DefaultSymbols допустимы без экранирования и в URL строки
+// подключения, и в строке вида key=value
+const (
+	Upper          = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	Lower          = "abcdefghijkmnopqrstuvwxyz"
+	Digits         = "23456789"
+	DefaultSymbols = "!-_.~"
+)
+
+// Policy представляет политику сложности пароля
+type Policy struct {
+	Length     int
+	MinUpper   int
+	MinLower   int
+	MinDigits  int
+	MinSymbols int
+	// Symbols - допустимые специальные символы; пустое значение означает DefaultSymbols
+	Symbols string
+}
+
+// DefaultPolicy - политика паролей, которые генерируют тесты
+var DefaultPolicy = Policy{Length: 24, MinUpper: 2, MinLower: 2, MinDigits: 2, MinSymbols: 2}
+
+func (p Policy) symbols() string {
+	if p.Symbols == "" {
+		return DefaultSymbols
+	}
+	return p.Symbols
+}
+
+// Generate возвращает случайный пароль, удовлетворяющий политике. Если минимумы по классам
+// символов в сумме больше Length, пароль удлиняется до их суммы
+func Generate(p Policy) string {
+	symbols := p.symbols()
+	var password []byte
+	for _, class := range []struct {
+		chars string
+		min   int
+	}{{Upper, p.MinUpper}, {Lower, p.MinLower}, {Digits, p.MinDigits}, {symbols, p.MinSymbols}} {
+		for i := 0; i < class.min; i++ {
+			password = append(password, class.chars[randomInt(len(class.chars))])
+		}
+	}
+	all := Upper + Lower + Digits + symbols
+	for len(password) < p.Length {
+		password = append(password, all[randomInt(len(all))])
+	}
+	// Перемешиваем, чтобы классы символов не шли в предсказуемом порядке
+	for i := len(password) - 1; i > 0; i-- {
+		j := randomInt(i + 1)
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password)
+}
+
+// Check возвращает ошибку с перечнем нарушений политики или nil
+func (p Policy) Check(password string) error {
+	var upper, lower, digits, symbols int
+	var violations []string
+	for _, r := range password {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper++
+		case r >= 'a' && r <= 'z':
+			lower++
+		case r >= '0' && r <= '9':
+			digits++
+		case strings.ContainsRune(p.symbols(), r):
+			symbols++
+		default:
+			violations = append(violations, fmt.Sprintf("недопустимый символ %q", r))
+		}
+	}
+	if n := len([]rune(password)); n < p.Length {
+		violations = append(violations, fmt.Sprintf("длина %d меньше %d", n, p.Length))
+	}
+	for _, class := range []struct {
+		name     string
+		got, min int
+	}{
+		{"заглавных букв", upper, p.MinUpper},
+		{"строчных букв", lower, p.MinLower},
+		{"цифр", digits, p.MinDigits},
+		{"специальных символов", symbols, p.MinSymbols},
+	} {
+		if class.got < class.min {
+			violations = append(violations, fmt.Sprintf("%s %d, нужно не меньше %d", class.name, class.got, class.min))
+		}
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("пароль не соответствует политике: %s", strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// randomInt возвращает криптографически случайное число из [0, n)
+func randomInt(n int) int {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		// crypto/rand не возвращает ошибок на поддерживаемых платформах
+		panic(err)
+	}
+	return int(v.Int64())
+}
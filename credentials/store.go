@@ -0,0 +1,61 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrNotFound возвращается, если учётных данных с таким ключом нет в хранилище
+var ErrNotFound = errors.New("учётные данные не найдены")
+
+// Credential представляет учётные данные пользователя базы данных
+type Credential struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Store представляет хранилище учётных данных. Ключ - путь вида "TestEndToEnd/app"
+type Store interface {
+	Get(ctx context.Context, key string) (Credential, error)
+	Put(ctx context.Context, key string, credential Credential) error
+	Delete(ctx context.Context, key string) error
+}
+
+// Persistent сообщает, переживают ли записанные в хранилище учётные данные завершение процесса
+func Persistent(store Store) bool {
+	_, inMemory := store.(EnvStore)
+	return !inMemory
+}
+
+// FromEnv открывает хранилище, выбранное переменной SECRET_STORE:
+//
+//	env (по умолчанию) - переменные окружения процесса, см. EnvStore
+//	file - JSON-файл SECRET_STORE_FILE, см. FileStore
+//	vault - KV-хранилище Vault по VAULT_ADDR и VAULT_TOKEN, см. VaultStore
+func FromEnv() (Store, error) {
+	switch kind := os.Getenv("SECRET_STORE"); kind {
+	case "", "env":
+		return EnvStore{}, nil
+	case "file":
+		path := os.Getenv("SECRET_STORE_FILE")
+		if path == "" {
+			return nil, errors.New("SECRET_STORE=file: не задан файл SECRET_STORE_FILE")
+		}
+		return OpenFile(path)
+	case "vault":
+		store := &VaultStore{
+			Addr:   os.Getenv("VAULT_ADDR"),
+			Token:  os.Getenv("VAULT_TOKEN"),
+			Mount:  os.Getenv("VAULT_KV_MOUNT"),
+			Prefix: os.Getenv("VAULT_KV_PREFIX"),
+		}
+		if store.Token == "" {
+			return nil, errors.New("SECRET_STORE=vault: не задан VAULT_TOKEN")
+		}
+		return store, nil
+	default:
+		return nil, fmt.Errorf("SECRET_STORE=%s: ожидается env, file или vault", kind)
+	}
+}
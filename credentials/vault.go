@@ -0,0 +1,119 @@
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Значения VaultStore по умолчанию совпадают с настройками `vault server -dev`
+const (
+	DefaultVaultAddr   = "http://127.0.0.1:8200"
+	DefaultVaultMount  = "secret"
+	DefaultVaultPrefix = "dbaas-tests"
+)
+
+// VaultStore хранит учётные данные в KV-хранилище Vault версии 2: ключ "TestEndToEnd/app"
+// записывается в <Mount>/data/<Prefix>/TestEndToEnd/app
+type VaultStore struct {
+	Addr   string
+	Token  string
+	Mount  string
+	Prefix string
+	// HTTPClient выполняет запросы; nil означает http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// vaultData представляет тело запроса записи и поле data ответа чтения KV версии 2
+type vaultData struct {
+	Data Credential `json:"data"`
+}
+
+// Get читает последнюю версию учётных данных ключа
+func (s *VaultStore) Get(ctx context.Context, key string) (Credential, error) {
+	var response struct {
+		Data vaultData `json:"data"`
+	}
+	if err := s.do(ctx, http.MethodGet, "data", key, nil, &response); err != nil {
+		return Credential{}, err
+	}
+	return response.Data.Data, nil
+}
+
+// Put записывает новую версию учётных данных ключа
+func (s *VaultStore) Put(ctx context.Context, key string, credential Credential) error {
+	return s.do(ctx, http.MethodPost, "data", key, vaultData{Data: credential}, nil)
+}
+
+// Delete удаляет ключ вместе со всеми версиями. Отсутствующий ключ не считается ошибкой
+func (s *VaultStore) Delete(ctx context.Context, key string) error {
+	err := s.do(ctx, http.MethodDelete, "metadata", key, nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *VaultStore) do(ctx context.Context, method, kind, key string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.url(kind, key), reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", s.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Vault недоступен: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&vaultErr)
+		return fmt.Errorf("Vault: %s %s: статус %d %s", method, key, resp.StatusCode, strings.Join(vaultErr.Errors, "; "))
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("Vault: некорректный ответ на %s %s: %w", method, key, err)
+	}
+	return nil
+}
+
+func (s *VaultStore) url(kind, key string) string {
+	addr, mount, prefix := s.Addr, s.Mount, s.Prefix
+	if addr == "" {
+		addr = DefaultVaultAddr
+	}
+	if mount == "" {
+		mount = DefaultVaultMount
+	}
+	if prefix == "" {
+		prefix = DefaultVaultPrefix
+	}
+	return fmt.Sprintf("%s/v1/%s/%s/%s/%s", strings.TrimRight(addr, "/"), mount, kind, prefix, strings.TrimLeft(key, "/"))
+}
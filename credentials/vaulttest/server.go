@@ -0,0 +1,86 @@
+// Package vaulttest содержит хранящую данные в памяти замену `vault server -dev` для тестов:
+// KV-хранилище версии 2 с чтением, записью и удалением ключей.
+package vaulttest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Token - единственный токен, который принимает замена
+const Token = "dev-root-token"
+
+// Mount - путь KV-хранилища, как у `vault server -dev`
+const Mount = "secret"
+
+// Server имитирует KV-хранилище Vault версии 2. Хранится только последняя версия ключа
+type Server struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	secrets map[string]json.RawMessage
+}
+
+// NewServer запускает замену; она останавливается по завершении теста
+func NewServer(t interface{ Cleanup(func()) }) *Server {
+	s := &Server{secrets: make(map[string]json.RawMessage)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Secret возвращает данные ключа path (без префикса хранилища) в виде JSON
+func (s *Server) Secret(path string) (json.RawMessage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.secrets[path]
+	return data, ok
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != Token {
+		reply(w, http.StatusForbidden, map[string][]string{"errors": {"permission denied"}})
+		return
+	}
+	prefix := "/v1/" + Mount + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		reply(w, http.StatusNotFound, map[string][]string{"errors": {}})
+		return
+	}
+	kind, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case kind == "data" && r.Method == http.MethodGet:
+		data, ok := s.secrets[path]
+		if !ok {
+			reply(w, http.StatusNotFound, map[string][]string{"errors": {}})
+			return
+		}
+		reply(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"data": data}})
+	case kind == "data" && (r.Method == http.MethodPost || r.Method == http.MethodPut):
+		var body struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.Data) == 0 {
+			reply(w, http.StatusBadRequest, map[string][]string{"errors": {"no data provided"}})
+			return
+		}
+		s.secrets[path] = body.Data
+		reply(w, http.StatusOK, map[string]interface{}{"data": map[string]int{"version": 1}})
+	case kind == "metadata" && r.Method == http.MethodDelete:
+		delete(s.secrets, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		reply(w, http.StatusMethodNotAllowed, map[string][]string{"errors": {"unsupported operation"}})
+	}
+}
+
+func reply(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"dbaas_testing_task/metrics"
+	"dbaas_testing_task/runstate"
+)
+
+// CreateDatabase создаёт базу данных в кластере, дожидается статуса OK и возвращает её ID
+func CreateDatabase(t *testing.T, clusterId string, request CreateDBRequest) string {
+	started := time.Now()
+	resp, _ := makeRequest(t, "POST", apiURL("/api/clusters/%s/databases", clusterId), request, authHeaders())
+	if resp.StatusCode != http.StatusCreated {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 201 при создании базы данных, получен: %d", resp.StatusCode)
+	}
+
+	var response CreateDBResponse
+	parseResponseBody(t, resp, &response)
+	Logf(t, "Database %s created with ID: %s", request.Name, response.Id)
+	JournalResource(t, runstate.KindDatabase, response.Id, clusterId, request.Name)
+
+	defer RecordOperation(t, metrics.OpDatabaseCreate, started)
+	WaitForStatus(t, "Database", apiURL("/api/clusters/%s/databases/%s", clusterId, response.Id))
+	return response.Id
+}
+
+// ListDatabases возвращает все базы данных кластера
+func ListDatabases(t *testing.T, clusterId string) []Database {
+	resp, _ := makeRequest(t, "GET", apiURL("/api/clusters/%s/databases", clusterId), nil, authHeaders())
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 200 при получении списка баз данных, получен: %d", resp.StatusCode)
+	}
+	var databases []Database
+	parseResponseBody(t, resp, &databases)
+	return databases
+}
+
+// GetDatabase возвращает базу данных кластера по ID
+func GetDatabase(t *testing.T, clusterId, dbId string) Database {
+	resp, _ := makeRequest(t, "GET", apiURL("/api/clusters/%s/databases/%s", clusterId, dbId), nil, authHeaders())
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 200 при получении базы данных %s, получен: %d", dbId, resp.StatusCode)
+	}
+	var db Database
+	parseResponseBody(t, resp, &db)
+	return db
+}
+
+// FindDatabase ищет базу данных кластера по имени. Второе значение равно false, если база не найдена
+func FindDatabase(t *testing.T, clusterId, name string) (Database, bool) {
+	for _, db := range ListDatabases(t, clusterId) {
+		if db.Name == name {
+			return db, true
+		}
+	}
+	return Database{}, false
+}
+
+// UpdateDatabase изменяет владельца и/или tablespace базы данных и дожидается статуса OK
+func UpdateDatabase(t *testing.T, clusterId, dbId string, request UpdateDBRequest) {
+	resp, _ := makeRequest(t, "PATCH", apiURL("/api/clusters/%s/databases/%s", clusterId, dbId), request, authHeaders())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		Fatalf(t, "Ожидался статус 200 или 202 при изменении базы данных %s, получен: %d", dbId, resp.StatusCode)
+	}
+	WaitForStatus(t, "Database", apiURL("/api/clusters/%s/databases/%s", clusterId, dbId))
+	Logf(t, "Database %s updated", dbId)
+}
+
+// DeleteDatabase удаляет базу данных кластера
+func DeleteDatabase(t *testing.T, clusterId, dbId string) {
+	resp, _ := makeRequest(t, "DELETE", apiURL("/api/clusters/%s/databases/%s", clusterId, dbId), nil, authHeaders())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		Fatalf(t, "Ожидался статус 204 при удалении базы данных %s, получен: %d", dbId, resp.StatusCode)
+	}
+	ForgetResource(t, runstate.KindDatabase, dbId)
+	Logf(t, "Deleted database with ID: %s", dbId)
+}
+
+// PgDatabase содержит сведения о базе данных из системного каталога pg_database.
+type PgDatabase struct {
+	Owner      string
+	Encoding   string
+	Collate    string
+	Ctype      string
+	TableSpace string
+}
+
+// QueryPgDatabase читает сведения о базе данных name из pg_database.
+// Второе значение равно false, если базы с таким именем нет
+func QueryPgDatabase(ctx context.Context, s *DBSession, name string) (PgDatabase, bool, error) {
+	var info PgDatabase
+	var count int
+	err := s.QueryRow(ctx, `SELECT count(*) FROM pg_database WHERE datname = $1`, []interface{}{name}, &count)
+	if err != nil || count == 0 {
+		return info, false, err
+	}
+	err = s.QueryRow(ctx, `
+		SELECT pg_get_userbyid(d.datdba), pg_encoding_to_char(d.encoding), d.datcollate, d.datctype, ts.spcname
+		FROM pg_database d
+		JOIN pg_tablespace ts ON ts.oid = d.dattablespace
+		WHERE d.datname = $1
+	`, []interface{}{name}, &info.Owner, &info.Encoding, &info.Collate, &info.Ctype, &info.TableSpace)
+	return info, err == nil, err
+}
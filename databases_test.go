@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDatabaseCRUD(t *testing.T) {
+	a := Assert(t)
+	RequireAPIEnv(t)
+	Authorize(t)
+
+	ctx := context.Background()
+	suffix := RandomSuffix()
+	clusterId := ProvisionCluster(t, DefaultClusterRequest(t, "databases-"+suffix))
+	defaultTableSpaceId := GetDefaultTableSpaceID(t, clusterId)
+
+	// Служебная база, через которую читаем pg_database
+	adminDB := "adminDB"
+	CreateDatabase(t, clusterId, CreateDBRequest{Name: adminDB, TableSpaceID: defaultTableSpaceId})
+
+	firstOwner, secondOwner := "owner1_"+suffix, "owner2_"+suffix
+	firstPassword := RandomPassword()
+	CreateClusterUser(t, clusterId, CreateClusterUserRequest{
+		Databases: []string{adminDB},
+		Roles:     []string{"pg_read_all_data"},
+		Name:      firstOwner,
+		Password:  firstPassword,
+	})
+	CreateClusterUser(t, clusterId, CreateClusterUserRequest{
+		Databases: []string{adminDB},
+		Roles:     []string{"pg_read_all_data"},
+		Name:      secondOwner,
+		Password:  RandomPassword(),
+	})
+
+	catalog := NewDBSession(t, ctx, ConnectionString(t, clusterId, adminDB, firstOwner, firstPassword), singleConnPoolConfig())
+
+	// Создание с владельцем, кодировкой и локалью
+	dbName := "crudDB"
+	dbId := CreateDatabase(t, clusterId, CreateDBRequest{
+		Name:         dbName,
+		TableSpaceID: defaultTableSpaceId,
+		Owner:        firstOwner,
+		Encoding:     "UTF8",
+		LcCollate:    "C",
+		LcCtype:      "C",
+	})
+
+	// Чтение списка и одной базы
+	_, found := FindDatabase(t, clusterId, dbName)
+	a.True(found, "База данных отсутствует в списке баз кластера")
+	db := GetDatabase(t, clusterId, dbId)
+	a.Equal(dbName, db.Name)
+	a.Equal(firstOwner, db.Owner)
+
+	info, found, err := QueryPgDatabase(ctx, catalog, dbName)
+	a.NoError(err, "не удалось прочитать pg_database")
+	a.True(found, "база данных отсутствует в pg_database")
+	a.Equal(firstOwner, info.Owner, "владелец в pg_database не совпадает")
+	a.Equal("UTF8", info.Encoding, "кодировка в pg_database не совпадает")
+	a.Equal("C", info.Collate, "datcollate в pg_database не совпадает")
+	a.Equal("C", info.Ctype, "datctype в pg_database не совпадает")
+
+	// Смена владельца
+	UpdateDatabase(t, clusterId, dbId, UpdateDBRequest{Owner: secondOwner})
+	a.Equal(secondOwner, GetDatabase(t, clusterId, dbId).Owner)
+	info, _, err = QueryPgDatabase(ctx, catalog, dbName)
+	a.NoError(err, "не удалось прочитать pg_database")
+	a.Equal(secondOwner, info.Owner, "смена владельца не отражена в pg_database")
+
+	// Перенос в отдельно созданный tablespace
+	targetName := "crud_ts_" + suffix
+	targetId := CreateTableSpace(t, clusterId, CreateTableSpaceRequest{Name: targetName})
+	UpdateDatabase(t, clusterId, dbId, UpdateDBRequest{TableSpaceID: targetId})
+	a.Equal(targetId, GetDatabase(t, clusterId, dbId).TableSpaceID)
+	info, _, err = QueryPgDatabase(ctx, catalog, dbName)
+	a.NoError(err, "не удалось прочитать pg_database")
+	a.Equal(targetName, info.TableSpace, "перенос tablespace не отражён в pg_database")
+
+	// Удаление
+	DeleteDatabase(t, clusterId, dbId)
+	_, found = FindDatabase(t, clusterId, dbName)
+	a.False(found, "Удалённая база данных осталась в списке баз кластера")
+	_, found, err = QueryPgDatabase(ctx, catalog, dbName)
+	a.NoError(err, "не удалось прочитать pg_database")
+	a.False(found, "удалённая база данных осталась в pg_database")
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+
+	"dbaas_testing_task/redact"
+)
+
+// PoolConfig содержит параметры пула соединений с базой данных.
+type PoolConfig struct {
+	MaxConns          int32
+	MinConns          int32
+	HealthCheckPeriod time.Duration
+	StatementTimeout  time.Duration
+}
+
+// LoadPoolConfig считывает параметры пула из переменных окружения, подставляя значения по умолчанию
+func LoadPoolConfig(t *testing.T) PoolConfig {
+	cfg := PoolConfig{
+		MaxConns:          10,
+		MinConns:          2,
+		HealthCheckPeriod: 30 * time.Second,
+		StatementTimeout:  30 * time.Second,
+	}
+	if v := os.Getenv("DB_POOL_MAX_CONNS"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 1 {
+			Fatalf(t, "Некорректное значение DB_POOL_MAX_CONNS: %q", v)
+		}
+		cfg.MaxConns = int32(n)
+	}
+	if v := os.Getenv("DB_POOL_MIN_CONNS"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 32)
+		if err != nil || n < 0 {
+			Fatalf(t, "Некорректное значение DB_POOL_MIN_CONNS: %q", v)
+		}
+		cfg.MinConns = int32(n)
+	}
+	if v := os.Getenv("DB_POOL_HEALTH_CHECK_PERIOD"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			Fatalf(t, "Некорректное значение DB_POOL_HEALTH_CHECK_PERIOD: %q", v)
+		}
+		cfg.HealthCheckPeriod = d
+	}
+	if v := os.Getenv("DB_STATEMENT_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			Fatalf(t, "Некорректное значение DB_STATEMENT_TIMEOUT: %q", v)
+		}
+		cfg.StatementTimeout = d
+	}
+	if cfg.MinConns > cfg.MaxConns {
+		cfg.MinConns = cfg.MaxConns
+	}
+	return cfg
+}
+
+// singleConnPoolConfig возвращает параметры пула из одного соединения для проверочных запросов
+// от имени отдельного пользователя
+func singleConnPoolConfig() PoolConfig {
+	return PoolConfig{MaxConns: 1, StatementTimeout: 30 * time.Second, HealthCheckPeriod: time.Minute}
+}
+
+// DBSession представляет пул соединений с базой данных с ограничением времени выполнения каждого запроса.
+type DBSession struct {
+	pool    *pgxpool.Pool
+	timeout time.Duration
+}
+
+// NewDBSession открывает пул соединений по строке подключения и проверяет его доступность.
+// Пул закрывается автоматически по завершении теста
+func NewDBSession(t *testing.T, ctx context.Context, conString string, cfg PoolConfig) *DBSession {
+	skipDatabaseOnReplay(t)
+	session, err := OpenDBSession(ctx, conString, cfg)
+	if err != nil {
+		Fatalf(t, "Не удалось подключиться к базе данных: %v", err)
+	}
+	t.Cleanup(session.Close)
+	Logf(t, "Connection pool opened (max %d, min %d connections)", cfg.MaxConns, cfg.MinConns)
+	return session
+}
+
+// OpenDBSession открывает пул соединений и проверяет его доступность, не прерывая тест при ошибке.
+// Используется там, где отказ в подключении является ожидаемым результатом
+func OpenDBSession(ctx context.Context, conString string, cfg PoolConfig) (*DBSession, error) {
+	poolConfig, err := pgxpool.ParseConfig(conString)
+	if err != nil {
+		// Ошибка разбора может содержать строку подключения вместе с паролем
+		return nil, fmt.Errorf("ошибка при разборе строки подключения: %w", redact.Error(err))
+	}
+	poolConfig.MaxConns = cfg.MaxConns
+	poolConfig.MinConns = cfg.MinConns
+	poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	// Ограничиваем время выполнения запросов и на стороне сервера
+	if cfg.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	pool, err := pgxpool.ConnectConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, redact.Error(err)
+	}
+
+	session := &DBSession{pool: pool, timeout: cfg.StatementTimeout}
+	if err := session.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, err
+	}
+	return session, nil
+}
+
+// Close закрывает все соединения пула
+func (s *DBSession) Close() {
+	s.pool.Close()
+}
+
+// withTimeout возвращает контекст, ограниченный таймаутом одного запроса
+func (s *DBSession) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+// Pool возвращает исходный пул соединений
+func (s *DBSession) Pool() *pgxpool.Pool {
+	return s.pool
+}
+
+// Ping проверяет, что пул может получить рабочее соединение
+func (s *DBSession) Ping(ctx context.Context) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	return s.pool.Ping(ctx)
+}
+
+// WaitReady повторяет Ping, пока база данных не станет доступна, например после перезапуска кластера
+func (s *DBSession) WaitReady(ctx context.Context, attempts int, interval time.Duration) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = s.Ping(ctx); err == nil {
+			return nil
+		}
+		time.Sleep(interval)
+	}
+	return err
+}
+
+// Exec выполняет запрос, не возвращающий строк
+func (s *DBSession) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	return s.pool.Exec(ctx, sql, args...)
+}
+
+// QueryRow выполняет запрос и считывает единственную строку результата в dest
+func (s *DBSession) QueryRow(ctx context.Context, sql string, args []interface{}, dest ...interface{}) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	return s.pool.QueryRow(ctx, sql, args...).Scan(dest...)
+}
+
+// Query выполняет запрос и передаёт каждую строку результата в fn
+func (s *DBSession) Query(ctx context.Context, sql string, args []interface{}, fn func(pgx.Rows) error) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Stat возвращает краткую статистику пула для логов
+func (s *DBSession) Stat() string {
+	st := s.pool.Stat()
+	return fmt.Sprintf("total=%d idle=%d acquired=%d acquire_count=%d",
+		st.TotalConns(), st.IdleConns(), st.AcquiredConns(), st.AcquireCount())
+}
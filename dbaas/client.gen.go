@@ -0,0 +1,270 @@
+// Code generated by apigen from api/openapi.yaml. DO NOT EDIT.
+
+package dbaas
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Authorize выдаёт токен по логину и паролю
+// (POST /api/authorize)
+func (c *Client) Authorize(ctx context.Context, body AuthRequest) (AuthResponse, error) {
+	var result AuthResponse
+	err := c.do(ctx, "POST", "/api/authorize", body, &result, http.StatusOK)
+	return result, err
+}
+
+// ListFlavors возвращает каталог flavor
+// (GET /api/flavors)
+func (c *Client) ListFlavors(ctx context.Context) ([]Flavor, error) {
+	var result []Flavor
+	err := c.do(ctx, "GET", "/api/flavors", nil, &result, http.StatusOK)
+	return result, err
+}
+
+// ListTypes возвращает каталог типов кластеров
+// (GET /api/types)
+func (c *Client) ListTypes(ctx context.Context) ([]ClusterType, error) {
+	var result []ClusterType
+	err := c.do(ctx, "GET", "/api/types", nil, &result, http.StatusOK)
+	return result, err
+}
+
+// ListClusters возвращает кластеры арендатора
+// (GET /api/clusters)
+func (c *Client) ListClusters(ctx context.Context) ([]Cluster, error) {
+	var result []Cluster
+	err := c.do(ctx, "GET", "/api/clusters", nil, &result, http.StatusOK)
+	return result, err
+}
+
+// CreateCluster начинает создание кластера
+// (POST /api/clusters)
+func (c *Client) CreateCluster(ctx context.Context, body CreateClusterRequest) (CreateClusterResponse, error) {
+	var result CreateClusterResponse
+	err := c.do(ctx, "POST", "/api/clusters", body, &result, http.StatusCreated)
+	return result, err
+}
+
+// GetCluster возвращает кластер
+// (GET /api/clusters/{cluster_id})
+func (c *Client) GetCluster(ctx context.Context, clusterID string) (Cluster, error) {
+	var result Cluster
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/clusters/%s", url.PathEscape(clusterID)), nil, &result, http.StatusOK)
+	return result, err
+}
+
+// UpdateCluster изменяет flavor, диск, число реплик или опции кластера
+// (PATCH /api/clusters/{cluster_id})
+func (c *Client) UpdateCluster(ctx context.Context, clusterID string, body UpdateClusterRequest) (Cluster, error) {
+	var result Cluster
+	err := c.do(ctx, "PATCH", fmt.Sprintf("/api/clusters/%s", url.PathEscape(clusterID)), body, &result, http.StatusOK, http.StatusAccepted)
+	return result, err
+}
+
+// DeleteCluster удаляет кластер
+// (DELETE /api/clusters/{cluster_id})
+func (c *Client) DeleteCluster(ctx context.Context, clusterID string) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/clusters/%s", url.PathEscape(clusterID)), nil, nil, http.StatusNoContent)
+}
+
+// RestartCluster перезапускает кластер
+// (POST /api/clusters/{cluster_id}/restart)
+func (c *Client) RestartCluster(ctx context.Context, clusterID string) (Cluster, error) {
+	var result Cluster
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/clusters/%s/restart", url.PathEscape(clusterID)), nil, &result, http.StatusOK, http.StatusAccepted)
+	return result, err
+}
+
+// ListClusterParameters возвращает параметры Postgres кластера
+// (GET /api/clusters/{cluster_id}/parameters)
+func (c *Client) ListClusterParameters(ctx context.Context, clusterID string) ([]ClusterParameter, error) {
+	var result []ClusterParameter
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/clusters/%s/parameters", url.PathEscape(clusterID)), nil, &result, http.StatusOK)
+	return result, err
+}
+
+// UpdateClusterParameters изменяет параметры Postgres кластера
+// (PATCH /api/clusters/{cluster_id}/parameters)
+func (c *Client) UpdateClusterParameters(ctx context.Context, clusterID string, body UpdateClusterParametersRequest) ([]ClusterParameter, error) {
+	var result []ClusterParameter
+	err := c.do(ctx, "PATCH", fmt.Sprintf("/api/clusters/%s/parameters", url.PathEscape(clusterID)), body, &result, http.StatusOK, http.StatusAccepted)
+	return result, err
+}
+
+// ListTableSpaces возвращает tablespace кластера
+// (GET /api/clusters/{cluster_id}/tablespaces)
+func (c *Client) ListTableSpaces(ctx context.Context, clusterID string) ([]TableSpaceResponse, error) {
+	var result []TableSpaceResponse
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/clusters/%s/tablespaces", url.PathEscape(clusterID)), nil, &result, http.StatusOK)
+	return result, err
+}
+
+// CreateTableSpace начинает создание tablespace
+// (POST /api/clusters/{cluster_id}/tablespaces)
+func (c *Client) CreateTableSpace(ctx context.Context, clusterID string, body CreateTableSpaceRequest) (TableSpaceResponse, error) {
+	var result TableSpaceResponse
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/clusters/%s/tablespaces", url.PathEscape(clusterID)), body, &result, http.StatusCreated)
+	return result, err
+}
+
+// GetTableSpace возвращает tablespace
+// (GET /api/clusters/{cluster_id}/tablespaces/{tablespace_id})
+func (c *Client) GetTableSpace(ctx context.Context, clusterID string, tablespaceID string) (TableSpaceResponse, error) {
+	var result TableSpaceResponse
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/clusters/%s/tablespaces/%s", url.PathEscape(clusterID), url.PathEscape(tablespaceID)), nil, &result, http.StatusOK)
+	return result, err
+}
+
+// DeleteTableSpace удаляет tablespace
+// (DELETE /api/clusters/{cluster_id}/tablespaces/{tablespace_id})
+func (c *Client) DeleteTableSpace(ctx context.Context, clusterID string, tablespaceID string) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/clusters/%s/tablespaces/%s", url.PathEscape(clusterID), url.PathEscape(tablespaceID)), nil, nil, http.StatusNoContent)
+}
+
+// ListDatabases возвращает базы данных кластера
+// (GET /api/clusters/{cluster_id}/databases)
+func (c *Client) ListDatabases(ctx context.Context, clusterID string) ([]Database, error) {
+	var result []Database
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/clusters/%s/databases", url.PathEscape(clusterID)), nil, &result, http.StatusOK)
+	return result, err
+}
+
+// CreateDatabase начинает создание базы данных
+// (POST /api/clusters/{cluster_id}/databases)
+func (c *Client) CreateDatabase(ctx context.Context, clusterID string, body CreateDBRequest) (CreateDBResponse, error) {
+	var result CreateDBResponse
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/clusters/%s/databases", url.PathEscape(clusterID)), body, &result, http.StatusCreated)
+	return result, err
+}
+
+// GetDatabase возвращает базу данных
+// (GET /api/clusters/{cluster_id}/databases/{database_id})
+func (c *Client) GetDatabase(ctx context.Context, clusterID string, databaseID string) (Database, error) {
+	var result Database
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/clusters/%s/databases/%s", url.PathEscape(clusterID), url.PathEscape(databaseID)), nil, &result, http.StatusOK)
+	return result, err
+}
+
+// UpdateDatabase изменяет владельца или tablespace базы данных
+// (PATCH /api/clusters/{cluster_id}/databases/{database_id})
+func (c *Client) UpdateDatabase(ctx context.Context, clusterID string, databaseID string, body UpdateDBRequest) (Database, error) {
+	var result Database
+	err := c.do(ctx, "PATCH", fmt.Sprintf("/api/clusters/%s/databases/%s", url.PathEscape(clusterID), url.PathEscape(databaseID)), body, &result, http.StatusOK, http.StatusAccepted)
+	return result, err
+}
+
+// DeleteDatabase удаляет базу данных
+// (DELETE /api/clusters/{cluster_id}/databases/{database_id})
+func (c *Client) DeleteDatabase(ctx context.Context, clusterID string, databaseID string) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/clusters/%s/databases/%s", url.PathEscape(clusterID), url.PathEscape(databaseID)), nil, nil, http.StatusNoContent)
+}
+
+// ListDumps возвращает дампы базы данных
+// (GET /api/clusters/{cluster_id}/databases/{database_id}/dumps)
+func (c *Client) ListDumps(ctx context.Context, clusterID string, databaseID string) ([]Dump, error) {
+	var result []Dump
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/clusters/%s/databases/%s/dumps", url.PathEscape(clusterID), url.PathEscape(databaseID)), nil, &result, http.StatusOK)
+	return result, err
+}
+
+// CreateDump начинает создание дампа базы данных
+// (POST /api/clusters/{cluster_id}/databases/{database_id}/dumps)
+func (c *Client) CreateDump(ctx context.Context, clusterID string, databaseID string, body CreateDumpRequest) (Dump, error) {
+	var result Dump
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/clusters/%s/databases/%s/dumps", url.PathEscape(clusterID), url.PathEscape(databaseID)), body, &result, http.StatusCreated)
+	return result, err
+}
+
+// RestoreDump восстанавливает базу данных из дампа
+// (POST /api/clusters/{cluster_id}/databases/{database_id}/dump_restore)
+func (c *Client) RestoreDump(ctx context.Context, clusterID string, databaseID string, body RestoreDumpRequest) (Dump, error) {
+	var result Dump
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/clusters/%s/databases/%s/dump_restore", url.PathEscape(clusterID), url.PathEscape(databaseID)), body, &result, http.StatusOK, http.StatusCreated, http.StatusAccepted)
+	return result, err
+}
+
+// ListClusterUsers возвращает пользователей кластера
+// (GET /api/clusters/{cluster_id}/users)
+func (c *Client) ListClusterUsers(ctx context.Context, clusterID string) ([]ClusterUser, error) {
+	var result []ClusterUser
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/clusters/%s/users", url.PathEscape(clusterID)), nil, &result, http.StatusOK)
+	return result, err
+}
+
+// CreateClusterUser начинает создание пользователя кластера
+// (POST /api/clusters/{cluster_id}/users)
+func (c *Client) CreateClusterUser(ctx context.Context, clusterID string, body CreateClusterUserRequest) (ClusterUser, error) {
+	var result ClusterUser
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/clusters/%s/users", url.PathEscape(clusterID)), body, &result, http.StatusCreated)
+	return result, err
+}
+
+// GetClusterUser возвращает пользователя кластера
+// (GET /api/clusters/{cluster_id}/users/{user_id})
+func (c *Client) GetClusterUser(ctx context.Context, clusterID string, userID string) (ClusterUser, error) {
+	var result ClusterUser
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/clusters/%s/users/%s", url.PathEscape(clusterID), url.PathEscape(userID)), nil, &result, http.StatusOK)
+	return result, err
+}
+
+// UpdateClusterUser изменяет пароль, роли или базы данных пользователя
+// (PATCH /api/clusters/{cluster_id}/users/{user_id})
+func (c *Client) UpdateClusterUser(ctx context.Context, clusterID string, userID string, body UpdateClusterUserRequest) (ClusterUser, error) {
+	var result ClusterUser
+	err := c.do(ctx, "PATCH", fmt.Sprintf("/api/clusters/%s/users/%s", url.PathEscape(clusterID), url.PathEscape(userID)), body, &result, http.StatusOK, http.StatusAccepted)
+	return result, err
+}
+
+// DeleteClusterUser удаляет пользователя кластера
+// (DELETE /api/clusters/{cluster_id}/users/{user_id})
+func (c *Client) DeleteClusterUser(ctx context.Context, clusterID string, userID string) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/clusters/%s/users/%s", url.PathEscape(clusterID), url.PathEscape(userID)), nil, nil, http.StatusNoContent)
+}
+
+// ListBackups возвращает резервные копии кластера
+// (GET /api/clusters/{cluster_id}/backups)
+func (c *Client) ListBackups(ctx context.Context, clusterID string) ([]Backup, error) {
+	var result []Backup
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/clusters/%s/backups", url.PathEscape(clusterID)), nil, &result, http.StatusOK)
+	return result, err
+}
+
+// CreateBackup начинает создание резервной копии кластера
+// (POST /api/clusters/{cluster_id}/backups)
+func (c *Client) CreateBackup(ctx context.Context, clusterID string) (Backup, error) {
+	var result Backup
+	err := c.do(ctx, "POST", fmt.Sprintf("/api/clusters/%s/backups", url.PathEscape(clusterID)), nil, &result, http.StatusCreated)
+	return result, err
+}
+
+// GetBackup возвращает резервную копию
+// (GET /api/backups/{backup_id})
+func (c *Client) GetBackup(ctx context.Context, backupID string) (Backup, error) {
+	var result Backup
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/backups/%s", url.PathEscape(backupID)), nil, &result, http.StatusOK)
+	return result, err
+}
+
+// DeleteBackup удаляет резервную копию
+// (DELETE /api/backups/{backup_id})
+func (c *Client) DeleteBackup(ctx context.Context, backupID string) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/backups/%s", url.PathEscape(backupID)), nil, nil, http.StatusNoContent)
+}
+
+// GetDump возвращает дамп
+// (GET /api/dumps/{dump_id})
+func (c *Client) GetDump(ctx context.Context, dumpID string) (Dump, error) {
+	var result Dump
+	err := c.do(ctx, "GET", fmt.Sprintf("/api/dumps/%s", url.PathEscape(dumpID)), nil, &result, http.StatusOK)
+	return result, err
+}
+
+// DeleteDump удаляет дамп
+// (DELETE /api/dumps/{dump_id})
+func (c *Client) DeleteDump(ctx context.Context, dumpID string) error {
+	return c.do(ctx, "DELETE", fmt.Sprintf("/api/dumps/%s", url.PathEscape(dumpID)), nil, nil, http.StatusNoContent)
+}
@@ -0,0 +1,137 @@
+// Package dbaas содержит модели и клиент API DBaaS, сгенерированные по спецификации api/openapi.yaml.
+//
+// Модели и методы клиента находятся в файлах *.gen.go и обновляются командой
+//
+//	go generate ./dbaas
+package dbaas
+
+//go:generate go run ../cmd/apigen -spec ../api/openapi.yaml -out .
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// defaultTimeout ограничивает время одного запроса, если HTTPClient не задан
+const defaultTimeout = 60 * time.Second
+
+// Client выполняет запросы к API DBaaS. Token передаётся в заголовке Authorization после Login
+type Client struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// NewClient создаёт клиент для API по адресу baseURL
+func NewClient(baseURL string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: defaultTimeout},
+	}
+}
+
+// Login получает токен по логину и паролю и сохраняет его в клиенте
+func (c *Client) Login(ctx context.Context, login, password string) error {
+	response, err := c.Authorize(ctx, AuthRequest{Login: login, Password: password})
+	if err != nil {
+		return err
+	}
+	if response.RefreshToken == "" {
+		return errors.New("в ответе API нет refresh_token")
+	}
+	c.Token = response.RefreshToken
+	return nil
+}
+
+// StatusError возвращается, если API ответил неожиданным кодом
+type StatusError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	// APIError содержит разобранное тело ошибки, если оно соответствует спецификации
+	APIError APIError
+	Body     string
+}
+
+func (e *StatusError) Error() string {
+	message := e.APIError.Message
+	if message == "" {
+		message = e.Body
+	}
+	if e.APIError.Field != "" {
+		message = fmt.Sprintf("%s (поле %s)", message, e.APIError.Field)
+	}
+	return fmt.Sprintf("%s %s: статус %d: %s", e.Method, e.Path, e.StatusCode, message)
+}
+
+// StatusCode возвращает код ответа API из ошибки или 0, если ошибка не связана с ответом API
+func StatusCode(err error) int {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound проверяет, что API ответил 404 или ресурс не найден при поиске
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || StatusCode(err) == http.StatusNotFound
+}
+
+// do отправляет запрос с телом body и разбирает ответ в out, если код ответа входит в expected
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}, expected ...int) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("не удалось сериализовать тело запроса: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s %s: не удалось прочитать ответ: %w", method, path, err)
+	}
+
+	for _, status := range expected {
+		if resp.StatusCode != status {
+			continue
+		}
+		if out == nil || len(data) == 0 {
+			return nil
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("%s %s: не удалось разобрать ответ: %w", method, path, err)
+		}
+		return nil
+	}
+
+	statusErr := &StatusError{Method: method, Path: path, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	_ = json.Unmarshal(data, &statusErr.APIError)
+	return statusErr
+}
@@ -0,0 +1,51 @@
+package dbaas
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/authorize":
+			var request AuthRequest
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			assert.Equal(t, AuthRequest{Login: "login", Password: "password"}, request)
+			_ = json.NewEncoder(w).Encode(AuthResponse{RefreshToken: "token"})
+		case "GET /api/clusters/c1":
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			_ = json.NewEncoder(w).Encode(Cluster{Id: "c1", Status: "OK"})
+		case "DELETE /api/clusters/c1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(APIError{Code: "not_found", Message: "cluster not found", Field: "cluster_id"})
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient(server.URL + "/")
+	assert.NoError(t, client.Login(ctx, "login", "password"))
+	assert.Equal(t, "token", client.Token)
+
+	cluster, err := client.GetCluster(ctx, "c1")
+	assert.NoError(t, err)
+	assert.Equal(t, "OK", cluster.Status)
+	assert.NoError(t, client.DeleteCluster(ctx, "c1"))
+
+	_, err = client.GetCluster(ctx, "c2")
+	assert.True(t, IsNotFound(err), "ожидалась ошибка 404, получено: %v", err)
+	var statusErr *StatusError
+	if assert.ErrorAs(t, err, &statusErr) {
+		assert.Equal(t, "cluster not found", statusErr.APIError.Message)
+		assert.Equal(t, "cluster_id", statusErr.APIError.Field)
+	}
+	assert.Equal(t, 0, StatusCode(nil))
+}
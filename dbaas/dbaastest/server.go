@@ -0,0 +1,460 @@
+// Package dbaastest содержит хранящую состояние в памяти имитацию API DBaaS для тестов
+// пакетов, работающих через клиент dbaas.
+package dbaastest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"dbaas_testing_task/dbaas"
+)
+
+// Token - токен, который выдаёт имитация на любые учётные данные
+const Token = "test-token"
+
+// Server имитирует API: ресурсы создаются сразу в статусе OK
+type Server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	nextID     int
+	clusters   map[string]*dbaas.Cluster
+	parameters map[string]map[string]string
+	databases  map[string]map[string]*dbaas.Database
+	users      map[string]map[string]*dbaas.ClusterUser
+	// passwords хранит пароли пользователей по ID
+	passwords map[string]string
+	dumps     map[string]*dbaas.Dump
+	// Requests содержит выполненные запросы вида "POST /api/clusters"
+	Requests []string
+}
+
+// Flavors и Types образуют каталоги имитации
+var (
+	Flavors = []dbaas.Flavor{
+		{Id: "flavor-1", Name: dbaas.DefaultFlavor, Vcpus: 1, Ram: 1024},
+		{Id: "flavor-2", Name: "STD3-2-4", Vcpus: 2, Ram: 4096},
+	}
+	Types = []dbaas.ClusterType{{Id: "type-1", Name: dbaas.DefaultTypeName, Version: dbaas.DefaultTypeVersion}}
+	// TableSpace - единственный tablespace каждого кластера
+	TableSpace = dbaas.TableSpaceResponse{Id: "ts-default", Name: "pg_default", Default: true, Status: dbaas.StatusOK}
+)
+
+// NewServer запускает имитацию; она останавливается по завершении теста
+func NewServer(t interface{ Cleanup(func()) }) *Server {
+	s := &Server{
+		clusters:   make(map[string]*dbaas.Cluster),
+		parameters: make(map[string]map[string]string),
+		databases:  make(map[string]map[string]*dbaas.Database),
+		users:      make(map[string]map[string]*dbaas.ClusterUser),
+		passwords:  make(map[string]string),
+		dumps:      make(map[string]*dbaas.Dump),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/authorize", func(w http.ResponseWriter, r *http.Request) {
+		reply(w, http.StatusOK, dbaas.AuthResponse{RefreshToken: Token})
+	})
+	mux.HandleFunc("GET /api/flavors", func(w http.ResponseWriter, r *http.Request) { reply(w, http.StatusOK, Flavors) })
+	mux.HandleFunc("GET /api/types", func(w http.ResponseWriter, r *http.Request) { reply(w, http.StatusOK, Types) })
+	mux.HandleFunc("GET /api/clusters", s.listClusters)
+	mux.HandleFunc("POST /api/clusters", s.createCluster)
+	mux.HandleFunc("GET /api/clusters/{cluster_id}", s.withCluster(func(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster) {
+		reply(w, http.StatusOK, c)
+	}))
+	mux.HandleFunc("PATCH /api/clusters/{cluster_id}", s.withCluster(s.updateCluster))
+	mux.HandleFunc("DELETE /api/clusters/{cluster_id}", s.withCluster(s.deleteCluster))
+	mux.HandleFunc("GET /api/clusters/{cluster_id}/parameters", s.withCluster(s.listParameters))
+	mux.HandleFunc("PATCH /api/clusters/{cluster_id}/parameters", s.withCluster(s.updateParameters))
+	mux.HandleFunc("GET /api/clusters/{cluster_id}/tablespaces", s.withCluster(func(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster) {
+		reply(w, http.StatusOK, []dbaas.TableSpaceResponse{TableSpace})
+	}))
+	mux.HandleFunc("GET /api/clusters/{cluster_id}/databases", s.withCluster(s.listDatabases))
+	mux.HandleFunc("POST /api/clusters/{cluster_id}/databases", s.withCluster(s.createDatabase))
+	mux.HandleFunc("GET /api/clusters/{cluster_id}/databases/{database_id}", s.withDatabase(func(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster, d *dbaas.Database) {
+		reply(w, http.StatusOK, d)
+	}))
+	mux.HandleFunc("PATCH /api/clusters/{cluster_id}/databases/{database_id}", s.withDatabase(s.updateDatabase))
+	mux.HandleFunc("DELETE /api/clusters/{cluster_id}/databases/{database_id}", s.withDatabase(s.deleteDatabase))
+	mux.HandleFunc("GET /api/clusters/{cluster_id}/databases/{database_id}/dumps", s.withDatabase(s.listDumps))
+	mux.HandleFunc("POST /api/clusters/{cluster_id}/databases/{database_id}/dumps", s.withDatabase(s.createDump))
+	mux.HandleFunc("POST /api/clusters/{cluster_id}/databases/{database_id}/dump_restore", s.withDatabase(s.restoreDump))
+	mux.HandleFunc("GET /api/dumps/{dump_id}", s.withDump(func(w http.ResponseWriter, r *http.Request, d *dbaas.Dump) {
+		reply(w, http.StatusOK, d)
+	}))
+	mux.HandleFunc("DELETE /api/dumps/{dump_id}", s.withDump(func(w http.ResponseWriter, r *http.Request, d *dbaas.Dump) {
+		delete(s.dumps, d.Id)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	mux.HandleFunc("GET /api/clusters/{cluster_id}/users", s.withCluster(s.listUsers))
+	mux.HandleFunc("POST /api/clusters/{cluster_id}/users", s.withCluster(s.createUser))
+	mux.HandleFunc("GET /api/clusters/{cluster_id}/users/{user_id}", s.withUser(func(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster, u *dbaas.ClusterUser) {
+		reply(w, http.StatusOK, u)
+	}))
+	mux.HandleFunc("PATCH /api/clusters/{cluster_id}/users/{user_id}", s.withUser(s.updateUser))
+	mux.HandleFunc("DELETE /api/clusters/{cluster_id}/users/{user_id}", s.withUser(func(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster, u *dbaas.ClusterUser) {
+		delete(s.users[c.Id], u.Id)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.Requests = append(s.Requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path != "/api/authorize" && r.Header.Get("Authorization") != "Bearer "+Token {
+			reply(w, http.StatusUnauthorized, dbaas.APIError{Code: "unauthorized", Message: "invalid token"})
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Client возвращает авторизованный клиент имитации
+func (s *Server) Client() *dbaas.Client {
+	client := dbaas.NewClient(s.URL)
+	client.Token = Token
+	return client
+}
+
+// Cluster возвращает копию кластера по имени. Второе значение равно false, если кластера нет
+func (s *Server) Cluster(name string) (dbaas.Cluster, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.clusters {
+		if c.Name == name {
+			return *c, true
+		}
+	}
+	return dbaas.Cluster{}, false
+}
+
+// Databases возвращает базы данных кластера
+func (s *Server) Databases(clusterID string) []dbaas.Database {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var databases []dbaas.Database
+	for _, d := range s.databases[clusterID] {
+		databases = append(databases, *d)
+	}
+	return databases
+}
+
+// Users возвращает пользователей кластера
+func (s *Server) Users(clusterID string) []dbaas.ClusterUser {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var users []dbaas.ClusterUser
+	for _, u := range s.users[clusterID] {
+		users = append(users, *u)
+	}
+	return users
+}
+
+// Password возвращает пароль пользователя кластера по имени, заданный при создании или изменении
+func (s *Server) Password(clusterID, name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users[clusterID] {
+		if u.Name == name {
+			return s.passwords[u.Id]
+		}
+	}
+	return ""
+}
+
+// Parameters возвращает параметры Postgres кластера
+func (s *Server) Parameters(clusterID string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	parameters := make(map[string]string)
+	for name, value := range s.parameters[clusterID] {
+		parameters[name] = value
+	}
+	return parameters
+}
+
+// ResetRequests очищает список выполненных запросов
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Requests = nil
+}
+
+func (s *Server) id(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%d", prefix, s.nextID)
+}
+
+func reply(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func notFound(w http.ResponseWriter, what string) {
+	reply(w, http.StatusNotFound, dbaas.APIError{Code: "not_found", Message: what + " not found"})
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		reply(w, http.StatusBadRequest, dbaas.APIError{Code: "bad_request", Message: err.Error()})
+		return false
+	}
+	return true
+}
+
+func (s *Server) withCluster(h func(http.ResponseWriter, *http.Request, *dbaas.Cluster)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c, ok := s.clusters[r.PathValue("cluster_id")]
+		if !ok {
+			notFound(w, "cluster")
+			return
+		}
+		h(w, r, c)
+	}
+}
+
+func (s *Server) withDatabase(h func(http.ResponseWriter, *http.Request, *dbaas.Cluster, *dbaas.Database)) http.HandlerFunc {
+	return s.withCluster(func(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster) {
+		d, ok := s.databases[c.Id][r.PathValue("database_id")]
+		if !ok {
+			notFound(w, "database")
+			return
+		}
+		h(w, r, c, d)
+	})
+}
+
+func (s *Server) withUser(h func(http.ResponseWriter, *http.Request, *dbaas.Cluster, *dbaas.ClusterUser)) http.HandlerFunc {
+	return s.withCluster(func(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster) {
+		u, ok := s.users[c.Id][r.PathValue("user_id")]
+		if !ok {
+			notFound(w, "user")
+			return
+		}
+		h(w, r, c, u)
+	})
+}
+
+func (s *Server) withDump(h func(http.ResponseWriter, *http.Request, *dbaas.Dump)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		d, ok := s.dumps[r.PathValue("dump_id")]
+		if !ok {
+			notFound(w, "dump")
+			return
+		}
+		h(w, r, d)
+	}
+}
+
+func (s *Server) listClusters(w http.ResponseWriter, r *http.Request) {
+	clusters := []dbaas.Cluster{}
+	for _, c := range s.clusters {
+		clusters = append(clusters, *c)
+	}
+	reply(w, http.StatusOK, clusters)
+}
+
+func (s *Server) createCluster(w http.ResponseWriter, r *http.Request) {
+	var request dbaas.CreateClusterRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	for _, c := range s.clusters {
+		if c.Name == request.Name {
+			reply(w, http.StatusConflict, dbaas.APIError{Code: "conflict", Message: "cluster name is taken", Field: "name"})
+			return
+		}
+	}
+	id := s.id("cluster")
+	s.clusters[id] = &dbaas.Cluster{
+		Id:            id,
+		Name:          request.Name,
+		Status:        dbaas.StatusOK,
+		FlavorID:      request.FlavorID,
+		DiskSize:      request.DiskSize,
+		ReplicasCount: request.ReplicasCount,
+		Options:       request.Options,
+	}
+	s.parameters[id] = map[string]string{"work_mem": "4MB", "max_connections": "100"}
+	s.databases[id] = make(map[string]*dbaas.Database)
+	s.users[id] = make(map[string]*dbaas.ClusterUser)
+	reply(w, http.StatusCreated, dbaas.CreateClusterResponse{Instances: []dbaas.Instance{{ClusterID: id}}})
+}
+
+func (s *Server) updateCluster(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster) {
+	var request dbaas.UpdateClusterRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if request.FlavorID != "" {
+		c.FlavorID = request.FlavorID
+	}
+	if request.DiskSize != 0 {
+		c.DiskSize = request.DiskSize
+	}
+	if request.ReplicasCount != nil {
+		c.ReplicasCount = *request.ReplicasCount
+	}
+	if request.Options != nil {
+		c.Options = *request.Options
+	}
+	reply(w, http.StatusOK, c)
+}
+
+func (s *Server) deleteCluster(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster) {
+	delete(s.clusters, c.Id)
+	delete(s.parameters, c.Id)
+	delete(s.databases, c.Id)
+	delete(s.users, c.Id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) parameterList(clusterID string) []dbaas.ClusterParameter {
+	parameters := []dbaas.ClusterParameter{}
+	for name, value := range s.parameters[clusterID] {
+		parameters = append(parameters, dbaas.ClusterParameter{Name: name, Value: value, RequiresRestart: name == "max_connections"})
+	}
+	return parameters
+}
+
+func (s *Server) listParameters(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster) {
+	reply(w, http.StatusOK, s.parameterList(c.Id))
+}
+
+func (s *Server) updateParameters(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster) {
+	var request dbaas.UpdateClusterParametersRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	for name, value := range request.Parameters {
+		s.parameters[c.Id][name] = value
+	}
+	reply(w, http.StatusOK, s.parameterList(c.Id))
+}
+
+func (s *Server) listDatabases(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster) {
+	databases := []dbaas.Database{}
+	for _, d := range s.databases[c.Id] {
+		databases = append(databases, *d)
+	}
+	reply(w, http.StatusOK, databases)
+}
+
+func (s *Server) createDatabase(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster) {
+	var request dbaas.CreateDBRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	id := s.id("db")
+	s.databases[c.Id][id] = &dbaas.Database{
+		Id:                     id,
+		Name:                   request.Name,
+		Owner:                  request.Owner,
+		TableSpaceID:           request.TableSpaceID,
+		Encoding:               request.Encoding,
+		LcCollate:              request.LcCollate,
+		LcCtype:                request.LcCtype,
+		Status:                 dbaas.StatusOK,
+		MasterConnectionString: fmt.Sprintf("postgres://<username>:<password>@%s.example:5432/%s", c.Id, request.Name),
+	}
+	reply(w, http.StatusCreated, dbaas.CreateDBResponse{Id: id})
+}
+
+func (s *Server) updateDatabase(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster, d *dbaas.Database) {
+	var request dbaas.UpdateDBRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if request.Owner != "" {
+		d.Owner = request.Owner
+	}
+	if request.TableSpaceID != "" {
+		d.TableSpaceID = request.TableSpaceID
+	}
+	reply(w, http.StatusOK, d)
+}
+
+func (s *Server) deleteDatabase(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster, d *dbaas.Database) {
+	delete(s.databases[c.Id], d.Id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listDumps(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster, d *dbaas.Database) {
+	dumps := []dbaas.Dump{}
+	for _, dump := range s.dumps {
+		if dump.DatabaseID == d.Id {
+			dumps = append(dumps, *dump)
+		}
+	}
+	reply(w, http.StatusOK, dumps)
+}
+
+func (s *Server) createDump(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster, d *dbaas.Database) {
+	var request dbaas.CreateDumpRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	id := s.id("dump")
+	s.dumps[id] = &dbaas.Dump{Id: id, Name: request.Name, ClusterID: c.Id, DatabaseID: d.Id, Status: dbaas.StatusOK}
+	reply(w, http.StatusCreated, s.dumps[id])
+}
+
+func (s *Server) restoreDump(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster, d *dbaas.Database) {
+	var request dbaas.RestoreDumpRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	dump, ok := s.dumps[request.DumpID]
+	if !ok {
+		notFound(w, "dump")
+		return
+	}
+	reply(w, http.StatusOK, dump)
+}
+
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster) {
+	users := []dbaas.ClusterUser{}
+	for _, u := range s.users[c.Id] {
+		users = append(users, *u)
+	}
+	reply(w, http.StatusOK, users)
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster) {
+	var request dbaas.CreateClusterUserRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	for _, u := range s.users[c.Id] {
+		if u.Name == request.Name {
+			reply(w, http.StatusConflict, dbaas.APIError{Code: "conflict", Message: "user exists", Field: "name"})
+			return
+		}
+	}
+	id := s.id("user")
+	s.users[c.Id][id] = &dbaas.ClusterUser{Id: id, Name: request.Name, Databases: request.Databases, Roles: request.Roles, Status: dbaas.StatusOK}
+	s.passwords[id] = request.Password
+	reply(w, http.StatusCreated, s.users[c.Id][id])
+}
+
+func (s *Server) updateUser(w http.ResponseWriter, r *http.Request, c *dbaas.Cluster, u *dbaas.ClusterUser) {
+	var request dbaas.UpdateClusterUserRequest
+	if !decode(w, r, &request) {
+		return
+	}
+	if request.Databases != nil {
+		u.Databases = *request.Databases
+	}
+	if request.Roles != nil {
+		u.Roles = *request.Roles
+	}
+	if request.Password != "" {
+		s.passwords[u.Id] = request.Password
+	}
+	reply(w, http.StatusOK, u)
+}
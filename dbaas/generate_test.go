@@ -0,0 +1,30 @@
+package dbaas
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"dbaas_testing_task/internal/apigen"
+)
+
+// TestGeneratedCodeUpToDate проверяет, что сгенерированные файлы соответствуют спецификации
+func TestGeneratedCodeUpToDate(t *testing.T) {
+	spec, err := os.ReadFile("../api/openapi.yaml")
+	if err != nil {
+		t.Fatalf("Не удалось прочитать спецификацию: %v", err)
+	}
+	files, err := apigen.Generate(spec)
+	if err != nil {
+		t.Fatalf("Не удалось сгенерировать код: %v", err)
+	}
+	for name, want := range files {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("Не удалось прочитать %s: %v", name, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s устарел, выполните go generate ./dbaas", name)
+		}
+	}
+}
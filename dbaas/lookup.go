@@ -0,0 +1,97 @@
+package dbaas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound возвращается, если ресурс не найден среди перечисленных API
+var ErrNotFound = errors.New("не найден")
+
+// Параметры кластера по умолчанию, используемые в тестах
+const (
+	DefaultFlavor      = "STD3-1-1"
+	DefaultTypeVersion = "17.2.2"
+	DefaultTypeName    = "Postgres Pro Enterprise"
+	DefaultDiskSize    = 3221225472
+	DefaultAz          = "GZ1"
+	DefaultHAManager   = "patroni"
+	DefaultMaxLag      = 1048576
+)
+
+// NewClusterRequest возвращает запрос на создание пустого кластера с параметрами по умолчанию
+func NewClusterRequest(name, flavorID, typeID string) CreateClusterRequest {
+	return CreateClusterRequest{
+		TypeID:        typeID,
+		Options:       Options{MaximumLagOnFailover: DefaultMaxLag},
+		DiskSize:      DefaultDiskSize,
+		Mode:          "create",
+		ReplicasCount: 1,
+		CreationMode:  "empty",
+		Name:          name,
+		FlavorID:      flavorID,
+		TypeName:      DefaultTypeName,
+		Az:            DefaultAz,
+		HAManager:     DefaultHAManager,
+	}
+}
+
+// FindFlavor ищет flavor по имени или ID
+func (c *Client) FindFlavor(ctx context.Context, nameOrID string) (Flavor, error) {
+	flavors, err := c.ListFlavors(ctx)
+	if err != nil {
+		return Flavor{}, err
+	}
+	for _, flavor := range flavors {
+		if flavor.Id == nameOrID || flavor.Name == nameOrID {
+			return flavor, nil
+		}
+	}
+	return Flavor{}, fmt.Errorf("flavor %s %w", nameOrID, ErrNotFound)
+}
+
+// FindType ищет тип кластера по версии или ID
+func (c *Client) FindType(ctx context.Context, versionOrID string) (ClusterType, error) {
+	types, err := c.ListTypes(ctx)
+	if err != nil {
+		return ClusterType{}, err
+	}
+	for _, typ := range types {
+		if typ.Id == versionOrID || typ.Version == versionOrID {
+			return typ, nil
+		}
+	}
+	return ClusterType{}, fmt.Errorf("тип кластера %s %w", versionOrID, ErrNotFound)
+}
+
+// FindCluster ищет кластер по имени
+func (c *Client) FindCluster(ctx context.Context, name string) (Cluster, error) {
+	clusters, err := c.ListClusters(ctx)
+	if err != nil {
+		return Cluster{}, err
+	}
+	for _, cluster := range clusters {
+		if cluster.Name == name {
+			return cluster, nil
+		}
+	}
+	return Cluster{}, fmt.Errorf("кластер %s %w", name, ErrNotFound)
+}
+
+// FindTableSpace ищет tablespace кластера по имени или ID; пустое значение выбирает tablespace с флагом default
+func (c *Client) FindTableSpace(ctx context.Context, clusterID, nameOrID string) (TableSpaceResponse, error) {
+	tableSpaces, err := c.ListTableSpaces(ctx, clusterID)
+	if err != nil {
+		return TableSpaceResponse{}, err
+	}
+	for _, tableSpace := range tableSpaces {
+		if nameOrID == "" && tableSpace.Default || nameOrID != "" && (tableSpace.Id == nameOrID || tableSpace.Name == nameOrID) {
+			return tableSpace, nil
+		}
+	}
+	if nameOrID == "" {
+		return TableSpaceResponse{}, fmt.Errorf("tablespace по умолчанию в кластере %s %w", clusterID, ErrNotFound)
+	}
+	return TableSpaceResponse{}, fmt.Errorf("tablespace %s %w", nameOrID, ErrNotFound)
+}
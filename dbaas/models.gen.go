@@ -0,0 +1,232 @@
+// Code generated by apigen from api/openapi.yaml. DO NOT EDIT.
+
+package dbaas
+
+import "time"
+
+// APIError представляет тело ответа API с ошибкой.
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Field указывает поле запроса, не прошедшее валидацию, если ошибка к нему относится
+	Field string `json:"field"`
+}
+
+// AuthRequest представляет запрос на получение токена.
+type AuthRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// AuthResponse представляет ответ аутентификации.
+type AuthResponse struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Flavor представляет конфигурацию ресурсов из каталога /api/flavors.
+type Flavor struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Vcpus int    `json:"vcpus"`
+	// Ram задаётся в мегабайтах
+	Ram int64 `json:"ram"`
+}
+
+// ClusterType представляет тип кластера из каталога /api/types.
+type ClusterType struct {
+	Id      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Options представляет параметры конфигурации кластера.
+type Options struct {
+	MaximumLagOnFailover  int  `json:"maximum_lag_on_failover"`
+	WalArchiveMode        bool `json:"wal_archive_mode"`
+	AutoRestart           bool `json:"auto_restart"`
+	Production            bool `json:"production"`
+	EnableSynchronousMode bool `json:"enable_synchronous_mode"`
+	DisableAutofailover   bool `json:"disable_autofailover"`
+}
+
+// RecoveryTarget представляет источник и точку восстановления кластера.
+// Для CreationMode "pitr" задаётся только одно из полей TargetTime и TargetLSN; если не задано ни одно,
+// кластер восстанавливается на последнее состояние. BackupID используется с "backup", DumpID - с "dump"
+type RecoveryTarget struct {
+	SourceClusterID string     `json:"source_cluster_id,omitempty"`
+	TargetTime      *time.Time `json:"target_time,omitempty"`
+	TargetLSN       string     `json:"target_lsn,omitempty"`
+	BackupID        string     `json:"backup_id,omitempty"`
+	DumpID          string     `json:"dump_id,omitempty"`
+}
+
+// CreateClusterRequest представляет запрос на создание кластера.
+type CreateClusterRequest struct {
+	TypeID        string  `json:"type_id"`
+	Options       Options `json:"options"`
+	DiskSize      int64   `json:"disk_size"`
+	Mode          string  `json:"mode"`
+	ReplicasCount int     `json:"replicas_count"`
+	CreationMode  string  `json:"creation_mode"`
+	Name          string  `json:"name"`
+	FlavorID      string  `json:"flavor_id"`
+	TypeName      string  `json:"type_name"`
+	Az            string  `json:"az"`
+	HAManager     string  `json:"ha_manager"`
+	HA            bool    `json:"ha"`
+	// Recovery задаётся для кластеров, восстанавливаемых из резервной копии (CreationMode отличен от "empty")
+	Recovery *RecoveryTarget `json:"recovery,omitempty"`
+}
+
+// Instance представляет экземпляр кластера.
+type Instance struct {
+	ClusterID string `json:"cluster_id"`
+}
+
+// CreateClusterResponse представляет ответ на запрос создания кластера.
+type CreateClusterResponse struct {
+	Instances []Instance `json:"instances"`
+}
+
+// Cluster представляет кластер.
+type Cluster struct {
+	Id            string  `json:"id"`
+	Name          string  `json:"name"`
+	Status        string  `json:"status"`
+	FlavorID      string  `json:"flavor_id"`
+	DiskSize      int64   `json:"disk_size"`
+	ReplicasCount int     `json:"replicas_count"`
+	Options       Options `json:"options"`
+	// PendingRestart равно true, если изменённые параметры ждут перезапуска кластера
+	PendingRestart bool `json:"pending_restart"`
+}
+
+// UpdateClusterRequest представляет запрос на изменение кластера.
+// Пустые поля не изменяются
+type UpdateClusterRequest struct {
+	FlavorID string `json:"flavor_id,omitempty"`
+	DiskSize int64  `json:"disk_size,omitempty"`
+	// ReplicasCount задан указателем, чтобы можно было явно передать 0
+	ReplicasCount *int     `json:"replicas_count,omitempty"`
+	Options       *Options `json:"options,omitempty"`
+}
+
+// ClusterParameter представляет параметр Postgres, управляемый через API.
+type ClusterParameter struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// RequiresRestart равно true для параметров, вступающих в силу только после перезапуска
+	RequiresRestart bool `json:"requires_restart"`
+}
+
+// UpdateClusterParametersRequest представляет запрос на изменение параметров Postgres.
+type UpdateClusterParametersRequest struct {
+	Parameters map[string]string `json:"parameters"`
+}
+
+// TableSpaceResponse представляет tablespace кластера.
+type TableSpaceResponse struct {
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Size     int64  `json:"size"`
+	Default  bool   `json:"default"`
+	Status   string `json:"status"`
+}
+
+// CreateTableSpaceRequest представляет запрос на создание tablespace.
+type CreateTableSpaceRequest struct {
+	Name     string `json:"name"`
+	Location string `json:"location,omitempty"`
+}
+
+// CreateDBRequest представляет запрос на создание базы данных.
+type CreateDBRequest struct {
+	Name         string `json:"name"`
+	TableSpaceID string `json:"tablespace_id"`
+	Owner        string `json:"owner,omitempty"`
+	Encoding     string `json:"encoding,omitempty"`
+	LcCollate    string `json:"lc_collate,omitempty"`
+	LcCtype      string `json:"lc_ctype,omitempty"`
+}
+
+// CreateDBResponse представляет ответ на запрос создания базы данных.
+type CreateDBResponse struct {
+	Id string `json:"id"`
+}
+
+// UpdateDBRequest представляет запрос на изменение базы данных.
+// Пустые поля не изменяются
+type UpdateDBRequest struct {
+	Owner        string `json:"owner,omitempty"`
+	TableSpaceID string `json:"tablespace_id,omitempty"`
+}
+
+// Database представляет базу данных кластера.
+type Database struct {
+	Id                     string `json:"id"`
+	Name                   string `json:"name"`
+	Owner                  string `json:"owner"`
+	TableSpaceID           string `json:"tablespace_id"`
+	Encoding               string `json:"encoding"`
+	LcCollate              string `json:"lc_collate"`
+	LcCtype                string `json:"lc_ctype"`
+	Status                 string `json:"status"`
+	MasterConnectionString string `json:"master_connection_string"`
+}
+
+// CreateClusterUserRequest представляет запрос на создание пользователя кластера.
+type CreateClusterUserRequest struct {
+	Databases []string `json:"databases"`
+	Roles     []string `json:"roles"`
+	Name      string   `json:"name"`
+	Password  string   `json:"password"`
+}
+
+// ClusterUser представляет пользователя кластера.
+type ClusterUser struct {
+	Id        string   `json:"id"`
+	Name      string   `json:"name"`
+	Databases []string `json:"databases"`
+	Roles     []string `json:"roles"`
+	Status    string   `json:"status"`
+}
+
+// UpdateClusterUserRequest представляет запрос на изменение пользователя кластера.
+// Пустые поля не изменяются
+type UpdateClusterUserRequest struct {
+	// Databases задан указателем, чтобы можно было передать пустой список и отозвать доступ ко всем базам данных
+	Databases *[]string `json:"databases,omitempty"`
+	// Roles задан указателем, чтобы можно было передать пустой список и отозвать все роли
+	Roles    *[]string `json:"roles,omitempty"`
+	Password string    `json:"password,omitempty"`
+}
+
+// CreateDumpRequest представляет запрос на создание дампа базы данных.
+type CreateDumpRequest struct {
+	Name string `json:"name"`
+}
+
+// Dump представляет логический дамп базы данных.
+type Dump struct {
+	Id         string `json:"id"`
+	Name       string `json:"name"`
+	ClusterID  string `json:"cluster_id"`
+	DatabaseID string `json:"database_id"`
+	Status     string `json:"status"`
+}
+
+// RestoreDumpRequest представляет запрос на восстановление базы данных из дампа.
+type RestoreDumpRequest struct {
+	DumpID       string `json:"dump_id"`
+	Mode         string `json:"mode"`
+	RestoreUsers bool   `json:"restore_users"`
+}
+
+// Backup представляет физическую резервную копию кластера.
+type Backup struct {
+	Id        string    `json:"id"`
+	ClusterID string    `json:"cluster_id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,101 @@
+package dbaas
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// StatusOK - статус ресурса, готового к работе
+	StatusOK = "OK"
+	// DefaultPollInterval задаёт интервал опроса статуса по умолчанию
+	DefaultPollInterval = 5 * time.Second
+)
+
+// WaitForStatus опрашивает status с интервалом interval, пока он не вернёт want.
+// Ошибка status прерывает ожидание; ожидание ограничено контекстом
+func WaitForStatus(ctx context.Context, interval time.Duration, want string, status func(context.Context) (string, error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := ""
+	for {
+		current, err := status(ctx)
+		if err != nil {
+			return err
+		}
+		if current == want {
+			return nil
+		}
+		last = current
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("статус %s не достигнут, последний статус %s: %w", want, last, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitCluster ждёт, пока кластер перейдёт в статус status, и возвращает его
+func (c *Client) WaitCluster(ctx context.Context, clusterID, status string, interval time.Duration) (Cluster, error) {
+	var cluster Cluster
+	err := WaitForStatus(ctx, interval, status, func(ctx context.Context) (string, error) {
+		var err error
+		cluster, err = c.GetCluster(ctx, clusterID)
+		return cluster.Status, err
+	})
+	return cluster, err
+}
+
+// WaitDatabase ждёт, пока база данных перейдёт в статус OK, и возвращает её
+func (c *Client) WaitDatabase(ctx context.Context, clusterID, databaseID string, interval time.Duration) (Database, error) {
+	var database Database
+	err := WaitForStatus(ctx, interval, StatusOK, func(ctx context.Context) (string, error) {
+		var err error
+		database, err = c.GetDatabase(ctx, clusterID, databaseID)
+		return database.Status, err
+	})
+	return database, err
+}
+
+// WaitClusterUser ждёт, пока пользователь кластера перейдёт в статус OK, и возвращает его
+func (c *Client) WaitClusterUser(ctx context.Context, clusterID, userID string, interval time.Duration) (ClusterUser, error) {
+	var user ClusterUser
+	err := WaitForStatus(ctx, interval, StatusOK, func(ctx context.Context) (string, error) {
+		var err error
+		user, err = c.GetClusterUser(ctx, clusterID, userID)
+		return user.Status, err
+	})
+	return user, err
+}
+
+// WaitDump ждёт, пока дамп перейдёт в статус OK, и возвращает его
+func (c *Client) WaitDump(ctx context.Context, dumpID string, interval time.Duration) (Dump, error) {
+	var dump Dump
+	err := WaitForStatus(ctx, interval, StatusOK, func(ctx context.Context) (string, error) {
+		var err error
+		dump, err = c.GetDump(ctx, dumpID)
+		return dump.Status, err
+	})
+	return dump, err
+}
+
+// WaitDeleted ждёт, пока get не вернёт 404
+func WaitDeleted(ctx context.Context, interval time.Duration, get func(context.Context) error) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := get(ctx)
+		if IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("ресурс не удалён: %w", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -3,22 +3,27 @@ package main
 import (
 	"context"
 	"fmt"
-	"github.com/jackc/pgx/v4"
-	"github.com/stretchr/testify/assert"
 	"math/rand"
 	"net/http"
 	"strings"
 	"testing"
 	"time"
+
+	"dbaas_testing_task/metrics"
+	"dbaas_testing_task/runstate"
 )
 
 func TestEndToEnd(t *testing.T) {
 	defer Teardown(t)
+	// При RESUME=1 и заданном STATE_FILE завершённые шаги прерванного запуска пропускаются
+	StartSteps(t)
+	// Ошибки проверок попадают в отчёт о шаге
+	a := Assert(t)
 
 	// Проверяем наличие переменных окружения
-	assert.NotEmpty(t, login, "Отсутствует переменная окружения API_LOGIN")
-	assert.NotEmpty(t, password, "Отсутствует переменная окружения API_PASSWORD")
-	assert.NotEmpty(t, apiBaseURL, "Отсутствует переменная окружения API_BASE_URL")
+	a.NotEmpty(login, "Отсутствует переменная окружения API_LOGIN")
+	a.NotEmpty(password, "Отсутствует переменная окружения API_PASSWORD")
+	a.NotEmpty(apiBaseURL, "Отсутствует переменная окружения API_BASE_URL")
 
 	// Шаг 1: Авторизация через API
 	requestBody := map[string]string{
@@ -29,306 +34,311 @@ func TestEndToEnd(t *testing.T) {
 	resp, err := makeRequest(t, "POST", fmt.Sprintf("%s/api/authorize", apiBaseURL), requestBody, map[string]string{
 		"Content-Type": "application/json",
 	})
-	assert.NoError(t, err, "Ошибка при выполнении запроса на авторизацию")
-	assert.Equal(t, http.StatusOK, resp.StatusCode, "Ожидался статус 200")
+	a.NoError(err, "Ошибка при выполнении запроса на авторизацию")
+	a.Equal(http.StatusOK, resp.StatusCode, "Ожидался статус 200")
 
 	parseResponseBody(t, resp, &authResponse)
-	assert.NotEmpty(t, authResponse.RefreshToken, "Поле refresh_token пустое в ответе API")
+	a.NotEmpty(authResponse.RefreshToken, "Поле refresh_token пустое в ответе API")
 
 	refreshToken = authResponse.RefreshToken
-	t.Logf("Authorization successful")
+	Logf(t, "Authorization successful")
 
 	// Шаг 2: Создаём двухнодовый кластер Postgres
-	typeId = GetTypeID(t)
-	assert.NotEmpty(t, typeId,"Ошибка при получении TypeID")
-	flavorId = GetFlavorID(t)
-	assert.NotEmpty(t, flavorId, "Ошибка при получении FlavorID")
-	// Наполняем и отправляем запрос на создание кластера
-	createClusterRequestBody := CreateClusterRequest{
-		TypeID: typeId,
-		Options: Options{
-			MaximumLagOnFailover:  1048576,
-			WalArchiveMode:        false,
-			AutoRestart:           false,
-			Production:            false,
-			EnableSynchronousMode: false,
-			DisableAutofailover:   false,
-		},
-		DiskSize:      3221225472,
-		Mode:          "create",
-		ReplicasCount: 1,
-		CreationMode:  "empty",
-		Name:          "test",
-		FlavorID:      flavorId,
-		TypeName:      "Postgres Pro Enterprise",
-		Az:            "GZ1",
-		HAManager:     "patroni",
-		HA:            false,
-	}
+	if !Step(t, "create-cluster", func() {
+		typeId = GetTypeID(t)
+		a.NotEmpty(typeId, "Ошибка при получении TypeID")
+		flavorId = GetFlavorID(t)
+		a.NotEmpty(flavorId, "Ошибка при получении FlavorID")
+		// Наполняем и отправляем запрос на создание кластера
+		createClusterRequestBody := CreateClusterRequest{
+			TypeID: typeId,
+			Options: Options{
+				MaximumLagOnFailover:  1048576,
+				WalArchiveMode:        false,
+				AutoRestart:           false,
+				Production:            false,
+				EnableSynchronousMode: false,
+				DisableAutofailover:   false,
+			},
+			DiskSize:      3221225472,
+			Mode:          "create",
+			ReplicasCount: 1,
+			CreationMode:  "empty",
+			Name:          "test",
+			FlavorID:      flavorId,
+			TypeName:      "Postgres Pro Enterprise",
+			Az:            "GZ1",
+			HAManager:     "patroni",
+			HA:            false,
+		}
 
-	resp, err = makeRequest(t, "POST", fmt.Sprintf("%s/api/clusters", apiBaseURL), createClusterRequestBody, map[string]string{
-		"Authorization": "Bearer " + refreshToken,
-		"Content-Type":  "application/json",
-	})
-	assert.NoError(t, err, "Ошибка при выполнении запроса на создание кластера")
-	assert.Equal(t, http.StatusCreated, resp.StatusCode, "Ожидался статус 201")
-
-	parseResponseBody(t, resp, &createClusterResponse)
-	clusterId = createClusterResponse.Instances[0].ClusterID
-	t.Logf("Cluster created with ID: %s", clusterId)
-	//Ждём пока кластер перейдёт в состояние OK
-	for i := 0; i < 30; i++ {
-		resp, err = makeRequest(t, "GET", fmt.Sprintf("%s/api/clusters/"+clusterId, apiBaseURL), nil, map[string]string{
+		started := time.Now()
+		resp, err = makeRequest(t, "POST", fmt.Sprintf("%s/api/clusters", apiBaseURL), createClusterRequestBody, map[string]string{
 			"Authorization": "Bearer " + refreshToken,
 			"Content-Type":  "application/json",
 		})
-		assert.NoError(t, err, "Ошибка при выполнении запроса на получение информации о кластере")
-
-		parseResponseBody(t, resp, &clusterStatusResponse)
-
-		if clusterStatusResponse.Status == "OK" {
-			break
+		a.NoError(err, "Ошибка при выполнении запроса на создание кластера")
+		a.Equal(http.StatusCreated, resp.StatusCode, "Ожидался статус 201")
+
+		parseResponseBody(t, resp, &createClusterResponse)
+		clusterId = createClusterResponse.Instances[0].ClusterID
+		Logf(t, "Cluster created with ID: %s", clusterId)
+		JournalResource(t, runstate.KindCluster, clusterId, "", "test")
+		//Ждём пока кластер перейдёт в состояние OK
+		for i := 0; i < 30; i++ {
+			resp, err = makeRequest(t, "GET", fmt.Sprintf("%s/api/clusters/"+clusterId, apiBaseURL), nil, map[string]string{
+				"Authorization": "Bearer " + refreshToken,
+				"Content-Type":  "application/json",
+			})
+			a.NoError(err, "Ошибка при выполнении запроса на получение информации о кластере")
+
+			parseResponseBody(t, resp, &clusterStatusResponse)
+
+			if clusterStatusResponse.Status == "OK" {
+				break
+			}
+			Logf(t, "Cluster status is %s, waiting for OK at %s", clusterStatusResponse.Status, time.Now().Format("2006-01-02 15:04:05.000"))
+			time.Sleep(statusPollInterval)
 		}
-		t.Logf("Cluster status is %s, waiting for OK at %s", clusterStatusResponse.Status, time.Now().Format("2006-01-02 15:04:05.000"))
-		time.Sleep(5 * time.Second)
+		// Время записывается и при таймауте, чтобы SLO учёл его
+		RecordOperation(t, metrics.OpClusterCreate, started)
+		if clusterStatusResponse.Status != "OK" {
+			Fatalf(t, "Кластер не перешёл в статус OK, последний статус: %s", clusterStatusResponse.Status)
+		}
+	}) {
+		clusterId = JournaledID(t, runstate.KindCluster, "test")
 	}
-	// Получаем список tablespace и используем дефолтный
-	resp, err = makeRequest(t, "GET", fmt.Sprintf("%s/api/clusters/"+clusterId+"/tablespaces", apiBaseURL), nil, map[string]string{
-		"Authorization": "Bearer " + refreshToken,
-		"Content-Type":  "application/json",
-	})
-	assert.NoError(t, err, "Ошибка при выполнении запроса на получение информации о tablespace")
-
-	parseResponseBody(t, resp, &tableSpaceResponse)
-	tableSpaceId = tableSpaceResponse[0].Id
 
 	// Шаг 3: Создаём базу данных
-	createDBRequestBody := CreateDBRequest{
-		Name:         "testDB",
-		TableSpaceID: tableSpaceId,
-	}
-	// Наполняем и отправляем запрос на создание базы данных
-	resp, err = makeRequest(t, "POST", fmt.Sprintf("%s/api/clusters/"+clusterId+"/databases", apiBaseURL), createDBRequestBody, map[string]string{
-		"Authorization": "Bearer " + refreshToken,
-		"Content-Type":  "application/json",
-	})
-	assert.NoError(t, err, "Ошибка при выполнении запроса на создание базы данных")
-	assert.Equal(t, http.StatusCreated, resp.StatusCode, "Ожидался статус 201")
-
-	parseResponseBody(t, resp, &createDBResponse)
-	dbId = createDBResponse.Id
-	t.Logf("Database created with ID: %s", dbId)
-	// Ждём пока база данных перейдёт в состояние OK
-	for i := 0; i < 30; i++ {
-		resp, err = makeRequest(t, "GET", fmt.Sprintf("%s/api/clusters/"+clusterId+"/databases/"+dbId, apiBaseURL), nil, map[string]string{
+	if !Step(t, "create-db", func() {
+		// Получаем список tablespace и используем дефолтный
+		tableSpaceId = GetDefaultTableSpaceID(t, clusterId)
+		createDBRequestBody := CreateDBRequest{
+			Name:         "testDB",
+			TableSpaceID: tableSpaceId,
+		}
+		// Наполняем и отправляем запрос на создание базы данных
+		started := time.Now()
+		resp, err = makeRequest(t, "POST", fmt.Sprintf("%s/api/clusters/"+clusterId+"/databases", apiBaseURL), createDBRequestBody, map[string]string{
 			"Authorization": "Bearer " + refreshToken,
 			"Content-Type":  "application/json",
 		})
-		assert.NoError(t, err, "Ошибка при выполнении запроса на получение информации о базе данных")
-
-		parseResponseBody(t, resp, &dbStatusResponse)
-
-		if dbStatusResponse.Status == "OK" {
-			break
+		a.NoError(err, "Ошибка при выполнении запроса на создание базы данных")
+		a.Equal(http.StatusCreated, resp.StatusCode, "Ожидался статус 201")
+
+		parseResponseBody(t, resp, &createDBResponse)
+		dbId = createDBResponse.Id
+		Logf(t, "Database created with ID: %s", dbId)
+		JournalResource(t, runstate.KindDatabase, dbId, clusterId, "testDB")
+		// Ждём пока база данных перейдёт в состояние OK
+		var database Database
+		for i := 0; i < 30; i++ {
+			resp, err = makeRequest(t, "GET", fmt.Sprintf("%s/api/clusters/"+clusterId+"/databases/"+dbId, apiBaseURL), nil, map[string]string{
+				"Authorization": "Bearer " + refreshToken,
+				"Content-Type":  "application/json",
+			})
+			a.NoError(err, "Ошибка при выполнении запроса на получение информации о базе данных")
+
+			parseResponseBody(t, resp, &database)
+
+			if database.Status == "OK" {
+				break
+			}
+			Logf(t, "Database status is %s, waiting for OK at %s", database.Status, time.Now().Format("2006-01-02 15:04:05.000"))
+			time.Sleep(statusPollInterval)
 		}
-		t.Logf("Database status is %s, waiting for OK at %s", dbStatusResponse.Status, time.Now().Format("2006-01-02 15:04:05.000"))
-		time.Sleep(5 * time.Second)
-	}
-    // Шаг 4: Создаём пользователя базы данных
-	createClusterUserRequestBody := CreateClusterUserRequest{
-		Databases: []string{"testDB"},
-		Roles:     []string{"pg_write_all_data", "pg_read_all_data"},
-		Name:      login,
-		Password:  password,
+		RecordOperation(t, metrics.OpDatabaseCreate, started)
+		if database.Status != "OK" {
+			Fatalf(t, "База данных не перешла в статус OK, последний статус: %s", database.Status)
+		}
+	}) {
+		dbId = JournaledID(t, runstate.KindDatabase, "testDB")
 	}
-	// Наполняем и отправляем запрос на создание пользователя
-	resp, err = makeRequest(t, "POST", fmt.Sprintf("%s/api/clusters/"+clusterId+"/users", apiBaseURL), createClusterUserRequestBody, map[string]string{
-		"Authorization": "Bearer " + refreshToken,
-		"Content-Type":  "application/json",
-	})
-	assert.NoError(t, err, "Ошибка при выполнении запроса на создание пользователя")
-	assert.Equal(t, http.StatusCreated, resp.StatusCode, "Ожидался статус 201")
 
-	t.Logf("Database user created with login: %s", login)
+	// Шаг 4: Создаём пользователя базы данных со сгенерированными учётными данными,
+	// не связанными с учётной записью API
+	dbUser := DBUserCredentials(t, "app")
+	Step(t, "create-user", func() {
+		createClusterUserRequestBody := CreateClusterUserRequest{
+			Databases: []string{"testDB"},
+			Roles:     []string{"pg_write_all_data", "pg_read_all_data"},
+			Name:      dbUser.Username,
+			Password:  dbUser.Password,
+		}
+		// Наполняем и отправляем запрос на создание пользователя
+		resp, err = makeRequest(t, "POST", fmt.Sprintf("%s/api/clusters/"+clusterId+"/users", apiBaseURL), createClusterUserRequestBody, map[string]string{
+			"Authorization": "Bearer " + refreshToken,
+			"Content-Type":  "application/json",
+		})
+		a.NoError(err, "Ошибка при выполнении запроса на создание пользователя")
+		a.Equal(http.StatusCreated, resp.StatusCode, "Ожидался статус 201")
+
+		Logf(t, "Database user created with login: %s", dbUser.Username)
+	})
 
-    // Шаг 5: Подключаемся к базе данных
+	// Шаг 5: Подключаемся к базе данных
 	resp, err = makeRequest(t, "GET", fmt.Sprintf("%s/api/clusters/"+clusterId+"/databases", apiBaseURL), nil, map[string]string{
 		"Authorization": "Bearer " + refreshToken,
 		"Content-Type":  "application/json",
 	})
-	assert.NoError(t, err, "Ошибка при выполнении запроса на получение информации о базах данных")
+	a.NoError(err, "Ошибка при выполнении запроса на получение информации о базах данных")
 
 	// Парсим ответ и формируем connection string
-	parseResponseBody(t, resp, &responseDBUsers)
+	parseResponseBody(t, resp, &databasesResponse)
 
-	conString = responseDBUsers[0].MasterConnectionString
-	conString = strings.Replace(conString, "<username>", login, 1)
-	conString = strings.Replace(conString, "<password>", password, 1)
-	// Подключаемся к базе данных
+	conString = databasesResponse[0].MasterConnectionString
+	conString = strings.Replace(conString, "<username>", dbUser.Username, 1)
+	conString = strings.Replace(conString, "<password>", dbUser.Password, 1)
+	// Подключаемся к базе данных через пул соединений
 	ctx := context.Background()
 
-	conn, err := pgx.Connect(ctx, conString)
-	assert.NoError(t, err, "не удалось подключиться к базе данных")
-	defer conn.Close(ctx)
-    // Шаг 6: Создаём схему данных и таблицу. Добавляем в таблицу произвольные данные
-
-	_, err = conn.Exec(ctx, `
-		CREATE SCHEMA IF NOT EXISTS test_schema;
-	`)
-	assert.NoError(t, err, "не удалось создать схему данных")
-	t.Logf("Schema created successfully")
-	// Создаём таблицу users
-	_, err = conn.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS test_schema.users (
-			id SERIAL PRIMARY KEY,
-			name VARCHAR(100) NOT NULL,
-			email VARCHAR(100) NOT NULL,
-			age INT,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		);
-	`)
-	assert.NoError(t, err, "не удалось создать таблицу")
-	t.Logf("Table 'Users' created")
-
-	// Добавляем в таблицу произвольные данные
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	for i := 1; i <= 10; i++ {
-		name := fmt.Sprintf("Пользователь %d", i)
-		email := fmt.Sprintf("user%d@example.com", i)
-		age := r.Intn(50) + 18
-
-		_, err = conn.Exec(ctx, `
-			INSERT INTO test_schema.users (name, email, age)
-			VALUES ($1, $2, $3)
-		`, name, email, age)
-
-		assert.NoError(t, err, "не удалось вставить данные")
-	}
-	t.Logf("Random data inserted inserted into the table")
-
-    // Шаг 7: Создаём дамп базы данных
-	createDumpRequestBody := map[string]string{
-		"name": "testBackup",
-	}
-	// Наполняем и отправляем запрос на создание дампа
-	resp, err = makeRequest(t, "POST", fmt.Sprintf("%s/api/clusters/%s/databases/%s/dumps", apiBaseURL, clusterId, dbId), createDumpRequestBody, map[string]string{
-		"Authorization": "Bearer " + refreshToken,
-		"Content-Type":  "application/json",
+	poolConfig := LoadPoolConfig(t)
+	session := NewDBSession(t, ctx, conString, poolConfig)
+	// Шаг 6: Создаём схему данных и таблицу. Добавляем в таблицу произвольные данные
+	Step(t, "seed", func() {
+
+		err = CreateTestSchema(ctx, session)
+		a.NoError(err, "не удалось создать схему данных")
+		Logf(t, "Schema and table 'Users' created")
+
+		// Добавляем в таблицу произвольные данные
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+		err = SeedUsers(ctx, session, r, 10)
+		a.NoError(err, "не удалось вставить данные")
+		Logf(t, "Random data inserted inserted into the table")
+
+		// Нагружаем кластер конкурентными клиентами
+		err = CreateWorkloadTable(ctx, session)
+		a.NoError(err, "не удалось создать таблицу нагрузки")
+		workloadConfig := LoadWorkloadConfig(t, poolConfig)
+		stats := RunWorkload(ctx, session, workloadConfig)
+		a.Zero(stats.Errors, "ошибки при конкурентной нагрузке, последняя: %s", stats.LastError)
+		a.NotZero(stats.Writes, "генератор нагрузки не выполнил ни одной записи")
+		Logf(t, "Workload finished: %d clients, %d reads, %d writes, pool %s", workloadConfig.Clients, stats.Reads, stats.Writes, session.Stat())
 	})
-	assert.NoError(t, err, "Ошибка при выполнении запроса")
-	assert.Equal(t, http.StatusCreated, resp.StatusCode, "Ожидался статус 201")
 
-	var createDumpResponse struct {
-		ID string `json:"id"`
-	}
-	parseResponseBody(t, resp, &createDumpResponse)
-	dumpId = createDumpResponse.ID
-	t.Logf("Database dump created with ID: %s", dumpId)
-	// Ждём пока дамп перейдёт в состояние OK
-	for i := 0; i < 30; i++ {
-		resp, err = makeRequest(t, "GET", fmt.Sprintf("%s/api/dumps/%s", apiBaseURL, dumpId), nil, map[string]string{
+	// Шаг 7: Создаём дамп базы данных
+	if !Step(t, "create-dump", func() {
+		createDumpRequestBody := map[string]string{
+			"name": "testBackup",
+		}
+		// Наполняем и отправляем запрос на создание дампа
+		started := time.Now()
+		resp, err = makeRequest(t, "POST", fmt.Sprintf("%s/api/clusters/%s/databases/%s/dumps", apiBaseURL, clusterId, dbId), createDumpRequestBody, map[string]string{
 			"Authorization": "Bearer " + refreshToken,
 			"Content-Type":  "application/json",
 		})
-		assert.NoError(t, err, "Ошибка при выполнении запроса на получение информации о дампе")
+		a.NoError(err, "Ошибка при выполнении запроса")
+		a.Equal(http.StatusCreated, resp.StatusCode, "Ожидался статус 201")
 
+		var createDumpResponse struct {
+			ID string `json:"id"`
+		}
+		parseResponseBody(t, resp, &createDumpResponse)
+		dumpId = createDumpResponse.ID
+		Logf(t, "Database dump created with ID: %s", dumpId)
+		JournalResource(t, runstate.KindDump, dumpId, clusterId, "testBackup")
+		// Ждём пока дамп перейдёт в состояние OK
 		var dumpStatusResponse struct {
 			Status string `json:"status"`
 		}
-		parseResponseBody(t, resp, &dumpStatusResponse)
-
-		if dumpStatusResponse.Status == "OK" {
-			break
+		for i := 0; i < 30; i++ {
+			resp, err = makeRequest(t, "GET", fmt.Sprintf("%s/api/dumps/%s", apiBaseURL, dumpId), nil, map[string]string{
+				"Authorization": "Bearer " + refreshToken,
+				"Content-Type":  "application/json",
+			})
+			a.NoError(err, "Ошибка при выполнении запроса на получение информации о дампе")
+
+			parseResponseBody(t, resp, &dumpStatusResponse)
+
+			if dumpStatusResponse.Status == "OK" {
+				break
+			}
+			Logf(t, "Dump status is %s, waiting for OK at %s", dumpStatusResponse.Status, time.Now().Format("2006-01-02 15:04:05.000"))
+			time.Sleep(statusPollInterval)
 		}
-		t.Logf("Dump status is %s, waiting for OK at %s", dumpStatusResponse.Status, time.Now().Format("2006-01-02 15:04:05.000"))
-		time.Sleep(5 * time.Second)
-	}
-
-    // Шаг 8: Очищаем созданную таблицу
-	_, err = conn.Exec(ctx, `
-		TRUNCATE TABLE test_schema.users;
-	`)
-	assert.NoError(t, err, "не удалось очистить таблицу")
-	t.Logf("Table truncated")
-    
-	// Шаг 9: Восстанавливаем базу данных из дампа
-	restoreDumpRequestBody := map[string]interface{}{
-		"dump_id":       dumpId,
-		"mode":          "full",
-		"restore_users": false,
+		RecordOperation(t, metrics.OpDumpCreate, started)
+		if dumpStatusResponse.Status != "OK" {
+			Fatalf(t, "Дамп не перешёл в статус OK, последний статус: %s", dumpStatusResponse.Status)
+		}
+	}) {
+		dumpId = JournaledID(t, runstate.KindDump, "testBackup")
 	}
 
-	resp, err = makeRequest(t, "POST", fmt.Sprintf("%s/api/clusters/%s/databases/%s/dump_restore", apiBaseURL, clusterId, dbId), restoreDumpRequestBody, map[string]string{
-		"Authorization": "Bearer " + refreshToken,
-		"Content-Type":  "application/json",
+	// Шаг 8: Очищаем созданную таблицу
+	Step(t, "truncate", func() {
+		_, err = session.Exec(ctx, `
+			TRUNCATE TABLE test_schema.users;
+		`)
+		a.NoError(err, "не удалось очистить таблицу")
+		Logf(t, "Table truncated")
 	})
-	assert.NoError(t, err, "Ошибка при выполнении запроса на восстановление базы данных из дампа")
-	// Ждём пока дамп перейдёт в состояние OK
-	for i := 0; i < 30; i++ {
-		resp, err = makeRequest(t, "GET", fmt.Sprintf("%s/api/dumps/%s", apiBaseURL, dumpId), nil, map[string]string{
-			"Authorization": "Bearer " + refreshToken,
-			"Content-Type":  "application/json",
-		})
-		assert.NoError(t, err, "Ошибка при выполнении запроса на получение информации о дампе")
-
-		var dumpStatusResponse struct {
-			Status string `json:"status"`
-		}
-		parseResponseBody(t, resp, &dumpStatusResponse)
 
-		if dumpStatusResponse.Status == "OK" {
-			break
+	// Шаг 9: Восстанавливаем базу данных из дампа
+	Step(t, "restore", func() {
+		restoreDumpRequestBody := map[string]interface{}{
+			"dump_id":       dumpId,
+			"mode":          "full",
+			"restore_users": false,
 		}
-		t.Logf("Dump status is %s, waiting for OK at %s", dumpStatusResponse.Status, time.Now().Format("2006-01-02 15:04:05.000"))
-		time.Sleep(5 * time.Second)
-	}
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode, "Ожидался статус 200")
-	// Ждём пока база данных перейдёт в состояние OK после восстановления
-	for i := 0; i < 30; i++ {
-		resp, err = makeRequest(t, "GET", fmt.Sprintf("%s/api/clusters/%s/databases/%s", apiBaseURL, clusterId, dbId), nil, map[string]string{
+		started := time.Now()
+		resp, err = makeRequest(t, "POST", fmt.Sprintf("%s/api/clusters/%s/databases/%s/dump_restore", apiBaseURL, clusterId, dbId), restoreDumpRequestBody, map[string]string{
 			"Authorization": "Bearer " + refreshToken,
 			"Content-Type":  "application/json",
 		})
-		assert.NoError(t, err, "Ошибка при выполнении запроса на получение информации о базе данных")
-
-		parseResponseBody(t, resp, &dbStatusResponse)
+		a.NoError(err, "Ошибка при выполнении запроса на восстановление базы данных из дампа")
+		// Ждём пока дамп перейдёт в состояние OK
+		for i := 0; i < 30; i++ {
+			resp, err = makeRequest(t, "GET", fmt.Sprintf("%s/api/dumps/%s", apiBaseURL, dumpId), nil, map[string]string{
+				"Authorization": "Bearer " + refreshToken,
+				"Content-Type":  "application/json",
+			})
+			a.NoError(err, "Ошибка при выполнении запроса на получение информации о дампе")
+
+			var dumpStatusResponse struct {
+				Status string `json:"status"`
+			}
+			parseResponseBody(t, resp, &dumpStatusResponse)
+
+			if dumpStatusResponse.Status == "OK" {
+				break
+			}
+			Logf(t, "Dump status is %s, waiting for OK at %s", dumpStatusResponse.Status, time.Now().Format("2006-01-02 15:04:05.000"))
+			time.Sleep(statusPollInterval)
+		}
 
-		if dbStatusResponse.Status == "OK" {
-			break
+		a.Equal(http.StatusOK, resp.StatusCode, "Ожидался статус 200")
+		// Ждём пока база данных перейдёт в состояние OK после восстановления
+		var database Database
+		for i := 0; i < 30; i++ {
+			resp, err = makeRequest(t, "GET", fmt.Sprintf("%s/api/clusters/%s/databases/%s", apiBaseURL, clusterId, dbId), nil, map[string]string{
+				"Authorization": "Bearer " + refreshToken,
+				"Content-Type":  "application/json",
+			})
+			a.NoError(err, "Ошибка при выполнении запроса на получение информации о базе данных")
+
+			parseResponseBody(t, resp, &database)
+
+			if database.Status == "OK" {
+				break
+			}
+			Logf(t, "Database status is %s, waiting for OK at %s", database.Status, time.Now().Format("2006-01-02 15:04:05.000"))
+			time.Sleep(statusPollInterval)
 		}
-		t.Logf("Database status is %s, waiting for OK at %s", dbStatusResponse.Status, time.Now().Format("2006-01-02 15:04:05.000"))
-		time.Sleep(5 * time.Second)
-	}
+		RecordOperation(t, metrics.OpDumpRestore, started)
+		if database.Status != "OK" {
+			Fatalf(t, "База данных не перешла в статус OK после восстановления, последний статус: %s", database.Status)
+		}
+	})
 
 	// Шаг 10: Проверяем что записи в таблице успешно восстановлены
-	rows, err := conn.Query(ctx, `
-		SELECT name, email, age
-		FROM test_schema.users;
-	`)
-	assert.NoError(t, err, "не удалось выполнить запрос")
-	defer rows.Close()
-
-	var restoredUsers []struct {
-		Name  string
-		Email string
-		Age   int
-	}
-
-	for rows.Next() {
-		var user struct {
-			Name  string
-			Email string
-			Age   int
-		}
-		err := rows.Scan(&user.Name, &user.Email, &user.Age)
-		assert.NoError(t, err, "не удалось прочитать данные")
-		restoredUsers = append(restoredUsers, user)
-	}
+	restoredUsers, err := FetchUsers(ctx, session)
+	a.NoError(err, "не удалось прочитать данные")
 
-	assert.Equal(t, 10, len(restoredUsers), "Ожидалось 10 записей")
-	if assert.Equal(t, 10, len(restoredUsers), "Ожидалось 10 записей") {
-		t.Logf("Data restored successfully")
+	a.Equal(10, len(restoredUsers), "Ожидалось 10 записей")
+	if a.Equal(10, len(restoredUsers), "Ожидалось 10 записей") {
+		Logf(t, "Data restored successfully")
 	}
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+)
+
+const (
+	// Коды ошибок Postgres, которыми сервер сообщает о нехватке места на диске
+	pgErrDiskFull               = "53100"
+	pgErrReadOnlySQLTransaction = "25006"
+	// fillerBatchRows задаёт число строк по ~1 КБ, добавляемых одним запросом при заполнении диска
+	fillerBatchRows = 65536
+)
+
+// CreateFillerTable создаёт таблицу для заполнения диска. Строки по ~1 КБ меньше порога TOAST,
+// поэтому хранятся в самой таблице без сжатия
+func CreateFillerTable(ctx context.Context, s *DBSession) error {
+	_, err := s.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS test_schema.filler (
+			id BIGSERIAL PRIMARY KEY,
+			payload TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу для заполнения диска: %w", err)
+	}
+	return nil
+}
+
+// DatabaseSize возвращает размер текущей базы данных в байтах
+func DatabaseSize(ctx context.Context, s *DBSession) (int64, error) {
+	var size int64
+	err := s.QueryRow(ctx, `SELECT pg_database_size(current_database())`, nil, &size)
+	return size, err
+}
+
+// CountFillerRows возвращает число строк в таблице для заполнения диска
+func CountFillerRows(ctx context.Context, s *DBSession) (int64, error) {
+	var count int64
+	err := s.QueryRow(ctx, `SELECT count(*) FROM test_schema.filler`, nil, &count)
+	return count, err
+}
+
+// FillDisk добавляет в test_schema.filler случайные данные, пока размер базы не достигнет targetBytes.
+// Возвращает число записанных строк и ошибку, на которой заполнение остановилось
+func FillDisk(ctx context.Context, s *DBSession, targetBytes int64) (int64, error) {
+	var rows int64
+	for {
+		size, err := DatabaseSize(ctx, s)
+		if err != nil {
+			return rows, err
+		}
+		if size >= targetBytes {
+			return rows, nil
+		}
+
+		tag, err := s.Exec(ctx, `
+			INSERT INTO test_schema.filler (payload)
+			SELECT (SELECT string_agg(md5(random()::text || g::text), '') FROM generate_series(1, 32))
+			FROM generate_series(1, $1) g
+		`, fillerBatchRows)
+		if err != nil {
+			return rows, err
+		}
+		rows += tag.RowsAffected()
+	}
+}
+
+// IsDiskFullError проверяет, что ошибка вызвана нехваткой места на диске
+// или переводом кластера в режим только для чтения
+func IsDiskFullError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgErrDiskFull || pgErr.Code == pgErrReadOnlySQLTransaction
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// diskFillRatio возвращает долю диска, до которой заполняется база перед расширением диска
+func diskFillRatio(t *testing.T) float64 {
+	ratio := 0.8
+	if v := os.Getenv("DBAAS_DISK_FILL_RATIO"); v != "" {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil || r <= 0 || r >= 1 {
+			Fatalf(t, "Некорректное значение DBAAS_DISK_FILL_RATIO: %q", v)
+		}
+		ratio = r
+	}
+	return ratio
+}
+
+func TestClusterDiskExpansion(t *testing.T) {
+	a := Assert(t)
+	RequireAPIEnv(t)
+	Authorize(t)
+
+	ctx := context.Background()
+	request := DefaultClusterRequest(t, "disk-"+RandomSuffix())
+	f := newScalingFixture(t, ctx, request)
+	a.NoError(CreateFillerTable(ctx, f.session), "не удалось создать таблицу для заполнения диска")
+
+	// Заполняем диск до порога
+	target := int64(float64(request.DiskSize) * diskFillRatio(t))
+	rows, err := FillDisk(ctx, f.session, target)
+	if err != nil {
+		Fatalf(t, "Не удалось заполнить диск до %d байт: %v", target, err)
+	}
+	size, _ := DatabaseSize(ctx, f.session)
+	Logf(t, "Disk filled with %d rows, database size %d of %d bytes", rows, size, request.DiskSize)
+
+	// Расширяем диск вдвое и проверяем, что запись продолжается
+	newSize := request.DiskSize * 2
+	cluster := ExpandClusterDisk(t, f.clusterId, newSize)
+	a.Equal(newSize, cluster.DiskSize)
+	a.NoError(f.session.WaitReady(ctx, statusPollAttempts, statusPollInterval), "база данных недоступна после расширения диска")
+
+	moreRows, err := FillDisk(ctx, f.session, size+int64(float64(request.DiskSize)*0.5))
+	a.NoError(err, "запись не возобновилась после расширения диска")
+
+	count, err := CountFillerRows(ctx, f.session)
+	a.NoError(err, "не удалось прочитать данные")
+	a.Equal(rows+moreRows, count, "число строк не совпадает с записанным")
+	f.verifyNoDataLoss(t, ctx, 0)
+}
+
+func TestClusterDiskFull(t *testing.T) {
+	a := Assert(t)
+	RequireAPIEnv(t)
+	Authorize(t)
+
+	ctx := context.Background()
+	request := DefaultClusterRequest(t, "diskfull-"+RandomSuffix())
+	f := newScalingFixture(t, ctx, request)
+	a.NoError(CreateFillerTable(ctx, f.session), "не удалось создать таблицу для заполнения диска")
+
+	// Пишем, пока сервер не откажет в записи
+	rows, err := FillDisk(ctx, f.session, request.DiskSize*2)
+	if !a.Error(err, "диск размером %d байт не заполнился", request.DiskSize) {
+		return
+	}
+	Logf(t, "Writes stopped after %d rows: %v", rows, err)
+	a.True(IsDiskFullError(err), "ожидалась ошибка нехватки места (53100) или режима только для чтения (25006), получена: %v", err)
+
+	// Кластер не должен сообщать о штатном состоянии при заполненном диске.
+	// Точный статус можно зафиксировать через DBAAS_DISK_FULL_STATUS
+	cluster := GetCluster(t, f.clusterId)
+	Logf(t, "Cluster status with full disk: %s", cluster.Status)
+	a.NotEqual("OK", cluster.Status, "кластер сообщает статус OK при заполненном диске")
+	if expected := os.Getenv("DBAAS_DISK_FULL_STATUS"); expected != "" {
+		a.Equal(expected, cluster.Status, "неожиданный статус кластера при заполненном диске")
+	}
+}
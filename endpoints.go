@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+)
+
+// Endpoint описывает метод API. Path содержит подстановки вида {cluster_id}.
+type Endpoint struct {
+	Method string
+	Path   string
+}
+
+// KnownEndpoints перечисляет все методы API, которые используются в тестах, кроме /api/authorize
+var KnownEndpoints = []Endpoint{
+	{"GET", "/api/flavors"},
+	{"GET", "/api/types"},
+	{"GET", "/api/clusters"},
+	{"POST", "/api/clusters"},
+	{"GET", "/api/clusters/{cluster_id}"},
+	{"PATCH", "/api/clusters/{cluster_id}"},
+	{"DELETE", "/api/clusters/{cluster_id}"},
+	{"POST", "/api/clusters/{cluster_id}/restart"},
+	{"GET", "/api/clusters/{cluster_id}/parameters"},
+	{"PATCH", "/api/clusters/{cluster_id}/parameters"},
+	{"GET", "/api/clusters/{cluster_id}/tablespaces"},
+	{"POST", "/api/clusters/{cluster_id}/tablespaces"},
+	{"GET", "/api/clusters/{cluster_id}/tablespaces/{tablespace_id}"},
+	{"DELETE", "/api/clusters/{cluster_id}/tablespaces/{tablespace_id}"},
+	{"GET", "/api/clusters/{cluster_id}/databases"},
+	{"POST", "/api/clusters/{cluster_id}/databases"},
+	{"GET", "/api/clusters/{cluster_id}/databases/{database_id}"},
+	{"PATCH", "/api/clusters/{cluster_id}/databases/{database_id}"},
+	{"DELETE", "/api/clusters/{cluster_id}/databases/{database_id}"},
+	{"GET", "/api/clusters/{cluster_id}/databases/{database_id}/dumps"},
+	{"POST", "/api/clusters/{cluster_id}/databases/{database_id}/dumps"},
+	{"POST", "/api/clusters/{cluster_id}/databases/{database_id}/dump_restore"},
+	{"GET", "/api/clusters/{cluster_id}/users"},
+	{"POST", "/api/clusters/{cluster_id}/users"},
+	{"GET", "/api/clusters/{cluster_id}/users/{user_id}"},
+	{"PATCH", "/api/clusters/{cluster_id}/users/{user_id}"},
+	{"DELETE", "/api/clusters/{cluster_id}/users/{user_id}"},
+	{"GET", "/api/clusters/{cluster_id}/backups"},
+	{"POST", "/api/clusters/{cluster_id}/backups"},
+	{"GET", "/api/backups/{backup_id}"},
+	{"DELETE", "/api/backups/{backup_id}"},
+	{"GET", "/api/dumps/{dump_id}"},
+	{"DELETE", "/api/dumps/{dump_id}"},
+}
+
+// URL подставляет идентификаторы в путь метода и возвращает полный адрес
+func (e Endpoint) URL(ids map[string]string) string {
+	path := e.Path
+	for key, value := range ids {
+		path = strings.ReplaceAll(path, "{"+key+"}", value)
+	}
+	return apiURL("%s", path)
+}
+
+// IsTenantScoped возвращает true для методов, обращающихся к конкретному ресурсу арендатора
+func (e Endpoint) IsTenantScoped() bool {
+	return strings.Contains(e.Path, "{")
+}
+
+// String возвращает метод и путь для сообщений тестов
+func (e Endpoint) String() string {
+	return e.Method + " " + e.Path
+}
@@ -1,21 +1,22 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"testing"
+
+	"dbaas_testing_task/redact"
+	"dbaas_testing_task/runstate"
 )
 
 var (
 	apiBaseURL            string
 	createDBResponse      CreateDBResponse
-	responseDBUsers       []ResponseDBUsers
-	clusterStatusResponse ClusterStatusResponse
+	databasesResponse     []Database
+	clusterStatusResponse Cluster
 	createClusterResponse CreateClusterResponse
-	tableSpaceResponse    []TableSpaceResponse
 	authResponse          AuthResponse
 	refreshToken          string
 	clusterId             string
@@ -34,30 +35,25 @@ func init() {
 	apiBaseURL = os.Getenv("API_BASE_URL")
 	login = os.Getenv("API_LOGIN")
 	password = os.Getenv("API_PASSWORD")
+	redact.Secret(password, os.Getenv("API_PASSWORD_2"))
 }
 // Функция для получения flavorId по имени, выглядит коряво, но в контексте тестового задания это не критично
 // В реальном проекте можно было бы сделать запрос на получение flavorId используя что-то в стиле pytest.mark.parametrize
 // Тоже самое касается и функции GetTypeID
 func GetFlavorID(t *testing.T) string {
-	// Создание запроса
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/flavors", apiBaseURL), nil)
-	if err != nil {
-		t.Fatalf("Ошибка при создании запроса: %v", err)
-	}
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Authorization", "Bearer "+refreshToken)
-
 	// Отправка запроса
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := makeRequest(t, "GET", fmt.Sprintf("%s/api/flavors", apiBaseURL), nil, map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "Bearer " + refreshToken,
+	})
 	if err != nil {
-		t.Fatalf("Ошибка при отправке запроса: %v", err)
+		Fatalf(t, "Ошибка при отправке запроса: %v", err)
 	}
 	defer resp.Body.Close()
 
 	// Проверка статуса ответа
 	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("Ожидался статус 200, получен: %d", resp.StatusCode)
+		Fatalf(t, "Ожидался статус 200, получен: %d", resp.StatusCode)
 	}
 
 	// Декодирование ответа
@@ -66,7 +62,7 @@ func GetFlavorID(t *testing.T) string {
 		Name string `json:"name"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&flavorsResponse); err != nil {
-		t.Fatalf("Ошибка при декодировании ответа: %v", err)
+		Fatalf(t, "Ошибка при декодировании ответа: %v", err)
 	}
 
 	// Поиск flavorId по имени
@@ -77,30 +73,24 @@ func GetFlavorID(t *testing.T) string {
 		}
 	}
 
-	t.Error("Flavor с именем STD3-1-1 не найден")
+	Errorf(t, "Flavor с именем STD3-1-1 не найден")
 	return ""
 }
 // Функция для получения type_id по версии
 func GetTypeID(t *testing.T) string {
-    // Создание запроса
-    req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/types", apiBaseURL), nil)
-    if err != nil {
-        t.Fatalf("Ошибка при создании запроса: %v", err)
-    }
-    req.Header.Add("Content-Type", "application/json")
-    req.Header.Add("Authorization", "Bearer "+refreshToken)
-
     // Отправка запроса
-    client := &http.Client{}
-    resp, err := client.Do(req)
+    resp, err := makeRequest(t, "GET", fmt.Sprintf("%s/api/types", apiBaseURL), nil, map[string]string{
+        "Content-Type":  "application/json",
+        "Authorization": "Bearer " + refreshToken,
+    })
     if err != nil {
-        t.Fatalf("Ошибка при отправке запроса: %v", err)
+        Fatalf(t, "Ошибка при отправке запроса: %v", err)
     }
     defer resp.Body.Close()
 
     // Проверка статуса ответа
     if resp.StatusCode != http.StatusOK {
-        t.Fatalf("Ожидался статус 200, получен: %d", resp.StatusCode)
+        Fatalf(t, "Ожидался статус 200, получен: %d", resp.StatusCode)
     }
 
     // Декодирование ответа
@@ -109,7 +99,7 @@ func GetTypeID(t *testing.T) string {
         Version string `json:"version"`
     }
     if err := json.NewDecoder(resp.Body).Decode(&typesResponse); err != nil {
-        t.Fatalf("Ошибка при декодировании ответа: %v", err)
+        Fatalf(t, "Ошибка при декодировании ответа: %v", err)
     }
 
     // Поиск type_id по версии
@@ -119,103 +109,89 @@ func GetTypeID(t *testing.T) string {
         }
     }
 
-    t.Error("Type с версией Postgres Pro Enterprise не найден")
+    Errorf(t, "Type с версией Postgres Pro Enterprise не найден")
     return ""
 }
 
 // Функция для удаления дампа БД и кластера, используется для очистки после тестов
 func Teardown(t *testing.T) {
-	// Создание запроса на удаление дампа
-	dumpReq, err := http.NewRequest(
-		"DELETE", fmt.Sprintf("%s/api/dumps/%s", apiBaseURL, dumpId), nil)
-	if err != nil {
-		t.Fatalf("Ошибка при создании запроса на удаление дампа: %v", err)
-	}
-	dumpReq.Header.Add("Content-Type", "application/json")
-	dumpReq.Header.Add("Authorization", "Bearer "+refreshToken)
-	
-	// Отправка запроса
-	dumpClient := &http.Client{}
-	dumpResp, err := dumpClient.Do(dumpReq)
+	// Ресурсы удаляются, поэтому продолжать сценарий с завершённых шагов больше нельзя
+	FinishSteps(t)
+
+	// Отправка запроса на удаление дампа
+	dumpResp, err := makeRequest(t, "DELETE", fmt.Sprintf("%s/api/dumps/%s", apiBaseURL, dumpId), nil, map[string]string{
+		"Content-Type":  "application/json",
+		"Authorization": "Bearer " + refreshToken,
+	})
 	if err != nil {
-		t.Fatalf("Ошибка при отправке запроса на удаление дампа: %v", err)
+		Fatalf(t, "Ошибка при отправке запроса на удаление дампа: %v", err)
 	}
 	defer dumpResp.Body.Close()
 
 	// Проверка статуса ответа
 	if dumpResp.StatusCode != http.StatusNoContent {
-		t.Fatalf("Ожидался статус 204, получен: %d", dumpResp.StatusCode)
-	}
-	t.Logf("Deleted dump with ID: %s", dumpId)	
-
-	// Создание запроса на удаление кластера
-	delreq, err := http.NewRequest(
-		"DELETE", fmt.Sprintf("%s/api/clusters/"+clusterId, apiBaseURL), nil)
-	if err != nil {
-		t.Fatalf("Ошибка при создании запроса на удаление: %v", err)
+		Fatalf(t, "Ожидался статус 204, получен: %d", dumpResp.StatusCode)
 	}
-	delreq.Header.Add("Content-Type", "*/*")
-	delreq.Header.Add("Authorization", "Bearer "+refreshToken)
+	ForgetResource(t, runstate.KindDump, dumpId)
+	Logf(t, "Deleted dump with ID: %s", dumpId)	
 
-	// Отправка запроса
-	delclient := &http.Client{}
-	delresp, err := delclient.Do(delreq)
+	// Отправка запроса на удаление кластера
+	delresp, err := makeRequest(t, "DELETE", fmt.Sprintf("%s/api/clusters/"+clusterId, apiBaseURL), nil, map[string]string{
+		"Content-Type":  "*/*",
+		"Authorization": "Bearer " + refreshToken,
+	})
 	if err != nil {
-		t.Fatalf("Ошибка при отправке запроса: %v", err)
+		Fatalf(t, "Ошибка при отправке запроса: %v", err)
 	}
 	defer delresp.Body.Close()
 
 	// Проверка статуса ответа
 	if delresp.StatusCode != http.StatusNoContent {
-		t.Fatalf("Ожидался статус 204, получен: %d", delresp.StatusCode)
+		Fatalf(t, "Ожидался статус 204, получен: %d", delresp.StatusCode)
 	}
-	t.Logf("Deleted cluster with ID: %s", clusterId)
+	ForgetResource(t, runstate.KindCluster, clusterId)
+	Logf(t, "Deleted cluster with ID: %s", clusterId)
 }
 
 // Функция авторизации, используется для получения токена
 func Authorize(t *testing.T) {
 	// Проверка наличия логина и пароля в переменных окружения
 	if login == "" || password == "" {
-		t.Fatal("Отсутствуют переменные окружения API_LOGIN и/или API_PASSWORD")
+		Fatalf(t, "Отсутствуют переменные окружения API_LOGIN и/или API_PASSWORD")
 	}
 
-	// Создание тела запроса в формате JSON
-	requestBody, err := json.Marshal(map[string]string{
+	// Создание тела запроса
+	requestBody := map[string]string{
 		"login":    login,
 		"password": password,
-	})
-	if err != nil {
-		t.Fatalf("Ошибка при создании JSON запроса: %v", err)
 	}
 
 	// Отправка запроса на авторизацию
-	resp, err := http.Post(
-		fmt.Sprintf("%s/api/authorize", apiBaseURL),
-		"application/json",
-		bytes.NewBuffer(requestBody),
-	)
+	resp, err := makeRequest(t, "POST", fmt.Sprintf("%s/api/authorize", apiBaseURL), requestBody, map[string]string{
+		"Content-Type": "application/json",
+	})
 	if err != nil {
-		t.Fatalf("Ошибка при выполнении запроса: %v", err)
+		Fatalf(t, "Ошибка при выполнении запроса: %v", err)
 	}
 	defer resp.Body.Close()
 
 	// Проверка статуса ответа
 	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("Ожидался статус 200, получен: %d", resp.StatusCode)
+		Fatalf(t, "Ожидался статус 200, получен: %d", resp.StatusCode)
 	}
 
 	// Декодирование ответа
 	var authResponse AuthResponse
 	if err := json.NewDecoder(resp.Body).Decode(&authResponse); err != nil {
-		t.Fatalf("Ошибка при декодировании ответа: %v", err)
+		Fatalf(t, "Ошибка при декодировании ответа: %v", err)
 	}
 
 	// Проверка наличия токена в ответе
 	if authResponse.RefreshToken == "" {
-		t.Error("Поле refresh_token пустое в ответе API")
+		Errorf(t, "Поле refresh_token пустое в ответе API")
 	}
 
 	// Сохранение токена
 	refreshToken = authResponse.RefreshToken
-	t.Logf("Токен успешно получен")
+	Logf(t, "Токен успешно получен")
 }
@@ -6,33 +6,53 @@ import (
     "io"
     "net/http"
     "testing"
+    "time"
+
+    "dbaas_testing_task/redact"
 )
 
 // makeRequest создает и отправляет HTTP-запрос с указанным методом, URL, телом и заголовками. Возвращает HTTP-ответ
 func makeRequest(t *testing.T, method, url string, body interface{}, headers map[string]string) (*http.Response, error) {
     var bodyReader io.Reader
+    var bodyBytes []byte
     if body != nil {
-        bodyBytes, err := json.Marshal(body)
+        var err error
+        bodyBytes, err = json.Marshal(body)
         if err != nil {
-            t.Fatalf("Ошибка при сериализации тела запроса: %v", err)
+            Fatalf(t, "Ошибка при сериализации тела запроса: %v", err)
         }
         bodyReader = bytes.NewReader(bodyBytes)
+        // Пароли и токены из тела запроса не должны попасть в лог и отчёты
+        redact.Secret(redact.SecretValues(bodyBytes)...)
     }
 
     req, err := http.NewRequest(method, url, bodyReader)
     if err != nil {
-        t.Fatalf("Ошибка при создании запроса: %v", err)
+        Fatalf(t, "Ошибка при создании запроса: %v", err)
     }
 
     for key, value := range headers {
         req.Header.Add(key, value)
     }
 
-    client := &http.Client{}
+    client := &http.Client{Transport: httpTransport}
+    started := time.Now()
     resp, err := client.Do(req)
     if err != nil {
-        t.Fatalf("Ошибка при выполнении запроса: %v", err)
+        reportCall(t, method, req.URL.Path, 0, time.Since(started), err)
+        Fatalf(t, "Ошибка при выполнении запроса %s %s: %v", method, req.URL.Path, err)
+    }
+
+    // Проверяем запрос и ответ по спецификации API, сохраняя тело ответа для вызывающего кода
+    responseBytes, err := io.ReadAll(resp.Body)
+    resp.Body.Close()
+    if err != nil {
+        Fatalf(t, "Ошибка при чтении тела ответа: %v", err)
     }
+    resp.Body = io.NopCloser(bytes.NewReader(responseBytes))
+    redact.Secret(redact.SecretValues(responseBytes)...)
+    reportCall(t, method, req.URL.Path, resp.StatusCode, time.Since(started), nil)
+    checkContract(t, method, req.URL.Path, bodyBytes, resp.StatusCode, responseBytes)
 
     return resp, err
 }
@@ -41,10 +61,10 @@ func parseResponseBody(t *testing.T, resp *http.Response, result interface{}) {
     defer resp.Body.Close()
     bodyBytes, err := io.ReadAll(resp.Body)
     if err != nil {
-        t.Fatalf("Ошибка при чтении тела ответа: %v", err)
+        Fatalf(t, "Ошибка при чтении тела ответа: %v", err)
     }
     err = json.Unmarshal(bodyBytes, result)
     if err != nil {
-        t.Fatalf("Ошибка при разборе JSON: %v", err)
+        Fatalf(t, "Ошибка при разборе JSON: %v", err)
     }
 }
\ No newline at end of file
@@ -0,0 +1,441 @@
+// Package apigen генерирует модели и методы клиента пакета dbaas по спецификации OpenAPI.
+//
+// Поддерживается то же подмножество OpenAPI 3, что и при контрактных проверках. Имена и типы
+// полей Go можно уточнить расширениями спецификации:
+//   - x-go-name у схемы или свойства задаёт имя типа или поля;
+//   - x-go-type у свойства задаёт тип поля целиком;
+//   - x-go-pointer у свойства делает поле указателем;
+//   - x-go-omitempty у свойства или схемы (для всех необязательных свойств) добавляет omitempty в тег json.
+package apigen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Имена генерируемых файлов пакета dbaas
+const (
+	ModelsFile = "models.gen.go"
+	ClientFile = "client.gen.go"
+)
+
+// header открывает каждый сгенерированный файл
+const header = "// Code generated by apigen from api/openapi.yaml. DO NOT EDIT.\n\n"
+
+// schema представляет схему или свойство со значимыми для генерации полями
+type schema struct {
+	Ref         string        `yaml:"$ref"`
+	Type        string        `yaml:"type"`
+	Format      string        `yaml:"format"`
+	Description string        `yaml:"description"`
+	Required    []string      `yaml:"required"`
+	Properties  orderedMap    `yaml:"properties"`
+	Items       *schema       `yaml:"items"`
+	GoName      string        `yaml:"x-go-name"`
+	GoType      string        `yaml:"x-go-type"`
+	GoPointer   bool          `yaml:"x-go-pointer"`
+	GoOmitEmpty bool          `yaml:"x-go-omitempty"`
+	Enum        []interface{} `yaml:"enum"`
+}
+
+// orderedMap сохраняет порядок ключей YAML, чтобы поля и типы шли в порядке спецификации
+type orderedMap struct {
+	Keys   []string
+	Values map[string]*schema
+}
+
+func (m *orderedMap) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("строка %d: ожидался mapping", node.Line)
+	}
+	m.Values = make(map[string]*schema)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		var value schema
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return err
+		}
+		m.Keys = append(m.Keys, key)
+		m.Values[key] = &value
+	}
+	return nil
+}
+
+type mediaType struct {
+	Schema *schema `yaml:"schema"`
+}
+
+type response struct {
+	Ref     string               `yaml:"$ref"`
+	Content map[string]mediaType `yaml:"content"`
+}
+
+type operation struct {
+	OperationID string `yaml:"operationId"`
+	Summary     string `yaml:"summary"`
+	RequestBody *struct {
+		Content map[string]mediaType `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]*response `yaml:"responses"`
+}
+
+type spec struct {
+	Paths      yaml.Node `yaml:"paths"`
+	Components struct {
+		Schemas   orderedMap           `yaml:"schemas"`
+		Responses map[string]*response `yaml:"responses"`
+	} `yaml:"components"`
+}
+
+// Generate возвращает содержимое сгенерированных файлов по имени файла
+func Generate(data []byte) (map[string][]byte, error) {
+	var s spec
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать спецификацию: %w", err)
+	}
+	g := &generator{spec: &s}
+
+	models, err := g.models()
+	if err != nil {
+		return nil, err
+	}
+	client, err := g.client()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+	for name, source := range map[string][]byte{ModelsFile: models, ClientFile: client} {
+		formatted, err := format.Source(source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: сгенерирован некорректный код: %w", name, err)
+		}
+		files[name] = formatted
+	}
+	return files, nil
+}
+
+type generator struct {
+	spec *spec
+}
+
+// typeName возвращает имя типа Go для схемы из components/schemas
+func (g *generator) typeName(name string) (string, error) {
+	s, ok := g.spec.Components.Schemas.Values[name]
+	if !ok {
+		return "", fmt.Errorf("не найдена схема %s", name)
+	}
+	if s.GoName != "" {
+		return s.GoName, nil
+	}
+	return name, nil
+}
+
+// goType возвращает тип Go для свойства
+func (g *generator) goType(s *schema) (string, error) {
+	var typ string
+	switch {
+	case s.GoType != "":
+		typ = s.GoType
+	case s.Ref != "":
+		name, err := g.typeName(strings.TrimPrefix(s.Ref, "#/components/schemas/"))
+		if err != nil {
+			return "", err
+		}
+		typ = name
+	case s.Type == "string" && s.Format == "date-time":
+		typ = "time.Time"
+	case s.Type == "string":
+		typ = "string"
+	case s.Type == "integer" && s.Format == "int64":
+		typ = "int64"
+	case s.Type == "integer":
+		typ = "int"
+	case s.Type == "number":
+		typ = "float64"
+	case s.Type == "boolean":
+		typ = "bool"
+	case s.Type == "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("у массива не описаны элементы")
+		}
+		item, err := g.goType(s.Items)
+		if err != nil {
+			return "", err
+		}
+		typ = "[]" + item
+	case s.Type == "object":
+		typ = "map[string]interface{}"
+	default:
+		return "", fmt.Errorf("неподдерживаемый тип %q", s.Type)
+	}
+	if s.GoPointer {
+		typ = "*" + typ
+	}
+	return typ, nil
+}
+
+// models генерирует типы для всех схем из components/schemas
+func (g *generator) models() ([]byte, error) {
+	var body bytes.Buffer
+	usesTime := false
+	for _, name := range g.spec.Components.Schemas.Keys {
+		s := g.spec.Components.Schemas.Values[name]
+		typeName, _ := g.typeName(name)
+		if s.Type != "object" {
+			return nil, fmt.Errorf("схема %s: поддерживаются только объекты", name)
+		}
+
+		body.WriteString("\n")
+		writeComment(&body, "", typeName+" представляет", s.Description)
+		fmt.Fprintf(&body, "type %s struct {\n", typeName)
+		required := make(map[string]bool)
+		for _, r := range s.Required {
+			required[r] = true
+		}
+		for _, prop := range s.Properties.Keys {
+			p := s.Properties.Values[prop]
+			fieldName := p.GoName
+			if fieldName == "" {
+				fieldName = FieldName(prop)
+			}
+			typ, err := g.goType(p)
+			if err != nil {
+				return nil, fmt.Errorf("схема %s, поле %s: %w", name, prop, err)
+			}
+			if strings.Contains(typ, "time.Time") {
+				usesTime = true
+			}
+			tag := prop
+			if p.GoOmitEmpty || (s.GoOmitEmpty && !required[prop]) {
+				tag += ",omitempty"
+			}
+			writeComment(&body, "\t", fieldName, p.Description)
+			fmt.Fprintf(&body, "\t%s %s `json:%q`\n", fieldName, typ, tag)
+		}
+		body.WriteString("}\n")
+	}
+
+	var out bytes.Buffer
+	out.WriteString(header + "package dbaas\n")
+	if usesTime {
+		out.WriteString("\nimport \"time\"\n")
+	}
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+// pathParam находит параметры пути вида {name}
+var pathParam = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// methodOrder задаёт порядок методов внутри пути
+var methodOrder = []string{"get", "post", "put", "patch", "delete"}
+
+// client генерирует методы Client для всех операций спецификации
+func (g *generator) client() ([]byte, error) {
+	var body bytes.Buffer
+	if g.spec.Paths.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("в спецификации нет путей")
+	}
+	for i := 0; i+1 < len(g.spec.Paths.Content); i += 2 {
+		path := g.spec.Paths.Content[i].Value
+		var operations map[string]*operation
+		if err := g.spec.Paths.Content[i+1].Decode(&operations); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, method := range methodOrder {
+			op, ok := operations[method]
+			if !ok {
+				continue
+			}
+			if err := g.method(&body, strings.ToUpper(method), path, op); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString(header + "package dbaas\n\n")
+	out.WriteString("import (\n\t\"context\"\n\t\"fmt\"\n\t\"net/http\"\n\t\"net/url\"\n)\n")
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+// method генерирует метод клиента для одной операции
+func (g *generator) method(out *bytes.Buffer, method, path string, op *operation) error {
+	if op.OperationID == "" {
+		return fmt.Errorf("не задан operationId")
+	}
+
+	// Параметры пути становятся аргументами метода в порядке их следования
+	args := []string{"ctx context.Context"}
+	format := path
+	var values []string
+	for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+		arg := ArgName(match[1])
+		args = append(args, arg+" string")
+		format = strings.Replace(format, match[0], "%s", 1)
+		values = append(values, "url.PathEscape("+arg+")")
+	}
+	pathExpr := strconv.Quote(format)
+	if len(values) > 0 {
+		pathExpr = fmt.Sprintf("fmt.Sprintf(%s, %s)", pathExpr, strings.Join(values, ", "))
+	}
+
+	bodyExpr := "nil"
+	if op.RequestBody != nil {
+		typ, err := g.mediaGoType(op.RequestBody.Content)
+		if err != nil {
+			return fmt.Errorf("тело запроса: %w", err)
+		}
+		args = append(args, "body "+typ)
+		bodyExpr = "body"
+	}
+
+	// Успешные коды ответа и тип их тела; все успешные ответы операции должны описывать одно тело
+	var statuses []string
+	var codes []string
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	if len(codes) == 0 {
+		return fmt.Errorf("не описан успешный ответ")
+	}
+	resultType := ""
+	for _, code := range codes {
+		resp, err := g.resolveResponse(op.Responses[code])
+		if err != nil {
+			return err
+		}
+		typ := ""
+		if len(resp.Content) > 0 {
+			if typ, err = g.mediaGoType(resp.Content); err != nil {
+				return fmt.Errorf("ответ %s: %w", code, err)
+			}
+		}
+		if code != codes[0] && typ != resultType {
+			return fmt.Errorf("успешные ответы описывают разные тела: %s и %s", resultType, typ)
+		}
+		resultType = typ
+		statuses = append(statuses, statusConstant(code))
+	}
+
+	out.WriteString("\n")
+	summary := op.Summary
+	if summary == "" {
+		summary = "выполняет запрос"
+	}
+	fmt.Fprintf(out, "// %s %s\n", op.OperationID, lowerFirst(summary))
+	fmt.Fprintf(out, "// (%s %s)\n", method, path)
+	statusList := strings.Join(statuses, ", ")
+	if resultType == "" {
+		fmt.Fprintf(out, "func (c *Client) %s(%s) error {\n", op.OperationID, strings.Join(args, ", "))
+		fmt.Fprintf(out, "\treturn c.do(ctx, %q, %s, %s, nil, %s)\n}\n", method, pathExpr, bodyExpr, statusList)
+		return nil
+	}
+	fmt.Fprintf(out, "func (c *Client) %s(%s) (%s, error) {\n", op.OperationID, strings.Join(args, ", "), resultType)
+	fmt.Fprintf(out, "\tvar result %s\n", resultType)
+	fmt.Fprintf(out, "\terr := c.do(ctx, %q, %s, %s, &result, %s)\n", method, pathExpr, bodyExpr, statusList)
+	out.WriteString("\treturn result, err\n}\n")
+	return nil
+}
+
+// resolveResponse разрешает ссылку на components/responses
+func (g *generator) resolveResponse(resp *response) (*response, error) {
+	if resp.Ref == "" {
+		return resp, nil
+	}
+	name := strings.TrimPrefix(resp.Ref, "#/components/responses/")
+	resolved, ok := g.spec.Components.Responses[name]
+	if !ok {
+		return nil, fmt.Errorf("не найден ответ %s", resp.Ref)
+	}
+	return resolved, nil
+}
+
+// mediaGoType возвращает тип Go для тела application/json
+func (g *generator) mediaGoType(content map[string]mediaType) (string, error) {
+	media, ok := content["application/json"]
+	if !ok || media.Schema == nil {
+		return "", fmt.Errorf("не описано тело application/json")
+	}
+	return g.goType(media.Schema)
+}
+
+// statusConstant возвращает константу net/http для кода ответа
+func statusConstant(code string) string {
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return code
+	}
+	name := strings.NewReplacer(" ", "", "-", "").Replace(http.StatusText(n))
+	if name == "" {
+		return code
+	}
+	return "http.Status" + name
+}
+
+// FieldName преобразует имя свойства в snake_case в имя поля Go: id -> Id, type_id -> TypeID
+func FieldName(name string) string {
+	parts := strings.Split(name, "_")
+	if len(parts) == 1 {
+		return upperFirst(name)
+	}
+	for i, part := range parts {
+		if part == "id" {
+			parts[i] = "ID"
+		} else {
+			parts[i] = upperFirst(part)
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// ArgName преобразует имя параметра пути в имя аргумента Go: cluster_id -> clusterID
+func ArgName(name string) string {
+	field := FieldName(name)
+	return strings.ToLower(field[:1]) + field[1:]
+}
+
+// writeComment выводит комментарий к типу или полю; первая строка начинается с prefix
+func writeComment(out *bytes.Buffer, indent, prefix, description string) {
+	if description == "" {
+		return
+	}
+	lines := strings.Split(strings.TrimSpace(description), "\n")
+	lines[0] = prefix + " " + lowerFirst(lines[0])
+	// Комментарии к типам, как и в остальном коде, заканчиваются точкой
+	if indent == "" && len(lines) == 1 && !strings.HasSuffix(lines[0], ".") {
+		lines[0] += "."
+	}
+	for _, line := range lines {
+		fmt.Fprintf(out, "%s// %s\n", indent, strings.TrimRight(line, " "))
+	}
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	return strings.ToUpper(string(r[0])) + string(r[1:])
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	return strings.ToLower(string(r[0])) + string(r[1:])
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"dbaas_testing_task/runstate"
+)
+
+// Журнал запуска включается переменной STATE_FILE. При RESUME=1 сценарии, разбитые на шаги,
+// продолжаются с первого незавершённого шага и переиспользуют ресурсы из журнала
+var (
+	stateFile = os.Getenv("STATE_FILE")
+	resume    = os.Getenv("RESUME") == "1"
+
+	runStateOnce sync.Once
+	runState     *runstate.State
+	runStateErr  error
+)
+
+// RunState возвращает журнал запуска или nil, если STATE_FILE не задан
+func RunState(t *testing.T) *runstate.State {
+	if stateFile == "" {
+		return nil
+	}
+	runStateOnce.Do(func() {
+		runState, runStateErr = runstate.Open(stateFile)
+	})
+	if runStateErr != nil {
+		Fatalf(t, "Журнал запуска недоступен: %v", runStateErr)
+	}
+	return runState
+}
+
+// JournalResource записывает созданный тестом ресурс в журнал запуска и отчёт
+func JournalResource(t *testing.T, kind, id, clusterId, name string) {
+	reportResource(t, kind, id, clusterId, name)
+	state := RunState(t)
+	if state == nil {
+		return
+	}
+	resource := runstate.Resource{Kind: kind, ID: id, ClusterID: clusterId, Name: name, Test: t.Name()}
+	if err := state.Record(resource); err != nil {
+		Errorf(t, "Не удалось записать %s в журнал запуска: %v", resource, err)
+	}
+}
+
+// ForgetResource убирает удалённый ресурс из журнала запуска; для кластера убираются и его базы данных,
+// пользователи и tablespace
+func ForgetResource(t *testing.T, kind, id string) {
+	state := RunState(t)
+	if state == nil {
+		return
+	}
+	if err := state.Forget(kind, id); err != nil {
+		Errorf(t, "Не удалось убрать %s %s из журнала запуска: %v", kind, id, err)
+	}
+}
+
+// Step выполняет шаг name сценария, записывает его в отчёт и отмечает в журнале как завершённый,
+// если fn не провалил тест.
+// При RESUME=1 шаг, завершённый в прерванном запуске, пропускается; в этом случае Step возвращает
+// false, и ID созданных шагом ресурсов нужно взять из журнала через JournaledID
+func Step(t *testing.T, name string, fn func()) bool {
+	state := RunState(t)
+	if state != nil && resume && state.StepCompleted(t.Name(), name) {
+		Logf(t, "Step %s already completed in the interrupted run, skipping", name)
+		reportSkippedStep(t, name)
+		return false
+	}
+	reportStepStart(t, name)
+	failedBefore, finished := t.Failed(), false
+	defer func() { reportStepEnd(t, finished, failedBefore) }()
+	fn()
+	finished = true
+	if state != nil && !t.Failed() {
+		if err := state.CompleteStep(t.Name(), name); err != nil {
+			Fatalf(t, "Не удалось отметить шаг %s в журнале запуска: %v", name, err)
+		}
+	}
+	return true
+}
+
+// JournaledID возвращает ID ресурса вида kind с именем name, созданного тестом в прерванном запуске
+func JournaledID(t *testing.T, kind, name string) string {
+	if state := RunState(t); state != nil {
+		if resource, ok := state.Find(t.Name(), kind, name); ok {
+			Logf(t, "Reusing %s from the interrupted run", resource)
+			return resource.ID
+		}
+	}
+	Fatalf(t, "%s %s не найден в журнале запуска, запустите тест без RESUME=1", kind, name)
+	return ""
+}
+
+// StartSteps готовит журнал к сценарию, разбитому на шаги: без RESUME=1 завершённые шаги
+// прерванного запуска забываются и сценарий начинается с начала
+func StartSteps(t *testing.T) {
+	reportTest(t)
+	state := RunState(t)
+	if state == nil || resume {
+		return
+	}
+	if err := state.ResetSteps(t.Name()); err != nil {
+		Fatalf(t, "Не удалось сбросить шаги в журнале запуска: %v", err)
+	}
+}
+
+// FinishSteps забывает шаги сценария, чтобы следующий запуск с RESUME=1 не пропустил их
+// после удаления ресурсов
+func FinishSteps(t *testing.T) {
+	state := RunState(t)
+	if state == nil {
+		return
+	}
+	if err := state.ResetSteps(t.Name()); err != nil {
+		Errorf(t, "Не удалось сбросить шаги в журнале запуска: %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain проверяет настройки SLO и включает запись или воспроизведение кассеты до запуска тестов,
+// а после их выполнения закрывает кассету, записывает отчёт и метрики и проверяет перцентильные пороги SLO
+func TestMain(m *testing.M) {
+	if err := loadSLOs(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	closeCassette, err := setupCassette()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	code := m.Run()
+	for _, finish := range []func() error{closeCassette, writeReports, finishMetrics} {
+		if err := finish(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			if code == 0 {
+				code = 1
+			}
+		}
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,590 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"dbaas_testing_task/dbaas"
+)
+
+// Операции плана
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+	OpSeed   = "seed"
+)
+
+// Виды ресурсов
+const (
+	KindCluster    = "cluster"
+	KindParameters = "parameters"
+	KindDatabase   = "database"
+	KindUser       = "user"
+)
+
+// Action описывает одно изменение, необходимое для приведения API к манифесту
+type Action struct {
+	Op      string   `json:"op"`
+	Kind    string   `json:"kind"`
+	Cluster string   `json:"cluster"`
+	Name    string   `json:"name"`
+	Changes []string `json:"changes,omitempty"`
+
+	run func(ctx context.Context) error
+}
+
+func (a Action) String() string {
+	s := fmt.Sprintf("%s %s %s", a.Op, a.Kind, a.Name)
+	if a.Kind != KindCluster {
+		s = fmt.Sprintf("%s %s %s/%s", a.Op, a.Kind, a.Cluster, a.Name)
+	}
+	if len(a.Changes) > 0 {
+		s += " (" + strings.Join(a.Changes, ", ") + ")"
+	}
+	return s
+}
+
+// Seeder выполняет SQL-запросы в базе данных по строке подключения
+type Seeder func(ctx context.Context, dsn string, statements []string) error
+
+// SeedChecker проверяет по строке подключения, загружены ли в базу данных начальные данные
+type SeedChecker func(ctx context.Context, dsn string) (bool, error)
+
+// Engine сравнивает манифест с состоянием API и применяет изменения
+type Engine struct {
+	Client *dbaas.Client
+	// Interval задаёт интервал опроса статуса ресурсов
+	Interval time.Duration
+	// Prune разрешает удалять базы данных и пользователей кластеров из манифеста, которых в нём нет.
+	// Кластеры, не описанные в манифесте, не затрагиваются никогда
+	Prune bool
+	// Seed загружает начальные данные; по умолчанию используется PgSeed
+	Seed Seeder
+	// Seeded проверяет, загружены ли начальные данные; по умолчанию используется PgSeeded
+	Seeded SeedChecker
+	// Log получает сообщение о каждом выполненном действии
+	Log func(action Action)
+}
+
+// clusterRef хранит ID кластера, который становится известен только после его создания
+type clusterRef struct {
+	id string
+}
+
+// Plan возвращает действия, необходимые для приведения API к манифесту
+func (e *Engine) Plan(ctx context.Context, m *Manifest) ([]Action, error) {
+	var plan []Action
+	for _, spec := range m.Clusters {
+		actions, err := e.planCluster(ctx, spec)
+		if err != nil {
+			return nil, fmt.Errorf("кластер %s: %w", spec.Name, err)
+		}
+		plan = append(plan, actions...)
+	}
+	return plan, nil
+}
+
+// Apply вычисляет план и выполняет его. Возвращает выполненные действия, в том числе при ошибке
+func (e *Engine) Apply(ctx context.Context, m *Manifest) ([]Action, error) {
+	plan, err := e.Plan(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	return e.run(ctx, plan)
+}
+
+// PlanDestroy возвращает действия для удаления кластеров манифеста, существующих в API
+func (e *Engine) PlanDestroy(ctx context.Context, m *Manifest) ([]Action, error) {
+	var plan []Action
+	for i := len(m.Clusters) - 1; i >= 0; i-- {
+		name := m.Clusters[i].Name
+		live, err := e.Client.FindCluster(ctx, name)
+		if dbaas.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, e.deleteCluster(live))
+	}
+	return plan, nil
+}
+
+// Destroy удаляет кластеры манифеста вместе с их базами данных и пользователями
+func (e *Engine) Destroy(ctx context.Context, m *Manifest) ([]Action, error) {
+	plan, err := e.PlanDestroy(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+	return e.run(ctx, plan)
+}
+
+func (e *Engine) run(ctx context.Context, plan []Action) ([]Action, error) {
+	for i, action := range plan {
+		if err := action.run(ctx); err != nil {
+			return plan[:i], fmt.Errorf("%s: %w", action, err)
+		}
+		if e.Log != nil {
+			e.Log(action)
+		}
+	}
+	return plan, nil
+}
+
+func (e *Engine) interval() time.Duration {
+	if e.Interval > 0 {
+		return e.Interval
+	}
+	return dbaas.DefaultPollInterval
+}
+
+func (e *Engine) planCluster(ctx context.Context, spec ClusterSpec) ([]Action, error) {
+	live, err := e.Client.FindCluster(ctx, spec.Name)
+	if dbaas.IsNotFound(err) {
+		return e.planNewCluster(ctx, spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ref := &clusterRef{id: live.Id}
+
+	var plan []Action
+	update, changes, err := e.diffCluster(ctx, spec, live)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) > 0 {
+		plan = append(plan, Action{Op: OpUpdate, Kind: KindCluster, Cluster: spec.Name, Name: spec.Name, Changes: changes,
+			run: func(ctx context.Context) error {
+				if _, err := e.Client.UpdateCluster(ctx, ref.id, update); err != nil {
+					return err
+				}
+				_, err := e.Client.WaitCluster(ctx, ref.id, dbaas.StatusOK, e.interval())
+				return err
+			}})
+	}
+
+	if len(spec.Parameters) > 0 {
+		parameters, err := e.Client.ListClusterParameters(ctx, live.Id)
+		if err != nil {
+			return nil, err
+		}
+		current := make(map[string]string)
+		for _, p := range parameters {
+			current[p.Name] = p.Value
+		}
+		changed := make(map[string]string)
+		for name, value := range spec.Parameters {
+			if current[name] != value {
+				changed[name] = value
+			}
+		}
+		if len(changed) > 0 {
+			plan = append(plan, e.updateParameters(spec.Name, ref, changed, current))
+		}
+	}
+
+	users, err := e.Client.ListClusterUsers(ctx, live.Id)
+	if err != nil {
+		return nil, err
+	}
+	liveUsers := make(map[string]dbaas.ClusterUser)
+	for _, u := range users {
+		liveUsers[u.Name] = u
+	}
+
+	databases, err := e.Client.ListDatabases(ctx, live.Id)
+	if err != nil {
+		return nil, err
+	}
+	liveDatabases := make(map[string]dbaas.Database)
+	for _, d := range databases {
+		liveDatabases[d.Name] = d
+	}
+	var seeds []Action
+	for _, d := range spec.Databases {
+		current, ok := liveDatabases[d.Name]
+		if !ok {
+			plan = append(plan, e.createDatabase(spec.Name, ref, d))
+			if d.Seed != nil {
+				seeds = append(seeds, e.seedDatabase(spec, ref, d))
+			}
+			continue
+		}
+		action, err := e.diffDatabase(ctx, spec.Name, ref, d, current)
+		if err != nil {
+			return nil, fmt.Errorf("база данных %s: %w", d.Name, err)
+		}
+		if action != nil {
+			plan = append(plan, *action)
+		}
+		if d.Seed != nil && e.seedMissing(ctx, spec, d, current, liveUsers) {
+			seeds = append(seeds, e.seedDatabase(spec, ref, d))
+		}
+	}
+
+	for _, u := range spec.Users {
+		current, ok := liveUsers[u.Name]
+		if !ok {
+			plan = append(plan, e.createUser(spec.Name, ref, u))
+			continue
+		}
+		if action := e.diffUser(spec.Name, ref, u, current); action != nil {
+			plan = append(plan, *action)
+		}
+	}
+	plan = append(plan, seeds...)
+
+	if e.Prune {
+		// Пользователи удаляются раньше баз данных, к которым у них есть доступ
+		for _, u := range users {
+			if _, ok := spec.user(u.Name); !ok {
+				plan = append(plan, e.deleteUser(spec.Name, ref, u))
+			}
+		}
+		for _, d := range databases {
+			if !specHasDatabase(spec, d.Name) {
+				plan = append(plan, e.deleteDatabase(spec.Name, ref, d))
+			}
+		}
+	}
+	return plan, nil
+}
+
+// planNewCluster возвращает действия для создания кластера и всего его содержимого
+func (e *Engine) planNewCluster(ctx context.Context, spec ClusterSpec) ([]Action, error) {
+	request, err := e.clusterRequest(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	ref := &clusterRef{}
+	plan := []Action{{Op: OpCreate, Kind: KindCluster, Cluster: spec.Name, Name: spec.Name,
+		run: func(ctx context.Context) error {
+			response, err := e.Client.CreateCluster(ctx, request)
+			if err != nil {
+				return err
+			}
+			if len(response.Instances) == 0 {
+				return fmt.Errorf("в ответе на создание кластера нет ни одного экземпляра")
+			}
+			ref.id = response.Instances[0].ClusterID
+			_, err = e.Client.WaitCluster(ctx, ref.id, dbaas.StatusOK, e.interval())
+			return err
+		}}}
+	if len(spec.Parameters) > 0 {
+		plan = append(plan, e.updateParameters(spec.Name, ref, spec.Parameters, nil))
+	}
+	var seeds []Action
+	for _, d := range spec.Databases {
+		plan = append(plan, e.createDatabase(spec.Name, ref, d))
+		if d.Seed != nil {
+			seeds = append(seeds, e.seedDatabase(spec, ref, d))
+		}
+	}
+	for _, u := range spec.Users {
+		plan = append(plan, e.createUser(spec.Name, ref, u))
+	}
+	return append(plan, seeds...), nil
+}
+
+// clusterRequest строит запрос на создание кластера по описанию
+func (e *Engine) clusterRequest(ctx context.Context, spec ClusterSpec) (dbaas.CreateClusterRequest, error) {
+	flavor, err := e.Client.FindFlavor(ctx, valueOr(spec.Flavor, dbaas.DefaultFlavor))
+	if err != nil {
+		return dbaas.CreateClusterRequest{}, err
+	}
+	typ, err := e.Client.FindType(ctx, valueOr(spec.Type, dbaas.DefaultTypeVersion))
+	if err != nil {
+		return dbaas.CreateClusterRequest{}, err
+	}
+	request := dbaas.NewClusterRequest(spec.Name, flavor.Id, typ.Id)
+	if spec.DiskSize > 0 {
+		request.DiskSize = spec.DiskSize
+	}
+	if spec.Replicas != nil {
+		request.ReplicasCount = *spec.Replicas
+	}
+	if spec.Az != "" {
+		request.Az = spec.Az
+	}
+	spec.Options.apply(&request.Options)
+	return request, nil
+}
+
+// diffCluster сравнивает кластер с описанием и возвращает запрос на изменение и список изменений
+func (e *Engine) diffCluster(ctx context.Context, spec ClusterSpec, live dbaas.Cluster) (dbaas.UpdateClusterRequest, []string, error) {
+	var update dbaas.UpdateClusterRequest
+	var changes []string
+	if spec.Flavor != "" {
+		flavor, err := e.Client.FindFlavor(ctx, spec.Flavor)
+		if err != nil {
+			return update, nil, err
+		}
+		if flavor.Id != live.FlavorID {
+			update.FlavorID = flavor.Id
+			changes = append(changes, fmt.Sprintf("flavor: %s -> %s", live.FlavorID, flavor.Name))
+		}
+	}
+	if spec.DiskSize > 0 && spec.DiskSize != live.DiskSize {
+		if spec.DiskSize < live.DiskSize {
+			return update, nil, fmt.Errorf("уменьшение диска с %d до %d байт не поддерживается", live.DiskSize, spec.DiskSize)
+		}
+		update.DiskSize = spec.DiskSize
+		changes = append(changes, fmt.Sprintf("disk_size: %d -> %d", live.DiskSize, spec.DiskSize))
+	}
+	if spec.Replicas != nil && *spec.Replicas != live.ReplicasCount {
+		replicas := *spec.Replicas
+		update.ReplicasCount = &replicas
+		changes = append(changes, fmt.Sprintf("replicas: %d -> %d", live.ReplicasCount, replicas))
+	}
+	options := live.Options
+	if optionChanges := spec.Options.apply(&options); len(optionChanges) > 0 {
+		update.Options = &options
+		changes = append(changes, optionChanges...)
+	}
+	return update, changes, nil
+}
+
+func (e *Engine) updateParameters(cluster string, ref *clusterRef, changed, current map[string]string) Action {
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var changes []string
+	for _, name := range names {
+		if old, ok := current[name]; ok {
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, old, changed[name]))
+		} else {
+			changes = append(changes, fmt.Sprintf("%s: %s", name, changed[name]))
+		}
+	}
+	return Action{Op: OpUpdate, Kind: KindParameters, Cluster: cluster, Name: "parameters", Changes: changes,
+		run: func(ctx context.Context) error {
+			if _, err := e.Client.UpdateClusterParameters(ctx, ref.id, dbaas.UpdateClusterParametersRequest{Parameters: changed}); err != nil {
+				return err
+			}
+			_, err := e.Client.WaitCluster(ctx, ref.id, dbaas.StatusOK, e.interval())
+			return err
+		}}
+}
+
+func (e *Engine) createDatabase(cluster string, ref *clusterRef, spec DatabaseSpec) Action {
+	return Action{Op: OpCreate, Kind: KindDatabase, Cluster: cluster, Name: spec.Name,
+		run: func(ctx context.Context) error {
+			tableSpace, err := e.Client.FindTableSpace(ctx, ref.id, spec.TableSpace)
+			if err != nil {
+				return err
+			}
+			response, err := e.Client.CreateDatabase(ctx, ref.id, dbaas.CreateDBRequest{
+				Name:         spec.Name,
+				TableSpaceID: tableSpace.Id,
+				Owner:        spec.Owner,
+				Encoding:     spec.Encoding,
+				LcCollate:    spec.LcCollate,
+				LcCtype:      spec.LcCtype,
+			})
+			if err != nil {
+				return err
+			}
+			_, err = e.Client.WaitDatabase(ctx, ref.id, response.Id, e.interval())
+			return err
+		}}
+}
+
+// diffDatabase сравнивает базу данных с описанием. Кодировка и локаль задаются только при создании
+func (e *Engine) diffDatabase(ctx context.Context, cluster string, ref *clusterRef, spec DatabaseSpec, live dbaas.Database) (*Action, error) {
+	for _, immutable := range []struct{ name, want, got string }{
+		{"encoding", spec.Encoding, live.Encoding},
+		{"lc_collate", spec.LcCollate, live.LcCollate},
+		{"lc_ctype", spec.LcCtype, live.LcCtype},
+	} {
+		if immutable.want != "" && immutable.got != "" && !strings.EqualFold(immutable.want, immutable.got) {
+			return nil, fmt.Errorf("%s нельзя изменить (%s -> %s), базу данных нужно пересоздать", immutable.name, immutable.got, immutable.want)
+		}
+	}
+
+	var update dbaas.UpdateDBRequest
+	var changes []string
+	if spec.Owner != "" && spec.Owner != live.Owner {
+		update.Owner = spec.Owner
+		changes = append(changes, fmt.Sprintf("owner: %s -> %s", live.Owner, spec.Owner))
+	}
+	if spec.TableSpace != "" {
+		tableSpace, err := e.Client.FindTableSpace(ctx, ref.id, spec.TableSpace)
+		if err != nil {
+			return nil, err
+		}
+		if tableSpace.Id != live.TableSpaceID {
+			update.TableSpaceID = tableSpace.Id
+			changes = append(changes, fmt.Sprintf("tablespace: %s -> %s", live.TableSpaceID, tableSpace.Name))
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return &Action{Op: OpUpdate, Kind: KindDatabase, Cluster: cluster, Name: spec.Name, Changes: changes,
+		run: func(ctx context.Context) error {
+			if _, err := e.Client.UpdateDatabase(ctx, ref.id, live.Id, update); err != nil {
+				return err
+			}
+			_, err := e.Client.WaitDatabase(ctx, ref.id, live.Id, e.interval())
+			return err
+		}}, nil
+}
+
+func (e *Engine) deleteDatabase(cluster string, ref *clusterRef, live dbaas.Database) Action {
+	return Action{Op: OpDelete, Kind: KindDatabase, Cluster: cluster, Name: live.Name,
+		run: func(ctx context.Context) error {
+			return e.Client.DeleteDatabase(ctx, ref.id, live.Id)
+		}}
+}
+
+func (e *Engine) createUser(cluster string, ref *clusterRef, spec UserSpec) Action {
+	return Action{Op: OpCreate, Kind: KindUser, Cluster: cluster, Name: spec.Name,
+		run: func(ctx context.Context) error {
+			user, err := e.Client.CreateClusterUser(ctx, ref.id, dbaas.CreateClusterUserRequest{
+				Databases: nonNil(spec.Databases),
+				Roles:     nonNil(spec.Roles),
+				Name:      spec.Name,
+				Password:  spec.Password,
+			})
+			if err != nil {
+				return err
+			}
+			_, err = e.Client.WaitClusterUser(ctx, ref.id, user.Id, e.interval())
+			return err
+		}}
+}
+
+// diffUser сравнивает роли и базы данных пользователя с описанием без учёта порядка
+func (e *Engine) diffUser(cluster string, ref *clusterRef, spec UserSpec, live dbaas.ClusterUser) *Action {
+	var changes []string
+	if !sameSet(spec.Databases, live.Databases) {
+		changes = append(changes, fmt.Sprintf("databases: [%s] -> [%s]", strings.Join(live.Databases, ","), strings.Join(spec.Databases, ",")))
+	}
+	if !sameSet(spec.Roles, live.Roles) {
+		changes = append(changes, fmt.Sprintf("roles: [%s] -> [%s]", strings.Join(live.Roles, ","), strings.Join(spec.Roles, ",")))
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return &Action{Op: OpUpdate, Kind: KindUser, Cluster: cluster, Name: spec.Name, Changes: changes,
+		run: func(ctx context.Context) error {
+			databases, roles := nonNil(spec.Databases), nonNil(spec.Roles)
+			update := dbaas.UpdateClusterUserRequest{Databases: &databases, Roles: &roles}
+			if _, err := e.Client.UpdateClusterUser(ctx, ref.id, live.Id, update); err != nil {
+				return err
+			}
+			_, err := e.Client.WaitClusterUser(ctx, ref.id, live.Id, e.interval())
+			return err
+		}}
+}
+
+func (e *Engine) deleteUser(cluster string, ref *clusterRef, live dbaas.ClusterUser) Action {
+	return Action{Op: OpDelete, Kind: KindUser, Cluster: cluster, Name: live.Name,
+		run: func(ctx context.Context) error {
+			return e.Client.DeleteClusterUser(ctx, ref.id, live.Id)
+		}}
+}
+
+// seedDatabase загружает начальные данные от имени пользователя из описания
+// seedMissing сообщает, нужно ли загрузить начальные данные в существующую базу данных.
+// Если проверить не удалось, загрузка планируется снова: PgSeed не повторяет уже выполненную загрузку
+func (e *Engine) seedMissing(ctx context.Context, spec ClusterSpec, database DatabaseSpec, live dbaas.Database, liveUsers map[string]dbaas.ClusterUser) bool {
+	if _, ok := liveUsers[database.Seed.User]; !ok {
+		return true
+	}
+	user, _ := spec.user(database.Seed.User)
+	seeded := e.Seeded
+	if seeded == nil {
+		seeded = PgSeeded
+	}
+	done, err := seeded(ctx, ConnectionString(live, user.Name, user.Password))
+	return err != nil || !done
+}
+
+func (e *Engine) seedDatabase(spec ClusterSpec, ref *clusterRef, database DatabaseSpec) Action {
+	return Action{Op: OpSeed, Kind: KindDatabase, Cluster: spec.Name, Name: database.Name,
+		Changes: []string{fmt.Sprintf("%d SQL-запросов от имени %s", len(database.Seed.SQL), database.Seed.User)},
+		run: func(ctx context.Context) error {
+			databases, err := e.Client.ListDatabases(ctx, ref.id)
+			if err != nil {
+				return err
+			}
+			var live *dbaas.Database
+			for i := range databases {
+				if databases[i].Name == database.Name {
+					live = &databases[i]
+				}
+			}
+			if live == nil {
+				return fmt.Errorf("база данных %s %w", database.Name, dbaas.ErrNotFound)
+			}
+			user, _ := spec.user(database.Seed.User)
+			seed := e.Seed
+			if seed == nil {
+				seed = PgSeed
+			}
+			return seed(ctx, ConnectionString(*live, user.Name, user.Password), database.Seed.SQL)
+		}}
+}
+
+func (e *Engine) deleteCluster(live dbaas.Cluster) Action {
+	return Action{Op: OpDelete, Kind: KindCluster, Cluster: live.Name, Name: live.Name,
+		run: func(ctx context.Context) error {
+			if err := e.Client.DeleteCluster(ctx, live.Id); err != nil && !dbaas.IsNotFound(err) {
+				return err
+			}
+			return dbaas.WaitDeleted(ctx, e.interval(), func(ctx context.Context) error {
+				_, err := e.Client.GetCluster(ctx, live.Id)
+				return err
+			})
+		}}
+}
+
+// ConnectionString подставляет имя пользователя и пароль в строку подключения базы данных
+func ConnectionString(database dbaas.Database, user, password string) string {
+	dsn := strings.Replace(database.MasterConnectionString, "<username>", user, 1)
+	return strings.Replace(dsn, "<password>", password, 1)
+}
+
+func specHasDatabase(spec ClusterSpec, name string) bool {
+	for _, d := range spec.Databases {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, item := range a {
+		if !contains(b, item) {
+			return false
+		}
+	}
+	return true
+}
+
+// nonNil заменяет nil пустым списком, чтобы в запросе было поле [] вместо null
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
+
+func valueOr(value, fallback string) string {
+	if value != "" {
+		return value
+	}
+	return fallback
+}
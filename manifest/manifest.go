@@ -0,0 +1,259 @@
+// Package manifest описывает тестовое окружение в YAML и приводит к нему состояние API.
+//
+// Манифест перечисляет кластеры с параметрами Postgres, базами данных, пользователями и
+// начальными данными. Engine сравнивает манифест с состоянием API и создаёт, изменяет или
+// удаляет ресурсы так, чтобы повторное применение того же манифеста ничего не меняло.
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+
+	"dbaas_testing_task/dbaas"
+)
+
+// Manifest описывает окружение
+type Manifest struct {
+	Clusters []ClusterSpec `yaml:"clusters"`
+}
+
+// ClusterSpec описывает кластер. Незаданные поля получают значения по умолчанию при создании
+// и не сравниваются с существующим кластером
+type ClusterSpec struct {
+	Name string `yaml:"name"`
+	// Flavor задаётся именем или ID
+	Flavor string `yaml:"flavor"`
+	// Type задаётся версией или ID и используется только при создании
+	Type       string            `yaml:"type"`
+	DiskSize   int64             `yaml:"disk_size"`
+	Replicas   *int              `yaml:"replicas"`
+	Az         string            `yaml:"az"`
+	Options    OptionsSpec       `yaml:"options"`
+	Parameters map[string]string `yaml:"parameters"`
+	Databases  []DatabaseSpec    `yaml:"databases"`
+	Users      []UserSpec        `yaml:"users"`
+}
+
+// OptionsSpec описывает опции кластера; сравниваются и изменяются только заданные
+type OptionsSpec struct {
+	MaximumLagOnFailover  *int  `yaml:"maximum_lag_on_failover"`
+	WalArchiveMode        *bool `yaml:"wal_archive_mode"`
+	AutoRestart           *bool `yaml:"auto_restart"`
+	Production            *bool `yaml:"production"`
+	EnableSynchronousMode *bool `yaml:"enable_synchronous_mode"`
+	DisableAutofailover   *bool `yaml:"disable_autofailover"`
+}
+
+// DatabaseSpec описывает базу данных кластера
+type DatabaseSpec struct {
+	Name  string `yaml:"name"`
+	Owner string `yaml:"owner"`
+	// TableSpace задаётся именем или ID; по умолчанию используется tablespace с флагом default
+	TableSpace string `yaml:"tablespace"`
+	Encoding   string `yaml:"encoding"`
+	LcCollate  string `yaml:"lc_collate"`
+	LcCtype    string `yaml:"lc_ctype"`
+	// Seed выполняется после создания базы данных и повторяется при следующих apply, пока загрузка не завершится успешно
+	Seed *SeedSpec `yaml:"seed"`
+}
+
+// SeedSpec описывает начальные данные: SQL-запросы, выполняемые от имени пользователя из манифеста
+type SeedSpec struct {
+	User string   `yaml:"user"`
+	SQL  []string `yaml:"sql"`
+}
+
+// UserSpec описывает пользователя кластера. Пароль задаётся только при создании
+type UserSpec struct {
+	Name      string   `yaml:"name"`
+	Password  string   `yaml:"password"`
+	Databases []string `yaml:"databases"`
+	Roles     []string `yaml:"roles"`
+}
+
+// envReference - ссылка на переменную окружения в манифесте. Другие формы с $ (параметры $1
+// и строки $$ в SQL, $ в паролях) остаются как есть
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Load читает манифест из файла. Ссылки вида ${NAME} в строковых значениях заменяются значениями
+// переменных окружения уже после разбора YAML, поэтому символы #, :, ! и другие в значении
+// переменной не меняют структуру манифеста
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать манифест: %w", err)
+	}
+	m, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	expandEnv(reflect.ValueOf(m).Elem())
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// expandEnv заменяет ссылки ${NAME} значениями переменных окружения во всех строках v
+func expandEnv(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(envReference.ReplaceAllStringFunc(v.String(), func(ref string) string {
+			return os.Getenv(envReference.FindStringSubmatch(ref)[1])
+		}))
+	case reflect.Ptr:
+		if !v.IsNil() {
+			expandEnv(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			expandEnv(v.Field(i))
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			expandEnv(v.Index(i))
+		}
+	case reflect.Map:
+		// Значения карты неадресуемы: меняется копия, которая записывается обратно
+		iter := v.MapRange()
+		for iter.Next() {
+			value := reflect.New(iter.Value().Type()).Elem()
+			value.Set(iter.Value())
+			expandEnv(value)
+			v.SetMapIndex(iter.Key(), value)
+		}
+	}
+}
+
+// Parse разбирает и проверяет манифест
+func Parse(data []byte) (*Manifest, error) {
+	m, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// decode разбирает манифест без проверки
+func decode(data []byte) (*Manifest, error) {
+	var m Manifest
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	// Опечатка в имени поля не должна молча превращаться в значение по умолчанию
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&m); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать манифест: %w", err)
+	}
+	return &m, nil
+}
+
+// Validate проверяет обязательные поля, уникальность имён и ссылки между ресурсами
+func (m *Manifest) Validate() error {
+	clusters := make(map[string]bool)
+	for _, c := range m.Clusters {
+		if c.Name == "" {
+			return fmt.Errorf("у кластера не задано имя")
+		}
+		if clusters[c.Name] {
+			return fmt.Errorf("кластер %s описан дважды", c.Name)
+		}
+		clusters[c.Name] = true
+		if c.DiskSize < 0 || c.Replicas != nil && *c.Replicas < 0 {
+			return fmt.Errorf("кластер %s: размер диска и число реплик не могут быть отрицательными", c.Name)
+		}
+
+		databases := make(map[string]bool)
+		for _, d := range c.Databases {
+			if d.Name == "" {
+				return fmt.Errorf("кластер %s: у базы данных не задано имя", c.Name)
+			}
+			if databases[d.Name] {
+				return fmt.Errorf("кластер %s: база данных %s описана дважды", c.Name, d.Name)
+			}
+			databases[d.Name] = true
+		}
+
+		users := make(map[string]UserSpec)
+		for _, u := range c.Users {
+			if u.Name == "" {
+				return fmt.Errorf("кластер %s: у пользователя не задано имя", c.Name)
+			}
+			if _, ok := users[u.Name]; ok {
+				return fmt.Errorf("кластер %s: пользователь %s описан дважды", c.Name, u.Name)
+			}
+			if u.Password == "" {
+				return fmt.Errorf("кластер %s: у пользователя %s не задан пароль", c.Name, u.Name)
+			}
+			for _, name := range u.Databases {
+				if !databases[name] {
+					return fmt.Errorf("кластер %s: пользователю %s назначена неописанная база данных %s", c.Name, u.Name, name)
+				}
+			}
+			users[u.Name] = u
+		}
+
+		for _, d := range c.Databases {
+			if d.Seed == nil {
+				continue
+			}
+			user, ok := users[d.Seed.User]
+			if !ok {
+				return fmt.Errorf("кластер %s: начальные данные базы %s загружаются неописанным пользователем %q", c.Name, d.Name, d.Seed.User)
+			}
+			if !contains(user.Databases, d.Name) {
+				return fmt.Errorf("кластер %s: пользователь %s не имеет доступа к базе %s для загрузки начальных данных", c.Name, user.Name, d.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// user возвращает описание пользователя кластера по имени
+func (c ClusterSpec) user(name string) (UserSpec, bool) {
+	for _, u := range c.Users {
+		if u.Name == name {
+			return u, true
+		}
+	}
+	return UserSpec{}, false
+}
+
+// apply накладывает заданные опции на options и возвращает список изменений
+func (o OptionsSpec) apply(options *dbaas.Options) []string {
+	var changes []string
+	setInt := func(name string, want *int, current *int) {
+		if want != nil && *want != *current {
+			changes = append(changes, fmt.Sprintf("%s: %d -> %d", name, *current, *want))
+			*current = *want
+		}
+	}
+	setBool := func(name string, want *bool, current *bool) {
+		if want != nil && *want != *current {
+			changes = append(changes, fmt.Sprintf("%s: %t -> %t", name, *current, *want))
+			*current = *want
+		}
+	}
+	setInt("maximum_lag_on_failover", o.MaximumLagOnFailover, &options.MaximumLagOnFailover)
+	setBool("wal_archive_mode", o.WalArchiveMode, &options.WalArchiveMode)
+	setBool("auto_restart", o.AutoRestart, &options.AutoRestart)
+	setBool("production", o.Production, &options.Production)
+	setBool("enable_synchronous_mode", o.EnableSynchronousMode, &options.EnableSynchronousMode)
+	setBool("disable_autofailover", o.DisableAutofailover, &options.DisableAutofailover)
+	return changes
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package manifest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"dbaas_testing_task/dbaas"
+	"dbaas_testing_task/dbaas/dbaastest"
+)
+
+func TestParseValidation(t *testing.T) {
+	cases := []struct {
+		name     string
+		manifest string
+		err      string
+	}{
+		{"missing cluster name", `clusters: [{flavor: STD3-1-1}]`, "не задано имя"},
+		{"duplicate cluster", `clusters: [{name: a}, {name: a}]`, "описан дважды"},
+		{"duplicate database", `clusters: [{name: a, databases: [{name: d}, {name: d}]}]`, "описана дважды"},
+		{"user without password", `clusters: [{name: a, users: [{name: u}]}]`, "не задан пароль"},
+		{"user with unknown database", `clusters: [{name: a, users: [{name: u, password: p, databases: [x]}]}]`, "неописанная база данных"},
+		{"seed by unknown user", `clusters: [{name: a, databases: [{name: d, seed: {user: u}}]}]`, "неописанным пользователем"},
+		{"seed without access", `clusters: [{name: a, databases: [{name: d, seed: {user: u}}], users: [{name: u, password: p}]}]`, "не имеет доступа"},
+		{"unknown field", `clusters: [{name: a, flavour: x}]`, "flavour"},
+		{"valid", `clusters: [{name: a, databases: [{name: d}]}]`, ""},
+	}
+	for _, tc := range cases {
+		_, err := Parse([]byte(tc.manifest))
+		if tc.err == "" {
+			assert.NoError(t, err, tc.name)
+			continue
+		}
+		if assert.Error(t, err, tc.name) {
+			assert.Contains(t, err.Error(), tc.err, tc.name)
+		}
+	}
+}
+
+func TestLoadExpandsOnlyEnvReferences(t *testing.T) {
+	// Значение подставляется после разбора YAML: комментарий, ключ, тег и якорь в нём остаются текстом
+	t.Setenv("APP_PASSWORD", "!se #cr: *et&")
+	t.Setenv("1", "expanded")
+	path := filepath.Join(t.TempDir(), "env.yaml")
+	err := os.WriteFile(path, []byte(`
+clusters:
+  - name: demo
+    parameters:
+      search_path: '"$user", public'
+      application_name: ${APP_PASSWORD}
+    databases:
+      - name: app
+        seed:
+          user: app
+          sql:
+            - INSERT INTO items (name) VALUES ($1)
+            - CREATE FUNCTION one() RETURNS int AS $$ SELECT 1 $$ LANGUAGE sql
+    users:
+      - name: app
+        password: p$ss-${APP_PASSWORD}
+        databases: [app]
+`), 0o600)
+	assert.NoError(t, err)
+
+	m, err := Load(path)
+	if err != nil {
+		t.Fatalf("Не удалось загрузить манифест: %v", err)
+	}
+	cluster := m.Clusters[0]
+	assert.Equal(t, []string{
+		"INSERT INTO items (name) VALUES ($1)",
+		"CREATE FUNCTION one() RETURNS int AS $$ SELECT 1 $$ LANGUAGE sql",
+	}, cluster.Databases[0].Seed.SQL)
+	assert.Equal(t, "p$ss-!se #cr: *et&", cluster.Users[0].Password)
+	assert.Equal(t, `"$user", public`, cluster.Parameters["search_path"])
+	assert.Equal(t, "!se #cr: *et&", cluster.Parameters["application_name"])
+}
+
+func TestApplyAndDestroy(t *testing.T) {
+	t.Setenv("APP_PASSWORD", "secret")
+	m, err := Load("testdata/environment.yaml")
+	if err != nil {
+		t.Fatalf("Не удалось загрузить манифест: %v", err)
+	}
+	assert.Equal(t, "secret", m.Clusters[0].Users[0].Password, "переменные окружения не подставлены")
+
+	server := dbaastest.NewServer(t)
+	var seeded []string
+	engine := &Engine{
+		Client:   server.Client(),
+		Interval: 1,
+		Seed: func(ctx context.Context, dsn string, statements []string) error {
+			seeded = append(seeded, dsn)
+			return nil
+		},
+		Seeded: func(ctx context.Context, dsn string) (bool, error) {
+			return len(seeded) > 0, nil
+		},
+	}
+	ctx := context.Background()
+
+	applied, err := engine.Apply(ctx, m)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"create cluster demo",
+		"update parameters demo/parameters (work_mem: 8MB)",
+		"create database demo/app",
+		"create database demo/reports",
+		"create user demo/app",
+		"create user demo/analyst",
+		"seed database demo/app (2 SQL-запросов от имени app)",
+	}, actionStrings(applied))
+	cluster, ok := server.Cluster("demo")
+	if !ok {
+		t.Fatal("Кластер не создан")
+	}
+	assert.True(t, cluster.Options.AutoRestart)
+	assert.Equal(t, "8MB", server.Parameters(cluster.Id)["work_mem"])
+	assert.Len(t, server.Databases(cluster.Id), 2)
+	assert.Len(t, server.Users(cluster.Id), 2)
+	if assert.Len(t, seeded, 1) {
+		assert.True(t, strings.HasPrefix(seeded[0], "postgres://app:secret@"), seeded[0])
+	}
+
+	// Повторное применение ничего не меняет
+	plan, err := engine.Plan(ctx, m)
+	assert.NoError(t, err)
+	assert.Empty(t, plan)
+
+	// Изменения манифеста применяются к существующим ресурсам
+	replicas := 2
+	m.Clusters[0].Replicas = &replicas
+	m.Clusters[0].Flavor = "STD3-2-4"
+	m.Clusters[0].Parameters["work_mem"] = "16MB"
+	m.Clusters[0].Users[1].Roles = []string{"pg_read_all_data", "pg_monitor"}
+	m.Clusters[0].Databases = m.Clusters[0].Databases[:1]
+	m.Clusters[0].Users[1].Databases = []string{"app"}
+	engine.Prune = true
+	applied, err = engine.Apply(ctx, m)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"update cluster demo (flavor: flavor-1 -> STD3-2-4, replicas: 1 -> 2)",
+		"update parameters demo/parameters (work_mem: 8MB -> 16MB)",
+		"update user demo/analyst (databases: [app,reports] -> [app], roles: [pg_read_all_data] -> [pg_read_all_data,pg_monitor])",
+		"delete database demo/reports",
+	}, actionStrings(applied))
+	cluster, _ = server.Cluster("demo")
+	assert.Equal(t, 2, cluster.ReplicasCount)
+	assert.Len(t, server.Databases(cluster.Id), 1)
+
+	// Пустой список ролей отзывает все роли, после чего план снова пуст
+	m.Clusters[0].Users[1].Roles = nil
+	applied, err = engine.Apply(ctx, m)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"update user demo/analyst (roles: [pg_read_all_data,pg_monitor] -> [])",
+	}, actionStrings(applied))
+	for _, u := range server.Users(cluster.Id) {
+		if u.Name == "analyst" {
+			assert.Empty(t, u.Roles, "роли не отозваны")
+		}
+	}
+	plan, err = engine.Plan(ctx, m)
+	assert.NoError(t, err)
+	assert.Empty(t, plan)
+
+	// Уменьшение диска не поддерживается
+	m.Clusters[0].DiskSize = 1024
+	_, err = engine.Plan(ctx, m)
+	assert.Error(t, err)
+
+	destroyed, err := engine.Destroy(ctx, m)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"delete cluster demo"}, actionStrings(destroyed))
+	_, ok = server.Cluster("demo")
+	assert.False(t, ok, "кластер не удалён")
+
+	plan, err = engine.PlanDestroy(ctx, m)
+	assert.NoError(t, err)
+	assert.Empty(t, plan)
+	_, err = engine.Client.FindCluster(ctx, "demo")
+	assert.True(t, dbaas.IsNotFound(err))
+}
+
+func TestApplyRetriesFailedSeed(t *testing.T) {
+	t.Setenv("APP_PASSWORD", "secret")
+	m, err := Load("testdata/environment.yaml")
+	if err != nil {
+		t.Fatalf("Не удалось загрузить манифест: %v", err)
+	}
+
+	server := dbaastest.NewServer(t)
+	seedErr := errors.New("connection refused")
+	seeded := false
+	engine := &Engine{
+		Client:   server.Client(),
+		Interval: 1,
+		Seed: func(ctx context.Context, dsn string, statements []string) error {
+			if seedErr != nil {
+				return seedErr
+			}
+			seeded = true
+			return nil
+		},
+		Seeded: func(ctx context.Context, dsn string) (bool, error) {
+			return seeded, nil
+		},
+	}
+	ctx := context.Background()
+
+	applied, err := engine.Apply(ctx, m)
+	assert.ErrorIs(t, err, seedErr)
+	assert.NotContains(t, actionStrings(applied), "seed database demo/app (2 SQL-запросов от имени app)")
+
+	// База данных уже создана, но начальные данные не загружены, поэтому загрузка планируется снова
+	seedErr = nil
+	applied, err = engine.Apply(ctx, m)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"seed database demo/app (2 SQL-запросов от имени app)"}, actionStrings(applied))
+
+	plan, err := engine.Plan(ctx, m)
+	assert.NoError(t, err)
+	assert.Empty(t, plan)
+}
+
+func actionStrings(actions []Action) []string {
+	var result []string
+	for _, a := range actions {
+		result = append(result, a.String())
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// SeedMarkerTable - таблица, которую PgSeed создаёт в той же транзакции, что и начальные данные
+const SeedMarkerTable = "manifest_seed"
+
+// PgSeed выполняет запросы в одной транзакции через отдельное подключение и отмечает загрузку
+// таблицей SeedMarkerTable. Если таблица уже есть, запросы не выполняются
+func PgSeed(ctx context.Context, dsn string, statements []string) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return fmt.Errorf("не удалось подключиться к базе данных: %w", err)
+	}
+	defer conn.Close(ctx)
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	seeded, err := hasSeedMarker(ctx, tx)
+	if err != nil || seeded {
+		return err
+	}
+	for i, statement := range statements {
+		if _, err := tx.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("запрос %d: %w", i+1, err)
+		}
+	}
+	if _, err := tx.Exec(ctx, "CREATE TABLE "+SeedMarkerTable+" (applied_at timestamptz NOT NULL DEFAULT now())"); err != nil {
+		return fmt.Errorf("не удалось создать таблицу %s: %w", SeedMarkerTable, err)
+	}
+	if _, err := tx.Exec(ctx, "INSERT INTO "+SeedMarkerTable+" DEFAULT VALUES"); err != nil {
+		return fmt.Errorf("не удалось отметить загрузку в %s: %w", SeedMarkerTable, err)
+	}
+	return tx.Commit(ctx)
+}
+
+// PgSeeded проверяет, есть ли в базе данных таблица SeedMarkerTable
+func PgSeeded(ctx context.Context, dsn string) (bool, error) {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return false, fmt.Errorf("не удалось подключиться к базе данных: %w", err)
+	}
+	defer conn.Close(ctx)
+	return hasSeedMarker(ctx, conn)
+}
+
+// rowQuerier реализуют pgx.Conn и pgx.Tx
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+func hasSeedMarker(ctx context.Context, q rowQuerier) (bool, error) {
+	var exists bool
+	err := q.QueryRow(ctx, "SELECT to_regclass($1) IS NOT NULL", SeedMarkerTable).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,162 @@
+// Package metrics собирает длительность операций API от отправки запроса до статуса OK,
+// агрегирует её по перцентилям в том числе по истории предыдущих запусков, проверяет пороги SLO
+// и выводит результат в текстовом формате Prometheus/OpenMetrics.
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Операции, длительность которых измеряется
+const (
+	OpClusterCreate        = "cluster_create"
+	OpClusterResize        = "cluster_resize"
+	OpClusterDiskExpand    = "cluster_disk_expand"
+	OpClusterReplicasScale = "cluster_replicas_scale"
+	OpClusterOptionsUpdate = "cluster_options_update"
+	OpClusterRestart       = "cluster_restart"
+	OpDatabaseCreate       = "database_create"
+	OpUserCreate           = "user_create"
+	OpTableSpaceCreate     = "tablespace_create"
+	OpDumpCreate           = "dump_create"
+	OpDumpRestore          = "dump_restore"
+	OpBackupCreate         = "backup_create"
+)
+
+// Quantiles - перцентили, которые выводятся для каждой операции
+var Quantiles = []float64{0.5, 0.9, 0.95, 0.99}
+
+// Sample представляет одно измерение операции
+type Sample struct {
+	Operation string    `json:"operation"`
+	Seconds   float64   `json:"seconds"`
+	Test      string    `json:"test,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// Recorder накапливает измерения текущего запуска. Методы безопасны для конкурентного вызова
+type Recorder struct {
+	mu      sync.Mutex
+	samples []Sample
+}
+
+// Add добавляет измерение
+func (r *Recorder) Add(s Sample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples = append(r.samples, s)
+}
+
+// Samples возвращает копию измерений в порядке добавления
+func (r *Recorder) Samples() []Sample {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Sample(nil), r.samples...)
+}
+
+// LoadHistory читает измерения предыдущих запусков из файла JSON Lines. Отсутствующий файл
+// означает пустую историю
+func LoadHistory(path string) ([]Sample, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать историю измерений: %w", err)
+	}
+	defer f.Close()
+
+	var samples []Sample
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var s Sample
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			return nil, fmt.Errorf("%s:%d: не удалось разобрать измерение: %w", path, line, err)
+		}
+		samples = append(samples, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("не удалось прочитать историю измерений: %w", err)
+	}
+	return samples, nil
+}
+
+// AppendHistory дописывает измерения в файл истории JSON Lines
+func AppendHistory(path string, samples []Sample) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть историю измерений: %w", err)
+	}
+	encoder := json.NewEncoder(f)
+	for _, s := range samples {
+		if err := encoder.Encode(s); err != nil {
+			f.Close()
+			return fmt.Errorf("не удалось записать историю измерений: %w", err)
+		}
+	}
+	return f.Close()
+}
+
+// Summary представляет агрегированные измерения одной операции
+type Summary struct {
+	Operation string
+	Count     int
+	Sum       float64
+	Min       float64
+	Max       float64
+	// values отсортированы по возрастанию
+	values []float64
+}
+
+// Mean возвращает среднюю длительность операции в секундах
+func (s Summary) Mean() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Sum / float64(s.Count)
+}
+
+// Quantile возвращает перцентиль q (от 0 до 1) по методу ближайшего ранга
+func (s Summary) Quantile(q float64) float64 {
+	if len(s.values) == 0 {
+		return math.NaN()
+	}
+	rank := int(math.Ceil(q*float64(len(s.values)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(s.values) {
+		rank = len(s.values) - 1
+	}
+	return s.values[rank]
+}
+
+// Summarize агрегирует измерения по операциям; результат отсортирован по имени операции
+func Summarize(samples []Sample) []Summary {
+	byOperation := make(map[string][]float64)
+	for _, s := range samples {
+		byOperation[s.Operation] = append(byOperation[s.Operation], s.Seconds)
+	}
+	summaries := make([]Summary, 0, len(byOperation))
+	for operation, values := range byOperation {
+		sort.Float64s(values)
+		summary := Summary{Operation: operation, Count: len(values), Min: values[0], Max: values[len(values)-1], values: values}
+		for _, v := range values {
+			summary.Sum += v
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Operation < summaries[j].Operation })
+	return summaries
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func samples(operation string, seconds ...float64) []Sample {
+	var result []Sample
+	for _, s := range seconds {
+		result = append(result, Sample{Operation: operation, Seconds: s})
+	}
+	return result
+}
+
+func TestSummarize(t *testing.T) {
+	summaries := Summarize(append(samples(OpDumpRestore, 30, 10, 20), samples(OpClusterCreate, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10)...))
+	if !assert.Len(t, summaries, 2) {
+		return
+	}
+	cluster, restore := summaries[0], summaries[1]
+	assert.Equal(t, OpClusterCreate, cluster.Operation)
+	assert.Equal(t, 10, cluster.Count)
+	assert.Equal(t, 5.5, cluster.Mean())
+	assert.Equal(t, 5.0, cluster.Quantile(0.5))
+	assert.Equal(t, 9.0, cluster.Quantile(0.9))
+	assert.Equal(t, 10.0, cluster.Quantile(0.95))
+	assert.Equal(t, 10.0, cluster.Quantile(1))
+	assert.Equal(t, 10.0, restore.Min)
+	assert.Equal(t, 30.0, restore.Max)
+	assert.Equal(t, 20.0, restore.Quantile(0.5))
+}
+
+func TestParseSLOs(t *testing.T) {
+	slos, err := ParseSLOs("cluster_create<10m, dump_restore:p95<2m,user_create:p99.9<30s")
+	assert.NoError(t, err)
+	assert.Equal(t, []SLO{
+		{Operation: OpClusterCreate, Quantile: 1, Threshold: 10 * time.Minute},
+		{Operation: OpDumpRestore, Quantile: 0.95, Threshold: 2 * time.Minute},
+		{Operation: OpUserCreate, Quantile: 0.999, Threshold: 30 * time.Second},
+	}, slos)
+	assert.Equal(t, "cluster_create<10m0s", slos[0].String())
+	assert.Equal(t, "dump_restore:p95<2m0s", slos[1].String())
+	assert.Equal(t, "user_create:p99.9<30s", slos[2].String())
+
+	for _, spec := range []string{"cluster_create", "cluster_create<", "cluster_create<-1s", ":p95<1m", "cluster_create:95<1m", "cluster_create:p0<1m", "cluster_create:p101<1m"} {
+		_, err := ParseSLOs(spec)
+		assert.Error(t, err, spec)
+	}
+	slos, err = ParseSLOs("")
+	assert.NoError(t, err)
+	assert.Empty(t, slos)
+}
+
+func TestEvaluate(t *testing.T) {
+	summaries := Summarize(append(samples(OpClusterCreate, 100, 200, 700), samples(OpDumpRestore, 60, 90)...))
+	slos, err := ParseSLOs("cluster_create<10m,cluster_create:p50<5m,dump_restore:p95<2m,backup_create<1s")
+	assert.NoError(t, err)
+
+	violations := Evaluate(slos, summaries)
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, slos[0], violations[0].SLO)
+		assert.Equal(t, 700.0, violations[0].Value)
+		assert.Contains(t, violations[0].Error(), "11m40s")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	history, err := LoadHistory(path)
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.NoError(t, AppendHistory(path, []Sample{{Operation: OpClusterCreate, Seconds: 300, Test: "TestEndToEnd", At: at}}))
+	assert.NoError(t, AppendHistory(path, samples(OpClusterCreate, 400)))
+	history, err = LoadHistory(path)
+	assert.NoError(t, err)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, Sample{Operation: OpClusterCreate, Seconds: 300, Test: "TestEndToEnd", At: at}, history[0])
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	slos, err := ParseSLOs("dump_restore:p95<1m")
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, WritePrometheus(&buf, Summarize(samples(OpDumpRestore, 30, 90)), slos))
+
+	out := buf.String()
+	assert.Contains(t, out, "# TYPE dbaas_operation_duration_seconds summary\n")
+	assert.Contains(t, out, `dbaas_operation_duration_seconds{operation="dump_restore",quantile="0.5"} 30`+"\n")
+	assert.Contains(t, out, `dbaas_operation_duration_seconds{operation="dump_restore",quantile="0.99"} 90`+"\n")
+	assert.Contains(t, out, `dbaas_operation_duration_seconds_sum{operation="dump_restore"} 120`+"\n")
+	assert.Contains(t, out, `dbaas_operation_duration_seconds_count{operation="dump_restore"} 2`+"\n")
+	assert.Contains(t, out, `dbaas_operation_slo_seconds{operation="dump_restore",quantile="0.95"} 60`+"\n")
+	assert.Contains(t, out, `dbaas_operation_slo_violated{operation="dump_restore",quantile="0.95"} 1`+"\n")
+	assert.True(t, bytes.HasSuffix(buf.Bytes(), []byte("# EOF\n")))
+}
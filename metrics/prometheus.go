@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WritePrometheus выводит длительность операций как summary dbaas_operation_duration_seconds
+// и пороги SLO с признаком нарушения в текстовом формате Prometheus. Вывод завершается
+// маркером # EOF и поэтому также является корректным OpenMetrics
+func WritePrometheus(w io.Writer, summaries []Summary, slos []SLO) error {
+	var b strings.Builder
+	b.WriteString("# HELP dbaas_operation_duration_seconds Длительность операции от отправки запроса до статуса OK.\n")
+	b.WriteString("# TYPE dbaas_operation_duration_seconds summary\n")
+	b.WriteString("# UNIT dbaas_operation_duration_seconds seconds\n")
+	for _, s := range summaries {
+		operation := escapeLabel(s.Operation)
+		for _, q := range Quantiles {
+			fmt.Fprintf(&b, "dbaas_operation_duration_seconds{operation=\"%s\",quantile=\"%s\"} %s\n", operation, formatFloat(q), formatFloat(s.Quantile(q)))
+		}
+		fmt.Fprintf(&b, "dbaas_operation_duration_seconds_sum{operation=\"%s\"} %s\n", operation, formatFloat(s.Sum))
+		fmt.Fprintf(&b, "dbaas_operation_duration_seconds_count{operation=\"%s\"} %d\n", operation, s.Count)
+	}
+
+	if len(slos) > 0 {
+		violated := make(map[SLO]bool)
+		for _, v := range Evaluate(slos, summaries) {
+			violated[v.SLO] = true
+		}
+		b.WriteString("# HELP dbaas_operation_slo_seconds Порог SLO длительности операции; квантиль 1 означает порог для каждого измерения.\n")
+		b.WriteString("# TYPE dbaas_operation_slo_seconds gauge\n")
+		b.WriteString("# UNIT dbaas_operation_slo_seconds seconds\n")
+		for _, slo := range slos {
+			fmt.Fprintf(&b, "dbaas_operation_slo_seconds{operation=\"%s\",quantile=\"%s\"} %s\n", escapeLabel(slo.Operation), formatFloat(slo.Quantile), formatFloat(slo.Threshold.Seconds()))
+		}
+		b.WriteString("# HELP dbaas_operation_slo_violated Нарушен ли порог SLO: 1 - нарушен, 0 - соблюдён.\n")
+		b.WriteString("# TYPE dbaas_operation_slo_violated gauge\n")
+		for _, slo := range slos {
+			value := 0
+			if violated[slo] {
+				value = 1
+			}
+			fmt.Fprintf(&b, "dbaas_operation_slo_violated{operation=\"%s\",quantile=\"%s\"} %d\n", escapeLabel(slo.Operation), formatFloat(slo.Quantile), value)
+		}
+	}
+	b.WriteString("# EOF\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SLO представляет порог длительности операции. Quantile равен 1, если порог относится
+// к каждому измерению, а не к перцентилю
+type SLO struct {
+	Operation string
+	Quantile  float64
+	Threshold time.Duration
+}
+
+func (s SLO) String() string {
+	if s.Quantile == 1 {
+		return fmt.Sprintf("%s<%s", s.Operation, s.Threshold)
+	}
+	return fmt.Sprintf("%s:p%s<%s", s.Operation, strconv.FormatFloat(math.Round(s.Quantile*1e6)/1e4, 'f', -1, 64), s.Threshold)
+}
+
+// ParseSLOs разбирает список порогов через запятую вида
+//
+//	cluster_create<10m,dump_restore:p95<2m
+//
+// Порог без перцентиля относится к каждому измерению операции, порог с перцентилем pNN —
+// к перцентилю по всем измерениям с учётом истории
+func ParseSLOs(spec string) ([]SLO, error) {
+	var slos []SLO
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		target, threshold, ok := strings.Cut(item, "<")
+		if !ok {
+			return nil, fmt.Errorf("SLO %q: ожидается формат операция[:pNN]<длительность", item)
+		}
+		slo := SLO{Operation: strings.TrimSpace(target), Quantile: 1}
+		if operation, quantile, ok := strings.Cut(slo.Operation, ":"); ok {
+			p, err := strconv.ParseFloat(strings.TrimPrefix(quantile, "p"), 64)
+			if !strings.HasPrefix(quantile, "p") || err != nil || p <= 0 || p > 100 {
+				return nil, fmt.Errorf("SLO %q: перцентиль задаётся как p50, p95, p99.9", item)
+			}
+			// Округление убирает погрешность деления: p99.9 должен дать ровно 0.999
+			slo.Operation, slo.Quantile = operation, math.Round(p*1e4)/1e6
+		}
+		if slo.Operation == "" {
+			return nil, fmt.Errorf("SLO %q: не задана операция", item)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(threshold))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("SLO %q: неверная длительность %q", item, threshold)
+		}
+		slo.Threshold = d
+		slos = append(slos, slo)
+	}
+	return slos, nil
+}
+
+// Violation представляет нарушение SLO; Value - фактическая длительность в секундах
+type Violation struct {
+	SLO   SLO
+	Value float64
+}
+
+func (v Violation) Error() string {
+	value := time.Duration(v.Value * float64(time.Second)).Round(time.Millisecond)
+	if v.SLO.Quantile == 1 {
+		return fmt.Sprintf("нарушен SLO %s: максимальная длительность %s", v.SLO, value)
+	}
+	return fmt.Sprintf("нарушен SLO %s: перцентиль составил %s", v.SLO, value)
+}
+
+// Evaluate проверяет пороги по агрегированным измерениям. Операции без измерений не проверяются
+func Evaluate(slos []SLO, summaries []Summary) []Violation {
+	var violations []Violation
+	for _, slo := range slos {
+		for _, summary := range summaries {
+			if summary.Operation != slo.Operation {
+				continue
+			}
+			value := summary.Quantile(slo.Quantile)
+			if value > slo.Threshold.Seconds() {
+				violations = append(violations, Violation{SLO: slo, Value: value})
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestClusterOptionsUpdate(t *testing.T) {
+	a := Assert(t)
+	RequireAPIEnv(t)
+	Authorize(t)
+
+	ctx := context.Background()
+	request := DefaultClusterRequest(t, "options-"+RandomSuffix())
+	f := newScalingFixture(t, ctx, request)
+
+	// Меняем все опции относительно значений при создании
+	options := request.Options
+	options.MaximumLagOnFailover = 2 * request.Options.MaximumLagOnFailover
+	options.WalArchiveMode = !request.Options.WalArchiveMode
+	options.AutoRestart = !request.Options.AutoRestart
+	options.Production = !request.Options.Production
+	options.EnableSynchronousMode = !request.Options.EnableSynchronousMode
+	options.DisableAutofailover = !request.Options.DisableAutofailover
+	cluster := UpdateClusterOptions(t, f.clusterId, options)
+	a.Equal(options, cluster.Options, "опции кластера не изменились")
+	a.Equal(options, GetCluster(t, f.clusterId).Options, "опции кластера не сохранились")
+
+	// archive_mode применяется только при перезапуске: с AutoRestart сервер перезапускается сам
+	if !options.AutoRestart {
+		RestartCluster(t, f.clusterId)
+	}
+	a.NoError(f.session.WaitReady(ctx, statusPollAttempts, statusPollInterval), "база данных недоступна после перезапуска")
+	archiveMode := "off"
+	if options.WalArchiveMode {
+		archiveMode = "on"
+	}
+	_, err := WaitForSetting(ctx, f.session, "archive_mode", 4*statusPollAttempts, statusPollInterval, func(s PgSetting) bool {
+		return s.Setting == archiveMode && !s.PendingRestart
+	})
+	a.NoError(err, "archive_mode не применился")
+
+	// Синхронный режим должен отразиться в synchronous_standby_names
+	setting, err := WaitForSetting(ctx, f.session, "synchronous_standby_names", statusPollAttempts, statusPollInterval, func(s PgSetting) bool {
+		return s.Setting != ""
+	})
+	a.NoError(err, "синхронный режим не применился")
+	Logf(t, "synchronous_standby_names: %s", setting.Setting)
+
+	options.EnableSynchronousMode = false
+	cluster = UpdateClusterOptions(t, f.clusterId, options)
+	a.Equal(options, cluster.Options, "опции кластера не изменились")
+	a.Equal(options, GetCluster(t, f.clusterId).Options, "опции кластера не сохранились")
+	_, err = WaitForSetting(ctx, f.session, "synchronous_standby_names", statusPollAttempts, statusPollInterval, func(s PgSetting) bool {
+		return s.Setting == ""
+	})
+	a.NoError(err, "синхронный режим не отключился")
+}
+
+func TestClusterParametersUpdate(t *testing.T) {
+	RequireAPIEnv(t)
+	Authorize(t)
+
+	ctx := context.Background()
+	request := DefaultClusterRequest(t, "parameters-"+RandomSuffix())
+	f := newScalingFixture(t, ctx, request)
+
+	// Параметр, применяемый без перезапуска
+	t.Run("reload", func(t *testing.T) {
+		a := Assert(t)
+		started, err := PostmasterStartTime(ctx, f.session)
+		a.NoError(err, "не удалось получить время запуска сервера")
+
+		parameters := UpdateClusterParameters(t, f.clusterId, map[string]string{"work_mem": "8MB"})
+		for _, p := range parameters {
+			if p.Name == "work_mem" {
+				a.False(p.RequiresRestart, "work_mem не должен требовать перезапуска")
+			}
+		}
+		setting, err := WaitForSetting(ctx, f.session, "work_mem", statusPollAttempts, statusPollInterval, func(s PgSetting) bool {
+			return s.Setting == "8192"
+		})
+		a.NoError(err, "work_mem не применился")
+		a.False(setting.PendingRestart)
+		a.False(GetCluster(t, f.clusterId).PendingRestart, "кластер ожидает перезапуска после изменения work_mem")
+
+		after, err := PostmasterStartTime(ctx, f.session)
+		a.NoError(err, "не удалось получить время запуска сервера")
+		a.Equal(started, after, "сервер перезапустился при изменении work_mem")
+	})
+
+	// Параметр, требующий перезапуска, проверяется при выключенном и включенном AutoRestart
+	for _, autoRestart := range []bool{false, true} {
+		autoRestart := autoRestart
+		t.Run("restart/auto_restart="+strconv.FormatBool(autoRestart), func(t *testing.T) {
+			a := Assert(t)
+			options := GetCluster(t, f.clusterId).Options
+			options.AutoRestart = autoRestart
+			UpdateClusterOptions(t, f.clusterId, options)
+			a.NoError(f.session.WaitReady(ctx, statusPollAttempts, statusPollInterval), "база данных недоступна")
+
+			started, err := PostmasterStartTime(ctx, f.session)
+			a.NoError(err, "не удалось получить время запуска сервера")
+			before, err := QueryPgSetting(ctx, f.session, "max_connections")
+			a.NoError(err, "не удалось прочитать max_connections")
+			current, _ := strconv.Atoi(before.Setting)
+			target := strconv.Itoa(current + 10)
+
+			parameters := UpdateClusterParameters(t, f.clusterId, map[string]string{"max_connections": target})
+			parameter, found := ClusterParameter{}, false
+			for _, p := range parameters {
+				if p.Name == "max_connections" {
+					parameter, found = p, true
+				}
+			}
+			a.True(found, "max_connections отсутствует в параметрах кластера")
+			a.True(parameter.RequiresRestart, "max_connections должен быть помечен как требующий перезапуска")
+
+			if !autoRestart {
+				// Без AutoRestart параметр ждёт перезапуска, сервер продолжает работать со старым значением
+				setting, err := WaitForSetting(ctx, f.session, "max_connections", statusPollAttempts, statusPollInterval, func(s PgSetting) bool {
+					return s.PendingRestart
+				})
+				a.NoError(err, "pending_restart не выставлен для max_connections")
+				a.Equal(before.Setting, setting.Setting, "max_connections изменился без перезапуска")
+				a.True(GetCluster(t, f.clusterId).PendingRestart, "кластер не сообщает о необходимости перезапуска")
+
+				after, err := PostmasterStartTime(ctx, f.session)
+				a.NoError(err, "не удалось получить время запуска сервера")
+				a.Equal(started, after, "сервер перезапустился при выключенном AutoRestart")
+
+				RestartCluster(t, f.clusterId)
+			}
+
+			// После перезапуска (ручного или автоматического) значение применено
+			a.NoError(f.session.WaitReady(ctx, statusPollAttempts, statusPollInterval), "база данных недоступна после перезапуска")
+			setting, err := WaitForSetting(ctx, f.session, "max_connections", statusPollAttempts, statusPollInterval, func(s PgSetting) bool {
+				return s.Setting == target && !s.PendingRestart
+			})
+			a.NoError(err, "max_connections не применился после перезапуска")
+			Logf(t, "max_connections after restart: %s", setting.Setting)
+
+			after, err := PostmasterStartTime(ctx, f.session)
+			a.NoError(err, "не удалось получить время запуска сервера")
+			a.True(after.After(started), "сервер не был перезапущен")
+			a.False(WaitForCluster(t, f.clusterId, statusPollAttempts, func(c Cluster) bool { return !c.PendingRestart }).PendingRestart)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"dbaas_testing_task/credentials"
+)
+
+func TestUserPasswordPolicy(t *testing.T) {
+	a := Assert(t)
+	RequireAPIEnv(t)
+	// Политика паролей зависит от настроек API, поэтому проверка включается явно
+	if os.Getenv("CHECK_PASSWORD_POLICY") != "1" {
+		t.Skip("Проверка политики паролей выключена: задайте CHECK_PASSWORD_POLICY=1")
+	}
+	Authorize(t)
+	// Тест намеренно отправляет пароли, нарушающие ограничения спецификации
+	SkipRequestContract(t)
+
+	suffix := RandomSuffix()
+	clusterId := ProvisionCluster(t, DefaultClusterRequest(t, "policy-"+suffix))
+	dbName := "policyDB"
+	CreateDatabase(t, clusterId, CreateDBRequest{Name: dbName, TableSpaceID: GetDefaultTableSpaceID(t, clusterId)})
+
+	userName := "policy_" + suffix
+	cases := []struct {
+		name     string
+		password string
+	}{
+		{"empty", ""},
+		{"short", "Aa1!"},
+		{"lowercase only", "abcdefghijklmnopqrstuvwx"},
+		{"digits only", "123456789012345678901234"},
+		{"common", "password123"},
+		{"same as user name", userName},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			a := Assert(t)
+			status, apiError := TryCreateClusterUser(t, clusterId, CreateClusterUserRequest{
+				Databases: []string{dbName},
+				Roles:     []string{"pg_read_all_data"},
+				Name:      userName,
+				Password:  tc.password,
+			})
+			a.Contains([]int{http.StatusBadRequest, http.StatusUnprocessableEntity}, status, "слабый пароль принят")
+			a.True(apiError.Field == "password" || strings.Contains(apiError.Message, "password"),
+				"ошибка не указывает на поле password: %+v", apiError)
+		})
+	}
+
+	// Сгенерированный пароль принимается и позволяет подключиться
+	dbUser := DBUserCredentials(t, "policy")
+	a.NoError(credentials.DefaultPolicy.Check(dbUser.Password))
+	CreateClusterUser(t, clusterId, CreateClusterUserRequest{
+		Databases: []string{dbName},
+		Roles:     []string{"pg_read_all_data"},
+		Name:      dbUser.Username,
+		Password:  dbUser.Password,
+	})
+	session, err := OpenDBSession(context.Background(), ConnectionString(t, clusterId, dbName, dbUser.Username, dbUser.Password), singleConnPoolConfig())
+	if a.NoError(err, "не удалось подключиться со сгенерированным паролем") {
+		session.Close()
+	}
+}
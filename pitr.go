@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// PhaseRow представляет строку таблицы test_schema.phases, заполняемой по фазам для проверки восстановления.
+type PhaseRow struct {
+	Id      int64
+	Phase   int
+	Payload string
+}
+
+// WALPosition описывает момент на мастере: время сервера и позицию WAL.
+type WALPosition struct {
+	Time time.Time
+	LSN  string
+}
+
+// CreatePhaseTable создаёт таблицу для данных, записываемых по фазам
+func CreatePhaseTable(ctx context.Context, s *DBSession) error {
+	_, err := s.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS test_schema.phases (
+			id BIGSERIAL PRIMARY KEY,
+			phase INT NOT NULL,
+			payload TEXT NOT NULL,
+			written_at TIMESTAMPTZ DEFAULT clock_timestamp()
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу фаз: %w", err)
+	}
+	return nil
+}
+
+// WritePhase добавляет count строк фазы phase одной транзакцией
+func WritePhase(ctx context.Context, s *DBSession, phase, count int) error {
+	_, err := s.Exec(ctx, `
+		INSERT INTO test_schema.phases (phase, payload)
+		SELECT $1, md5(random()::text) FROM generate_series(1, $2)
+	`, phase, count)
+	if err != nil {
+		return fmt.Errorf("не удалось записать фазу %d: %w", phase, err)
+	}
+	return nil
+}
+
+// FetchPhaseRows возвращает все строки test_schema.phases в порядке id
+func FetchPhaseRows(ctx context.Context, s *DBSession) ([]PhaseRow, error) {
+	var result []PhaseRow
+	err := s.Query(ctx, `SELECT id, phase, payload FROM test_schema.phases ORDER BY id`, nil, func(rows pgx.Rows) error {
+		var row PhaseRow
+		if err := rows.Scan(&row.Id, &row.Phase, &row.Payload); err != nil {
+			return err
+		}
+		result = append(result, row)
+		return nil
+	})
+	return result, err
+}
+
+// CurrentWALPosition возвращает текущие время сервера и позицию WAL на мастере
+func CurrentWALPosition(ctx context.Context, s *DBSession) (WALPosition, error) {
+	var position WALPosition
+	err := s.QueryRow(ctx, `SELECT clock_timestamp(), pg_current_wal_lsn()::text`, nil, &position.Time, &position.LSN)
+	return position, err
+}
+
+// pgErrInsufficientPrivilege - код ошибки Postgres при нехватке прав
+const pgErrInsufficientPrivilege = "42501"
+
+// SwitchWAL закрывает текущий сегмент WAL, чтобы он был передан в архив без ожидания archive_timeout.
+// Возвращает false без ошибки, если у пользователя нет права вызывать pg_switch_wal
+func SwitchWAL(ctx context.Context, s *DBSession) (bool, error) {
+	_, err := s.Exec(ctx, `SELECT pg_switch_wal()`)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgErrInsufficientPrivilege {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// WaitWALArchived закрывает сегмент WAL, содержащий позицию lsn, и ждёт, пока он попадёт в архив.
+// Без права на pg_switch_wal сегмент закроется только по archive_timeout кластера
+func WaitWALArchived(ctx context.Context, s *DBSession, lsn string, attempts int, interval time.Duration) error {
+	// На простаивающем кластере сегмент с lsn иначе может оставаться текущим сколь угодно долго
+	if _, err := SwitchWAL(ctx, s); err != nil {
+		return fmt.Errorf("не удалось переключить сегмент WAL: %w", err)
+	}
+	var archived bool
+	for i := 0; i < attempts; i++ {
+		err := s.QueryRow(ctx, `
+			SELECT coalesce(last_archived_wal >= pg_walfile_name($1::pg_lsn), false)
+			FROM pg_stat_archiver
+		`, []interface{}{lsn}, &archived)
+		if err != nil {
+			return err
+		}
+		if archived {
+			return nil
+		}
+		time.Sleep(interval)
+	}
+	return fmt.Errorf("сегмент WAL с позицией %s не попал в архив", lsn)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPointInTimeRecovery(t *testing.T) {
+	a := Assert(t)
+	RequireAPIEnv(t)
+	Authorize(t)
+
+	ctx := context.Background()
+	request := DefaultClusterRequest(t, "pitr-"+RandomSuffix())
+	request.Options.WalArchiveMode = true
+	f := newScalingFixture(t, ctx, request)
+	a.NoError(CreatePhaseTable(ctx, f.session), "не удалось создать таблицу фаз")
+
+	// Пишем данные фазами и фиксируем ожидаемое состояние после каждой фазы
+	writePhase := func(phase int) (WALPosition, []PhaseRow) {
+		a.NoError(WritePhase(ctx, f.session, phase, 100))
+		position, err := CurrentWALPosition(ctx, f.session)
+		a.NoError(err, "не удалось получить позицию WAL")
+		rows, err := FetchPhaseRows(ctx, f.session)
+		a.NoError(err, "не удалось прочитать данные")
+		Logf(t, "Phase %d written: %d rows, time %s, LSN %s", phase, len(rows), position.Time.Format(time.RFC3339Nano), position.LSN)
+		// Разносим фазы во времени, чтобы точка восстановления по времени однозначно отделяла их
+		time.Sleep(2 * time.Second)
+		return position, rows
+	}
+	afterFirst, firstRows := writePhase(1)
+	afterSecond, secondRows := writePhase(2)
+	// Третья фаза меняет уже записанные данные, чтобы восстановление "лишнего" было заметно
+	_, err := f.session.Exec(ctx, `DELETE FROM test_schema.phases WHERE phase = 1 AND id % 2 = 0`)
+	a.NoError(err, "не удалось изменить данные")
+	writePhase(3)
+
+	lastPosition, err := CurrentWALPosition(ctx, f.session)
+	a.NoError(err, "не удалось получить позицию WAL")
+	if err := WaitWALArchived(ctx, f.session, lastPosition.LSN, 4*statusPollAttempts, statusPollInterval); err != nil {
+		Fatalf(t, "WAL не архивируется: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		target   RecoveryTarget
+		expected []PhaseRow
+	}{
+		{"by_time", RecoveryTarget{SourceClusterID: f.clusterId, TargetTime: &afterFirst.Time}, firstRows},
+		{"by_lsn", RecoveryTarget{SourceClusterID: f.clusterId, TargetLSN: afterSecond.LSN}, secondRows},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			a := Assert(t)
+			restoreRequest := DefaultClusterRequest(t, "pitr-"+tc.name+"-"+RandomSuffix())
+			restoreRequest.CreationMode = CreationModePITR
+			restoreRequest.Recovery = &tc.target
+			restoredId := ProvisionCluster(t, restoreRequest)
+
+			// Пользователи и базы восстанавливаются вместе с данными
+			restored := NewDBSession(t, ctx, ConnectionString(t, restoredId, f.dbName, f.userName, f.userPassword), LoadPoolConfig(t))
+			rows, err := FetchPhaseRows(ctx, restored)
+			a.NoError(err, "не удалось прочитать восстановленные данные")
+			a.Equal(tc.expected, rows, "восстановленные данные не совпадают с состоянием на точке восстановления")
+		})
+	}
+}
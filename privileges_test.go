@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// privilegeCase описывает одну комбинацию ролей и баз данных пользователя и ожидаемые права
+type privilegeCase struct {
+	name      string
+	roles     []string
+	databases []string
+	// Ожидаемый результат операций: true - операция должна пройти, false - завершиться ошибкой
+	canSelect       bool
+	canInsert       bool
+	canConnectMain  bool
+	canConnectOther bool
+}
+
+// privilegeMatrix строит матрицу всех поддерживаемых комбинаций ролей и списков баз данных.
+// Ожидания исходят из того, что API выдаёт пользователю CONNECT и CREATE только на перечисленные базы,
+// а права на данные определяются предопределёнными ролями pg_read_all_data и pg_write_all_data.
+// Права, не зависящие от комбинации, проверяются в тесте напрямую: DDL в чужой схеме запрещён при любых
+// ролях, а своя схема создаётся в любой доступной базе
+func privilegeMatrix(mainDB, otherDB string) []privilegeCase {
+	roleSets := []struct {
+		name  string
+		roles []string
+	}{
+		{"no_roles", []string{}},
+		{"read", []string{"pg_read_all_data"}},
+		{"write", []string{"pg_write_all_data"}},
+		{"read_write", []string{"pg_write_all_data", "pg_read_all_data"}},
+	}
+	dbSets := []struct {
+		name      string
+		databases []string
+	}{
+		{"main_db", []string{mainDB}},
+		{"other_db", []string{otherDB}},
+		{"both_db", []string{mainDB, otherDB}},
+	}
+
+	var cases []privilegeCase
+	for _, rs := range roleSets {
+		for _, ds := range dbSets {
+			tc := privilegeCase{
+				name:      rs.name + "/" + ds.name,
+				roles:     rs.roles,
+				databases: ds.databases,
+			}
+			for _, role := range rs.roles {
+				tc.canSelect = tc.canSelect || role == "pg_read_all_data"
+				tc.canInsert = tc.canInsert || role == "pg_write_all_data"
+			}
+			for _, db := range ds.databases {
+				tc.canConnectMain = tc.canConnectMain || db == mainDB
+				tc.canConnectOther = tc.canConnectOther || db == otherDB
+			}
+			cases = append(cases, tc)
+		}
+	}
+	return cases
+}
+
+// assertOperation проверяет, что ошибка операции соответствует ожиданию
+func assertOperation(t *testing.T, operation string, expected bool, err error) {
+	a := Assert(t)
+	if expected {
+		a.NoError(err, "операция %s должна быть разрешена", operation)
+	} else {
+		a.Error(err, "операция %s должна быть запрещена", operation)
+	}
+}
+
+func TestUserPrivilegeMatrix(t *testing.T) {
+	RequireAPIEnv(t)
+	Authorize(t)
+
+	ctx := context.Background()
+	suffix := RandomSuffix()
+	clusterId := ProvisionCluster(t, DefaultClusterRequest(t, "privileges-"+suffix))
+
+	tableSpaceId := GetDefaultTableSpaceID(t, clusterId)
+	mainDB, otherDB := "privDB", "otherDB"
+	CreateDatabase(t, clusterId, CreateDBRequest{Name: mainDB, TableSpaceID: tableSpaceId})
+	CreateDatabase(t, clusterId, CreateDBRequest{Name: otherDB, TableSpaceID: tableSpaceId})
+
+	// Владелец тестовых данных, от имени которого создаются схема и таблица
+	ownerName, ownerPassword := "owner_"+suffix, RandomPassword()
+	CreateClusterUser(t, clusterId, CreateClusterUserRequest{
+		Databases: []string{mainDB, otherDB},
+		Roles:     []string{"pg_write_all_data", "pg_read_all_data"},
+		Name:      ownerName,
+		Password:  ownerPassword,
+	})
+	owner := NewDBSession(t, ctx, ConnectionString(t, clusterId, mainDB, ownerName, ownerPassword), LoadPoolConfig(t))
+	if err := CreateTestSchema(ctx, owner); err != nil {
+		Fatalf(t, "Не удалось подготовить данные: %v", err)
+	}
+	if err := SeedUsers(ctx, owner, rand.New(rand.NewSource(time.Now().UnixNano())), 3); err != nil {
+		Fatalf(t, "Не удалось подготовить данные: %v", err)
+	}
+
+	for i, tc := range privilegeMatrix(mainDB, otherDB) {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			userName, userPassword := fmt.Sprintf("priv_%s_%d", suffix, i), RandomPassword()
+			CreateClusterUser(t, clusterId, CreateClusterUserRequest{
+				Databases: tc.databases,
+				Roles:     tc.roles,
+				Name:      userName,
+				Password:  userPassword,
+			})
+
+			other, err := OpenDBSession(ctx, ConnectionString(t, clusterId, otherDB, userName, userPassword), singleConnPoolConfig())
+			if err == nil {
+				other.Close()
+			}
+			assertOperation(t, "CONNECT "+otherDB, tc.canConnectOther, err)
+
+			session, err := OpenDBSession(ctx, ConnectionString(t, clusterId, mainDB, userName, userPassword), singleConnPoolConfig())
+			assertOperation(t, "CONNECT "+mainDB, tc.canConnectMain, err)
+			if err != nil {
+				return
+			}
+			defer session.Close()
+
+			var count int
+			err = session.QueryRow(ctx, `SELECT count(*) FROM test_schema.users`, nil, &count)
+			assertOperation(t, "SELECT", tc.canSelect, err)
+
+			_, err = session.Exec(ctx, `
+				INSERT INTO test_schema.users (name, email, age)
+				VALUES ($1, $2, $3)
+			`, userName, userName+"@example.com", 30)
+			assertOperation(t, "INSERT", tc.canInsert, err)
+
+			// Ни одна из ролей не даёт CREATE на схему test_schema, созданную владельцем данных
+			_, err = session.Exec(ctx, fmt.Sprintf(`CREATE TABLE test_schema.probe_%d (id INT)`, i))
+			assertOperation(t, "DDL", false, err)
+
+			// CREATE на базу данных, выданный API, позволяет создать свою схему
+			_, err = session.Exec(ctx, fmt.Sprintf(`CREATE SCHEMA %s`, strings.ToLower(userName)))
+			assertOperation(t, "CREATE SCHEMA", true, err)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"dbaas_testing_task/cassette"
+)
+
+// При DBAAS_CASSETTE_MODE=record обмен makeRequest с API дописывается в кассету DBAAS_CASSETTE,
+// при DBAAS_CASSETTE_MODE=replay ответы берутся из неё без обращения к API.
+// Имена ресурсов из RandomSuffix в обоих режимах выводятся из seed кассеты
+var (
+	cassetteFile = os.Getenv("DBAAS_CASSETTE")
+	cassetteMode = os.Getenv("DBAAS_CASSETTE_MODE")
+	// httpTransport выполняет запросы makeRequest; nil означает http.DefaultTransport
+	httpTransport http.RoundTripper
+)
+
+// setupCassette включает запись или воспроизведение и возвращает функцию, которую нужно вызвать
+// после выполнения тестов
+func setupCassette() (func() error, error) {
+	if cassetteMode == "" {
+		return func() error { return nil }, nil
+	}
+	if cassetteFile == "" {
+		return nil, fmt.Errorf("DBAAS_CASSETTE_MODE=%s: не задан файл кассеты DBAAS_CASSETTE", cassetteMode)
+	}
+	switch cassetteMode {
+	case "record":
+		recorder, err := cassette.NewRecorder(cassetteFile, nil)
+		if err != nil {
+			return nil, err
+		}
+		httpTransport = recorder
+		seedSuffixes(recorder.Seed())
+		return recorder.Close, nil
+	case "replay":
+		c, err := cassette.Load(cassetteFile)
+		if err != nil {
+			return nil, err
+		}
+		replayer := cassette.NewReplayer(c.Interactions)
+		httpTransport = replayer
+		if c.Seed == 0 {
+			fmt.Fprintf(os.Stderr, "В кассете %s нет seed: имена ресурсов не совпадут с записанными\n", cassetteFile)
+		}
+		seedSuffixes(c.Seed)
+		// Записанные ответы возвращаются сразу, ждать между опросами статуса незачем
+		statusPollInterval = 0
+		// Адрес API и учётные данные при воспроизведении не используются, но без них тесты пропускаются
+		if apiBaseURL == "" {
+			apiBaseURL = "http://cassette.invalid"
+		}
+		if login == "" {
+			login = "cassette"
+		}
+		if password == "" {
+			password = "cassette"
+		}
+		return func() error {
+			if unused := replayer.Unused(); unused > 0 {
+				fmt.Fprintf(os.Stderr, "Не воспроизведено обменов из кассеты %s: %d\n", cassetteFile, unused)
+			}
+			return nil
+		}, nil
+	}
+	return nil, fmt.Errorf("DBAAS_CASSETTE_MODE=%s: ожидается record или replay", cassetteMode)
+}
+
+// skipDatabaseOnReplay пропускает тест при воспроизведении кассеты: в ней записан только обмен
+// с API, а подключение к базе данных ушло бы к хостам из записанных ответов
+func skipDatabaseOnReplay(t *testing.T) {
+	if cassetteMode == "replay" {
+		t.Skip("Тест подключается к базе данных и не воспроизводится из кассеты")
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClusterReplicaScaling(t *testing.T) {
+	a := Assert(t)
+	RequireAPIEnv(t)
+	Authorize(t)
+
+	ctx := context.Background()
+	request := DefaultClusterRequest(t, "replicas-"+RandomSuffix())
+	f := newScalingFixture(t, ctx, request)
+
+	_, err := WaitReplicasCaughtUp(ctx, f.session, request.ReplicasCount, statusPollAttempts, statusPollInterval)
+	a.NoError(err, "исходная топология кластера не синхронизирована")
+
+	// Добавляем реплику под нагрузкой: новая реплика должна догнать мастер
+	scaledOut := request.ReplicasCount + 1
+	stats := f.runWithWorkload(t, ctx, func() {
+		cluster := ScaleClusterReplicas(t, f.clusterId, scaledOut)
+		a.Equal(scaledOut, cluster.ReplicasCount)
+	})
+	a.NoError(f.session.WaitReady(ctx, statusPollAttempts, statusPollInterval), "база данных недоступна после добавления реплики")
+	replicas, err := WaitReplicasCaughtUp(ctx, f.session, scaledOut, statusPollAttempts, statusPollInterval)
+	a.NoError(err, "новая реплика не догнала мастер")
+	Logf(t, "Replicas after scale-out: %+v", replicas)
+	writes := stats.Writes
+
+	// Удаляем реплику: оставшаяся топология должна быть исправна
+	stats = f.runWithWorkload(t, ctx, func() {
+		cluster := ScaleClusterReplicas(t, f.clusterId, request.ReplicasCount)
+		a.Equal(request.ReplicasCount, cluster.ReplicasCount)
+	})
+	a.NoError(f.session.WaitReady(ctx, statusPollAttempts, statusPollInterval), "база данных недоступна после удаления реплики")
+	replicas, err = WaitReplicasCaughtUp(ctx, f.session, request.ReplicasCount, statusPollAttempts, statusPollInterval)
+	a.NoError(err, "оставшиеся реплики не синхронизированы после удаления реплики")
+	Logf(t, "Replicas after scale-in: %+v", replicas)
+	a.Equal("OK", GetCluster(t, f.clusterId).Status)
+	writes += stats.Writes
+
+	f.verifyNoDataLoss(t, ctx, writes)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// ReplicaState представляет строку pg_stat_replication на мастере.
+type ReplicaState struct {
+	ApplicationName string
+	State           string
+	// CaughtUp равно true, если реплика применила WAL до позиции, запрошенной в WaitReplicasCaughtUp
+	CaughtUp bool
+}
+
+// ReplicationStatus возвращает состояние реплик относительно позиции WAL targetLSN
+func ReplicationStatus(ctx context.Context, s *DBSession, targetLSN string) ([]ReplicaState, error) {
+	var replicas []ReplicaState
+	err := s.Query(ctx, `
+		SELECT application_name, state, coalesce(replay_lsn >= $1::pg_lsn, false)
+		FROM pg_stat_replication
+	`, []interface{}{targetLSN}, func(rows pgx.Rows) error {
+		var replica ReplicaState
+		if err := rows.Scan(&replica.ApplicationName, &replica.State, &replica.CaughtUp); err != nil {
+			return err
+		}
+		replicas = append(replicas, replica)
+		return nil
+	})
+	return replicas, err
+}
+
+// WaitReplicasCaughtUp фиксирует текущую позицию WAL на мастере и ждёт, пока ровно expected реплик
+// в состоянии streaming применят WAL до этой позиции
+func WaitReplicasCaughtUp(ctx context.Context, s *DBSession, expected, attempts int, interval time.Duration) ([]ReplicaState, error) {
+	var targetLSN string
+	if err := s.QueryRow(ctx, `SELECT pg_current_wal_lsn()::text`, nil, &targetLSN); err != nil {
+		return nil, err
+	}
+
+	var replicas []ReplicaState
+	for i := 0; i < attempts; i++ {
+		var err error
+		replicas, err = ReplicationStatus(ctx, s, targetLSN)
+		if err != nil {
+			return nil, err
+		}
+
+		caughtUp := 0
+		for _, replica := range replicas {
+			if replica.State == "streaming" && replica.CaughtUp {
+				caughtUp++
+			}
+		}
+		if len(replicas) == expected && caughtUp == expected {
+			return replicas, nil
+		}
+		time.Sleep(interval)
+	}
+	return replicas, fmt.Errorf("ожидалось %d синхронизированных реплик на позиции %s, состояние: %+v", expected, targetLSN, replicas)
+}
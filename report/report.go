@@ -0,0 +1,213 @@
+// Package report собирает результаты сценариев: шаги, их длительность, вызовы API, созданные
+// ресурсы и ошибки, и выводит их в формате JUnit XML для CI и в JSON для дашбордов.
+package report
+
+import (
+	"sync"
+	"time"
+)
+
+// Status - итог теста или шага
+type Status string
+
+// Итоги тестов и шагов
+const (
+	StatusPassed  Status = "passed"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// Report представляет отчёт об одном запуске тестов
+type Report struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Tests      []*Test   `json:"tests"`
+}
+
+// Test представляет тест или подтест
+type Test struct {
+	Name       string    `json:"name"`
+	Status     Status    `json:"status"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs float64   `json:"duration_ms"`
+	Steps      []*Step   `json:"steps,omitempty"`
+	// Calls содержит вызовы API вне шагов
+	Calls     []Call     `json:"calls,omitempty"`
+	Resources []Resource `json:"resources,omitempty"`
+	Failures  []Failure  `json:"failures,omitempty"`
+}
+
+// Step представляет шаг сценария
+type Step struct {
+	Name       string    `json:"name"`
+	Status     Status    `json:"status"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs float64   `json:"duration_ms"`
+	Calls      []Call    `json:"calls,omitempty"`
+}
+
+// Call представляет вызов API. Status равен 0, если ответ не получен
+type Call struct {
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	Status    int     `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Resource представляет ресурс API, созданный тестом
+type Resource struct {
+	Kind      string `json:"kind"`
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	ClusterID string `json:"cluster_id,omitempty"`
+}
+
+// Failure представляет ошибку теста; Step пуст, если ошибка произошла вне шагов
+type Failure struct {
+	Step    string `json:"step,omitempty"`
+	Message string `json:"message"`
+}
+
+// Milliseconds переводит длительность в миллисекунды для отчёта
+func Milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Recorder собирает отчёт по мере выполнения тестов. Методы безопасны для конкурентного вызова;
+// обращения к тесту, запись которого не начата, начинают её
+type Recorder struct {
+	mu     sync.Mutex
+	report Report
+	tests  map[string]*Test
+	// current содержит выполняемый шаг теста
+	current map[string]*Step
+}
+
+// NewRecorder создаёт пустой отчёт с текущим временем начала запуска
+func NewRecorder() *Recorder {
+	return &Recorder{
+		report:  Report{StartedAt: time.Now()},
+		tests:   make(map[string]*Test),
+		current: make(map[string]*Step),
+	}
+}
+
+// StartTest начинает запись теста и возвращает false, если она уже начата
+func (r *Recorder) StartTest(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tests[name]; ok {
+		return false
+	}
+	r.test(name)
+	return true
+}
+
+// EndTest фиксирует итог и длительность теста
+func (r *Recorder) EndTest(name string, status Status) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	test := r.test(name)
+	test.Status = status
+	test.DurationMs = Milliseconds(time.Since(test.StartedAt))
+	if status == StatusFailed && len(test.Failures) == 0 {
+		test.Failures = append(test.Failures, Failure{Message: "тест завершился с ошибкой, подробности в выводе go test"})
+	}
+	delete(r.current, name)
+}
+
+// StartStep начинает шаг теста; последующие вызовы API относятся к нему
+func (r *Recorder) StartStep(test, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	step := &Step{Name: name, StartedAt: time.Now()}
+	t := r.test(test)
+	t.Steps = append(t.Steps, step)
+	r.current[test] = step
+}
+
+// EndStep фиксирует итог и длительность текущего шага теста. Для проваленного шага без
+// записанных ошибок добавляется message
+func (r *Recorder) EndStep(test string, status Status, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	step, ok := r.current[test]
+	if !ok {
+		return
+	}
+	delete(r.current, test)
+	step.Status = status
+	step.DurationMs = Milliseconds(time.Since(step.StartedAt))
+	if status != StatusFailed {
+		return
+	}
+	t := r.test(test)
+	for _, f := range t.Failures {
+		if f.Step == step.Name {
+			return
+		}
+	}
+	t.Failures = append(t.Failures, Failure{Step: step.Name, Message: message})
+}
+
+// SkipStep записывает шаг, который не выполнялся
+func (r *Recorder) SkipStep(test, name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.test(test)
+	t.Steps = append(t.Steps, &Step{Name: name, Status: StatusSkipped, StartedAt: time.Now()})
+}
+
+// Call записывает вызов API в текущий шаг или, если шаг не выполняется, в тест
+func (r *Recorder) Call(test string, call Call) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if step, ok := r.current[test]; ok {
+		step.Calls = append(step.Calls, call)
+		return
+	}
+	t := r.test(test)
+	t.Calls = append(t.Calls, call)
+}
+
+// Resource записывает ресурс, созданный тестом
+func (r *Recorder) Resource(test string, resource Resource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := r.test(test)
+	t.Resources = append(t.Resources, resource)
+}
+
+// Failure записывает ошибку теста в текущем шаге
+func (r *Recorder) Failure(test, message string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	failure := Failure{Message: message}
+	if step, ok := r.current[test]; ok {
+		failure.Step = step.Name
+	}
+	t := r.test(test)
+	t.Failures = append(t.Failures, failure)
+}
+
+// Report возвращает отчёт с текущим временем окончания запуска. Тесты перечислены в порядке начала
+func (r *Recorder) Report() Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := r.report
+	report.FinishedAt = time.Now()
+	report.Tests = append([]*Test(nil), r.report.Tests...)
+	return report
+}
+
+// test возвращает запись теста, создавая её при первом обращении. Вызывается под r.mu
+func (r *Recorder) test(name string) *Test {
+	if t, ok := r.tests[name]; ok {
+		return t
+	}
+	t := &Test{Name: name, StartedAt: time.Now()}
+	r.tests[name] = t
+	r.report.Tests = append(r.report.Tests, t)
+	return t
+}
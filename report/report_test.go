@@ -0,0 +1,92 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// record воспроизводит запуск: сценарий с шагами, один из которых провален, и пропущенный тест
+func record() Report {
+	r := NewRecorder()
+	r.StartTest("TestEndToEnd")
+	r.Call("TestEndToEnd", Call{Method: "POST", Path: "/api/authorize", Status: 200, LatencyMs: 12})
+	r.StartStep("TestEndToEnd", "create-cluster")
+	r.Call("TestEndToEnd", Call{Method: "POST", Path: "/api/clusters", Status: 201, LatencyMs: 150})
+	r.Resource("TestEndToEnd", Resource{Kind: "cluster", ID: "c1", Name: "test"})
+	r.EndStep("TestEndToEnd", StatusPassed, "")
+	r.SkipStep("TestEndToEnd", "create-db")
+	r.StartStep("TestEndToEnd", "create-dump")
+	r.Call("TestEndToEnd", Call{Method: "POST", Path: "/api/clusters/c1/databases/d1/dumps", Status: 500, LatencyMs: 30})
+	r.Failure("TestEndToEnd", "Ожидался статус 201, получен: 500")
+	r.EndStep("TestEndToEnd", StatusFailed, "шаг прерван")
+	r.EndTest("TestEndToEnd", StatusFailed)
+
+	r.StartTest("TestSkipped")
+	r.EndTest("TestSkipped", StatusSkipped)
+	return r.Report()
+}
+
+func TestRecorder(t *testing.T) {
+	report := record()
+	if !assert.Len(t, report.Tests, 2) {
+		return
+	}
+	test := report.Tests[0]
+	assert.Equal(t, StatusFailed, test.Status)
+	assert.Len(t, test.Calls, 1, "авторизация выполнена вне шагов")
+	if assert.Len(t, test.Steps, 3) {
+		assert.Len(t, test.Steps[0].Calls, 1)
+		assert.Equal(t, StatusSkipped, test.Steps[1].Status)
+		assert.Equal(t, StatusFailed, test.Steps[2].Status)
+	}
+	// Сообщение EndStep не дублирует уже записанную ошибку шага
+	assert.Equal(t, []Failure{{Step: "create-dump", Message: "Ожидался статус 201, получен: 500"}}, test.Failures)
+	assert.Equal(t, []Resource{{Kind: "cluster", ID: "c1", Name: "test"}}, test.Resources)
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteJUnit(&buf, record()))
+
+	var suites junitSuites
+	assert.NoError(t, xml.Unmarshal(buf.Bytes(), &suites), buf.String())
+	assert.Equal(t, 5, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+	assert.Equal(t, 2, suites.Skipped)
+	if !assert.Len(t, suites.Suites, 2) {
+		return
+	}
+	e2e := suites.Suites[0]
+	if assert.NotNil(t, e2e.Properties) {
+		assert.Equal(t, []junitProperty{{Name: "cluster", Value: "test c1"}}, e2e.Properties.Property)
+	}
+	assert.Nil(t, suites.Suites[1].Properties)
+	if assert.Len(t, e2e.Cases, 4) {
+		assert.Equal(t, "create-cluster", e2e.Cases[0].Name)
+		assert.Contains(t, e2e.Cases[0].SystemOut, "POST /api/clusters 201 150.0ms")
+		assert.NotNil(t, e2e.Cases[1].Skipped)
+		if assert.NotNil(t, e2e.Cases[2].Failure) {
+			assert.Equal(t, "Ожидался статус 201, получен: 500", e2e.Cases[2].Failure.Message)
+		}
+		assert.Equal(t, "(вне шагов)", e2e.Cases[3].Name)
+		assert.Nil(t, e2e.Cases[3].Failure)
+	}
+	if assert.Len(t, suites.Suites[1].Cases, 1) {
+		assert.Equal(t, "TestSkipped", suites.Suites[1].Cases[0].Name)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteJSON(&buf, record()))
+
+	var decoded Report
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "create-dump", decoded.Tests[0].Failures[0].Step)
+	assert.Equal(t, 500, decoded.Tests[0].Steps[2].Calls[0].Status)
+	assert.Contains(t, buf.String(), `"latency_ms": 30`)
+}
@@ -0,0 +1,176 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteJSON выводит отчёт в JSON
+func WriteJSON(w io.Writer, report Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// junitSuites - корневой элемент JUnit XML
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+// junitSuite соответствует тесту; его шаги становятся testcase
+type junitSuite struct {
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       string           `xml:"time,attr"`
+	Timestamp  string           `xml:"timestamp,attr"`
+	Properties *junitProperties `xml:"properties,omitempty"`
+	Cases      []junitCase      `xml:"testcase"`
+}
+
+type junitProperties struct {
+	Property []junitProperty `xml:"property"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit выводит отчёт в формате JUnit XML: тест становится testsuite, его шаги — testcase,
+// а созданные ресурсы — свойствами testsuite. Вызовы API перечисляются в system-out.
+// Тест без шагов представлен одним testcase
+func WriteJUnit(w io.Writer, report Report) error {
+	suites := junitSuites{Name: "dbaas", Time: seconds(report.FinishedAt.Sub(report.StartedAt))}
+	for _, test := range report.Tests {
+		suite := junitSuite{
+			Name:      test.Name,
+			Time:      seconds(msDuration(test.DurationMs)),
+			Timestamp: test.StartedAt.UTC().Format(time.RFC3339),
+		}
+		if len(test.Resources) > 0 {
+			suite.Properties = &junitProperties{}
+			for _, r := range test.Resources {
+				suite.Properties.Property = append(suite.Properties.Property, junitProperty{Name: r.Kind, Value: resourceValue(r)})
+			}
+		}
+		if len(test.Steps) == 0 {
+			suite.Cases = append(suite.Cases, junitTestCase(test.Name, test.Name, test.Status, test.DurationMs, test.Calls, test.Failures))
+		} else {
+			// Ошибки вне шагов и вызовы API вне шагов относятся к тесту в целом
+			var outside []Failure
+			for _, f := range test.Failures {
+				if f.Step == "" {
+					outside = append(outside, f)
+				}
+			}
+			for _, step := range test.Steps {
+				var failures []Failure
+				for _, f := range test.Failures {
+					if f.Step == step.Name {
+						failures = append(failures, f)
+					}
+				}
+				suite.Cases = append(suite.Cases, junitTestCase(step.Name, test.Name, step.Status, step.DurationMs, step.Calls, failures))
+			}
+			if len(outside) > 0 || len(test.Calls) > 0 {
+				status := StatusPassed
+				if len(outside) > 0 {
+					status = StatusFailed
+				}
+				suite.Cases = append(suite.Cases, junitTestCase("(вне шагов)", test.Name, status, 0, test.Calls, outside))
+			}
+		}
+		for _, c := range suite.Cases {
+			suite.Tests++
+			if c.Failure != nil {
+				suite.Failures++
+			}
+			if c.Skipped != nil {
+				suite.Skipped++
+			}
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitTestCase(name, className string, status Status, durationMs float64, calls []Call, failures []Failure) junitCase {
+	c := junitCase{Name: name, ClassName: className, Time: seconds(msDuration(durationMs))}
+	switch status {
+	case StatusSkipped:
+		c.Skipped = &struct{}{}
+	case StatusFailed:
+		var messages []string
+		for _, f := range failures {
+			messages = append(messages, f.Message)
+		}
+		if len(messages) == 0 {
+			messages = append(messages, "шаг завершился с ошибкой")
+		}
+		c.Failure = &junitFailure{Message: messages[0], Text: strings.Join(messages, "\n")}
+	}
+	var out strings.Builder
+	for _, call := range calls {
+		fmt.Fprintf(&out, "%s %s %d %.1fms", call.Method, call.Path, call.Status, call.LatencyMs)
+		if call.Error != "" {
+			fmt.Fprintf(&out, " %s", call.Error)
+		}
+		out.WriteString("\n")
+	}
+	c.SystemOut = out.String()
+	return c
+}
+
+func resourceValue(r Resource) string {
+	if r.Name == "" {
+		return r.ID
+	}
+	return r.Name + " " + r.ID
+}
+
+func msDuration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
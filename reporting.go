@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"dbaas_testing_task/redact"
+	"dbaas_testing_task/report"
+)
+
+// Отчёт о запуске пишется в JUnit XML (REPORT_JUNIT) и/или JSON (REPORT_JSON), если задан хотя бы один путь
+var (
+	reportJUnitFile = os.Getenv("REPORT_JUNIT")
+	reportJSONFile  = os.Getenv("REPORT_JSON")
+	reporter        = newReporter()
+)
+
+func newReporter() *report.Recorder {
+	if reportJUnitFile == "" && reportJSONFile == "" {
+		return nil
+	}
+	return report.NewRecorder()
+}
+
+// reportTest начинает запись теста в отчёт при первом обращении и фиксирует его итог по завершении
+func reportTest(t *testing.T) {
+	if reporter == nil || !reporter.StartTest(t.Name()) {
+		return
+	}
+	t.Cleanup(func() { reporter.EndTest(t.Name(), testStatus(t)) })
+}
+
+func testStatus(t *testing.T) report.Status {
+	switch {
+	case t.Skipped():
+		return report.StatusSkipped
+	case t.Failed():
+		return report.StatusFailed
+	}
+	return report.StatusPassed
+}
+
+// reportCall записывает вызов API. status равен 0, если ответ не получен
+func reportCall(t *testing.T, method, path string, status int, latency time.Duration, err error) {
+	if reporter == nil {
+		return
+	}
+	reportTest(t)
+	call := report.Call{Method: method, Path: path, Status: status, LatencyMs: report.Milliseconds(latency)}
+	if err != nil {
+		call.Error = redact.String(err.Error())
+	}
+	reporter.Call(t.Name(), call)
+}
+
+// reportFailure записывает в отчёт причину ошибки теста
+func reportFailure(t *testing.T, format string, args ...interface{}) {
+	if reporter == nil {
+		return
+	}
+	reportTest(t)
+	reporter.Failure(t.Name(), redact.String(fmt.Sprintf(format, args...)))
+}
+
+// Fatalf записывает причину ошибки в отчёт и прерывает тест. Помощники вызывают его вместо t.Fatalf,
+// чтобы в отчёте о шаге была настоящая причина, а не ссылка на вывод go test. Секреты в сообщении
+// маскируются
+func Fatalf(t *testing.T, format string, args ...interface{}) {
+	t.Helper()
+	message := redact.String(fmt.Sprintf(format, args...))
+	reportFailure(t, "%s", message)
+	t.Fatal(message)
+}
+
+// Errorf записывает причину ошибки в отчёт и помечает тест проваленным
+func Errorf(t *testing.T, format string, args ...interface{}) {
+	t.Helper()
+	message := redact.String(fmt.Sprintf(format, args...))
+	reportFailure(t, "%s", message)
+	t.Error(message)
+}
+
+// Logf выводит сообщение в лог теста, маскируя секреты
+func Logf(t *testing.T, format string, args ...interface{}) {
+	t.Helper()
+	t.Log(redact.String(fmt.Sprintf(format, args...)))
+}
+
+// reportingT передаёт ошибки проверок testify в отчёт и маскирует в них секреты
+type reportingT struct {
+	*testing.T
+}
+
+func (r reportingT) Errorf(format string, args ...interface{}) {
+	r.Helper()
+	message := redact.String(fmt.Sprintf(format, args...))
+	// testify начинает сообщение с перевода строки и выравнивает его табуляциями
+	reportFailure(r.T, "%s", strings.TrimSpace(message))
+	r.T.Error(message)
+}
+
+// Assert возвращает проверки testify, ошибки которых записываются в отчёт
+func Assert(t *testing.T) *assert.Assertions {
+	return assert.New(reportingT{t})
+}
+
+// reportResource записывает в отчёт ресурс, созданный тестом
+func reportResource(t *testing.T, kind, id, clusterId, name string) {
+	if reporter == nil {
+		return
+	}
+	reportTest(t)
+	reporter.Resource(t.Name(), report.Resource{Kind: kind, ID: id, Name: name, ClusterID: clusterId})
+}
+
+// reportStepStart начинает шаг сценария в отчёте
+func reportStepStart(t *testing.T, name string) {
+	if reporter == nil {
+		return
+	}
+	reportTest(t)
+	reporter.StartStep(t.Name(), name)
+}
+
+// reportStepEnd фиксирует итог шага. finished равен false, если шаг прерван t.FailNow или t.SkipNow;
+// failedBefore - был ли тест провален до начала шага. Причины, записанные через Fatalf, Errorf и Assert,
+// уже есть в отчёте; сообщение ниже попадает в него, только если шаг провален в обход них
+func reportStepEnd(t *testing.T, finished, failedBefore bool) {
+	if reporter == nil {
+		return
+	}
+	switch {
+	case !finished && t.Skipped():
+		reporter.EndStep(t.Name(), report.StatusSkipped, "")
+	case !finished:
+		reporter.EndStep(t.Name(), report.StatusFailed, "шаг прерван, подробности в выводе go test")
+	case t.Failed() && !failedBefore:
+		reporter.EndStep(t.Name(), report.StatusFailed, "проверки шага завершились с ошибкой, подробности в выводе go test")
+	default:
+		reporter.EndStep(t.Name(), report.StatusPassed, "")
+	}
+}
+
+// reportSkippedStep записывает шаг, пропущенный при продолжении прерванного запуска
+func reportSkippedStep(t *testing.T, name string) {
+	if reporter == nil {
+		return
+	}
+	reportTest(t)
+	reporter.SkipStep(t.Name(), name)
+}
+
+// writeReports записывает собранный отчёт в заданные файлы
+func writeReports() error {
+	if reporter == nil {
+		return nil
+	}
+	r := reporter.Report()
+	if err := writeReport(reportJUnitFile, r, report.WriteJUnit); err != nil {
+		return err
+	}
+	return writeReport(reportJSONFile, r, report.WriteJSON)
+}
+
+func writeReport(path string, r report.Report, write func(w io.Writer, r report.Report) error) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("не удалось создать каталог отчёта: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("не удалось создать отчёт: %w", err)
+	}
+	if err := write(f, r); err != nil {
+		f.Close()
+		return fmt.Errorf("не удалось записать отчёт %s: %w", path, err)
+	}
+	return f.Close()
+}
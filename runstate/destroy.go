@@ -0,0 +1,82 @@
+package runstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"dbaas_testing_task/dbaas"
+)
+
+// Plan возвращает ресурсы, которые нужно удалить, в обратном порядке создания. Базы данных,
+// пользователи и tablespace кластеров из журнала не включаются: они удаляются вместе с кластером
+func (s *State) Plan() []Resource {
+	resources := s.List()
+	clusters := make(map[string]bool)
+	for _, r := range resources {
+		if r.Kind == KindCluster {
+			clusters[r.ID] = true
+		}
+	}
+	var plan []Resource
+	for i := len(resources) - 1; i >= 0; i-- {
+		r := resources[i]
+		switch r.Kind {
+		case KindDatabase, KindUser, KindTableSpace:
+			if clusters[r.ClusterID] {
+				continue
+			}
+		}
+		plan = append(plan, r)
+	}
+	return plan
+}
+
+// Destroy удаляет ресурсы журнала через client и убирает их из журнала, в том числе уже удалённые
+// в API. Кластеры удаляются с ожиданием, пока API не начнёт отвечать 404. Ошибка удаления одного
+// ресурса не останавливает удаление остальных; оставшиеся ресурсы можно удалить повторным вызовом.
+// log вызывается после удаления каждого ресурса
+func Destroy(ctx context.Context, client *dbaas.Client, s *State, interval time.Duration, log func(Resource)) ([]Resource, error) {
+	var destroyed []Resource
+	var errs []error
+	for _, r := range s.Plan() {
+		err := destroy(ctx, client, r, interval)
+		if err != nil && !dbaas.IsNotFound(err) {
+			errs = append(errs, fmt.Errorf("%s: %w", r, err))
+			continue
+		}
+		if err := s.Forget(r.Kind, r.ID); err != nil {
+			return destroyed, err
+		}
+		destroyed = append(destroyed, r)
+		if log != nil {
+			log(r)
+		}
+	}
+	return destroyed, errors.Join(errs...)
+}
+
+func destroy(ctx context.Context, client *dbaas.Client, r Resource, interval time.Duration) error {
+	switch r.Kind {
+	case KindCluster:
+		if err := client.DeleteCluster(ctx, r.ID); err != nil {
+			return err
+		}
+		return dbaas.WaitDeleted(ctx, interval, func(ctx context.Context) error {
+			_, err := client.GetCluster(ctx, r.ID)
+			return err
+		})
+	case KindDatabase:
+		return client.DeleteDatabase(ctx, r.ClusterID, r.ID)
+	case KindUser:
+		return client.DeleteClusterUser(ctx, r.ClusterID, r.ID)
+	case KindTableSpace:
+		return client.DeleteTableSpace(ctx, r.ClusterID, r.ID)
+	case KindDump:
+		return client.DeleteDump(ctx, r.ID)
+	case KindBackup:
+		return client.DeleteBackup(ctx, r.ID)
+	}
+	return fmt.Errorf("неизвестный вид ресурса %q", r.Kind)
+}
@@ -0,0 +1,188 @@
+// Package runstate ведёт журнал запуска тестов: ресурсы API, созданные тестами, и завершённые шаги.
+//
+// Журнал сохраняется в файл после каждого изменения, поэтому переживает аварийное завершение
+// go test. По нему можно продолжить прерванный сценарий с последнего завершённого шага или
+// удалить всё, что осталось после запуска.
+package runstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Виды ресурсов журнала
+const (
+	KindCluster    = "cluster"
+	KindDatabase   = "database"
+	KindUser       = "user"
+	KindTableSpace = "tablespace"
+	KindDump       = "dump"
+	KindBackup     = "backup"
+)
+
+// Resource представляет ресурс API, созданный тестом
+type Resource struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	// ClusterID задаётся для ресурсов внутри кластера: баз данных, пользователей, tablespace и дампов
+	ClusterID string    `json:"cluster_id,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Test      string    `json:"test,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (r Resource) String() string {
+	if r.Name == "" {
+		return fmt.Sprintf("%s %s", r.Kind, r.ID)
+	}
+	return fmt.Sprintf("%s %s (%s)", r.Kind, r.Name, r.ID)
+}
+
+// State представляет журнал запуска, связанный с файлом. Методы безопасны для конкурентного вызова
+type State struct {
+	// Resources перечислены в порядке создания
+	Resources []Resource `json:"resources"`
+	// Steps содержит завершённые шаги по имени теста
+	Steps map[string][]string `json:"steps,omitempty"`
+
+	path string
+	mu   sync.Mutex
+}
+
+// Open читает журнал из файла path. Отсутствующий файл означает пустой журнал
+func Open(path string) (*State, error) {
+	s := &State{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать журнал запуска: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("не удалось разобрать журнал запуска %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Path возвращает путь к файлу журнала
+func (s *State) Path() string {
+	return s.path
+}
+
+// Record добавляет ресурс в журнал. Время создания проставляется, если не задано
+func (s *State) Record(r Resource) error {
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now().UTC()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Resources = append(s.Resources, r)
+	return s.save()
+}
+
+// Forget удаляет ресурс из журнала, а для кластера — и ресурсы внутри него, которые API удаляет
+// вместе с кластером (дампы и резервные копии остаются). Ресурс, которого нет в журнале, не считается ошибкой
+func (s *State) Forget(kind, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.Resources[:0]
+	for _, r := range s.Resources {
+		if r.Kind == kind && r.ID == id {
+			continue
+		}
+		if kind == KindCluster && r.Kind != KindDump && r.Kind != KindBackup && r.ClusterID == id {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	if len(kept) == len(s.Resources) {
+		return nil
+	}
+	s.Resources = kept
+	return s.save()
+}
+
+// List возвращает копию ресурсов журнала в порядке создания
+func (s *State) List() []Resource {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Resource(nil), s.Resources...)
+}
+
+// Find ищет последний ресурс вида kind с именем name, созданный тестом test
+func (s *State) Find(test, kind, name string) (Resource, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.Resources) - 1; i >= 0; i-- {
+		r := s.Resources[i]
+		if r.Test == test && r.Kind == kind && r.Name == name {
+			return r, true
+		}
+	}
+	return Resource{}, false
+}
+
+// CompleteStep отмечает шаг step теста test как завершённый
+func (s *State) CompleteStep(test, step string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Steps == nil {
+		s.Steps = make(map[string][]string)
+	}
+	s.Steps[test] = append(s.Steps[test], step)
+	return s.save()
+}
+
+// StepCompleted проверяет, что шаг step теста test завершён
+func (s *State) StepCompleted(test, step string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, completed := range s.Steps[test] {
+		if completed == step {
+			return true
+		}
+	}
+	return false
+}
+
+// ResetSteps забывает завершённые шаги теста test, чтобы следующий запуск начался с начала
+func (s *State) ResetSteps(test string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.Steps[test]; !ok {
+		return nil
+	}
+	delete(s.Steps, test)
+	return s.save()
+}
+
+// save записывает журнал во временный файл и переименовывает его, чтобы прерванная запись
+// не оставила повреждённый журнал. Вызывается под s.mu
+func (s *State) save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("не удалось записать журнал запуска: %w", err)
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("не удалось записать журнал запуска: %w", err)
+	}
+	return nil
+}
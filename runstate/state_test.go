@@ -0,0 +1,100 @@
+package runstate
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"dbaas_testing_task/dbaas"
+	"dbaas_testing_task/dbaas/dbaastest"
+)
+
+func TestStatePersistsAcrossOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, err := Open(path)
+	assert.NoError(t, err)
+	assert.Empty(t, s.List())
+
+	assert.NoError(t, s.Record(Resource{Kind: KindCluster, ID: "c1", Name: "test", Test: "TestEndToEnd"}))
+	assert.NoError(t, s.Record(Resource{Kind: KindDatabase, ID: "d1", ClusterID: "c1", Name: "testDB", Test: "TestEndToEnd"}))
+	assert.NoError(t, s.CompleteStep("TestEndToEnd", "create-cluster"))
+
+	// Повторное открытие имитирует продолжение после аварийного завершения
+	s, err = Open(path)
+	assert.NoError(t, err)
+	assert.Len(t, s.List(), 2)
+	assert.True(t, s.StepCompleted("TestEndToEnd", "create-cluster"))
+	assert.False(t, s.StepCompleted("TestEndToEnd", "create-db"))
+	db, ok := s.Find("TestEndToEnd", KindDatabase, "testDB")
+	assert.True(t, ok)
+	assert.Equal(t, "d1", db.ID)
+
+	assert.NoError(t, s.Forget(KindDatabase, "d1"))
+	assert.NoError(t, s.ResetSteps("TestEndToEnd"))
+	s, err = Open(path)
+	assert.NoError(t, err)
+	assert.Len(t, s.List(), 1)
+	assert.False(t, s.StepCompleted("TestEndToEnd", "create-cluster"))
+}
+
+func TestForgetClusterWithChildren(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	assert.NoError(t, s.Record(Resource{Kind: KindCluster, ID: "c1", Name: "first"}))
+	assert.NoError(t, s.Record(Resource{Kind: KindDatabase, ID: "d1", ClusterID: "c1", Name: "app"}))
+	assert.NoError(t, s.Record(Resource{Kind: KindUser, ID: "u1", ClusterID: "c1", Name: "app"}))
+	assert.NoError(t, s.Record(Resource{Kind: KindTableSpace, ID: "t1", ClusterID: "c1", Name: "ts"}))
+	assert.NoError(t, s.Record(Resource{Kind: KindDump, ID: "p1", ClusterID: "c1", Name: "nightly"}))
+	assert.NoError(t, s.Record(Resource{Kind: KindCluster, ID: "c2", Name: "second"}))
+	assert.NoError(t, s.Record(Resource{Kind: KindDatabase, ID: "d2", ClusterID: "c2", Name: "app"}))
+
+	// Базы данных, пользователи и tablespace удаляются вместе с кластером, дампы остаются
+	assert.NoError(t, s.Forget(KindCluster, "c1"))
+	var left []string
+	for _, r := range s.List() {
+		left = append(left, r.ID)
+	}
+	assert.Equal(t, []string{"p1", "c2", "d2"}, left)
+}
+
+func TestDestroy(t *testing.T) {
+	server := dbaastest.NewServer(t)
+	client := server.Client()
+	ctx := context.Background()
+
+	request := dbaas.NewClusterRequest("journaled", "flavor-1", "type-1")
+	created, err := client.CreateCluster(ctx, request)
+	assert.NoError(t, err)
+	clusterID := created.Instances[0].ClusterID
+	db, err := client.CreateDatabase(ctx, clusterID, dbaas.CreateDBRequest{Name: "app", TableSpaceID: dbaastest.TableSpace.Id})
+	assert.NoError(t, err)
+	dump, err := client.CreateDump(ctx, clusterID, db.Id, dbaas.CreateDumpRequest{Name: "nightly"})
+	assert.NoError(t, err)
+
+	s, err := Open(filepath.Join(t.TempDir(), "state.json"))
+	assert.NoError(t, err)
+	assert.NoError(t, s.Record(Resource{Kind: KindCluster, ID: clusterID, Name: "journaled"}))
+	assert.NoError(t, s.Record(Resource{Kind: KindDatabase, ID: db.Id, ClusterID: clusterID, Name: "app"}))
+	assert.NoError(t, s.Record(Resource{Kind: KindDump, ID: dump.Id, ClusterID: clusterID, Name: "nightly"}))
+	// Ресурс, уже удалённый в API, тоже убирается из журнала
+	assert.NoError(t, s.Record(Resource{Kind: KindDump, ID: "dump-gone"}))
+
+	plan := s.Plan()
+	if assert.Len(t, plan, 3, "база данных удаляется вместе с кластером") {
+		assert.Equal(t, "dump-gone", plan[0].ID)
+		assert.Equal(t, dump.Id, plan[1].ID)
+		assert.Equal(t, clusterID, plan[2].ID)
+	}
+
+	server.ResetRequests()
+	destroyed, err := Destroy(ctx, client, s, time.Millisecond, nil)
+	assert.NoError(t, err)
+	assert.Len(t, destroyed, 3)
+	assert.Empty(t, s.List())
+	_, exists := server.Cluster("journaled")
+	assert.False(t, exists)
+	assert.NotContains(t, server.Requests, "DELETE /api/clusters/"+clusterID+"/databases/"+db.Id)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"testing"
+	"time"
+)
+
+// scalingFixture содержит кластер с тестовыми данными, на котором проверяется изменение ресурсов
+type scalingFixture struct {
+	clusterId    string
+	dbId         string
+	dbName       string
+	userName     string
+	userPassword string
+	session      *DBSession
+}
+
+// newScalingFixture создаёт кластер по запросу, базу данных, пользователя и тестовые данные
+func newScalingFixture(t *testing.T, ctx context.Context, request CreateClusterRequest) scalingFixture {
+	clusterId := ProvisionCluster(t, request)
+
+	dbName := "scaleDB"
+	dbId := CreateDatabase(t, clusterId, CreateDBRequest{Name: dbName, TableSpaceID: GetDefaultTableSpaceID(t, clusterId)})
+	userName, userPassword := "scale_"+RandomSuffix(), RandomPassword()
+	CreateClusterUser(t, clusterId, CreateClusterUserRequest{
+		Databases: []string{dbName},
+		// pg_monitor нужен для чтения pg_stat_replication
+		Roles:    []string{"pg_write_all_data", "pg_read_all_data", "pg_monitor"},
+		Name:     userName,
+		Password: userPassword,
+	})
+
+	session := NewDBSession(t, ctx, ConnectionString(t, clusterId, dbName, userName, userPassword), LoadPoolConfig(t))
+	if err := CreateTestSchema(ctx, session); err != nil {
+		Fatalf(t, "Не удалось подготовить данные: %v", err)
+	}
+	if err := SeedUsers(ctx, session, rand.New(rand.NewSource(time.Now().UnixNano())), 10); err != nil {
+		Fatalf(t, "Не удалось подготовить данные: %v", err)
+	}
+	if err := CreateWorkloadTable(ctx, session); err != nil {
+		Fatalf(t, "Не удалось подготовить данные: %v", err)
+	}
+	return scalingFixture{
+		clusterId:    clusterId,
+		dbId:         dbId,
+		dbName:       dbName,
+		userName:     userName,
+		userPassword: userPassword,
+		session:      session,
+	}
+}
+
+// runWithWorkload выполняет action, пока на базу данных идёт конкурентная нагрузка, и возвращает её итоги
+func (f scalingFixture) runWithWorkload(t *testing.T, ctx context.Context, action func()) WorkloadStats {
+	cfg := LoadWorkloadConfig(t, LoadPoolConfig(t))
+	// Нагрузка останавливается отменой контекста после завершения action
+	cfg.Duration = 24 * time.Hour
+
+	workloadCtx, cancel := context.WithCancel(ctx)
+	done := make(chan WorkloadStats, 1)
+	go func() { done <- RunWorkload(workloadCtx, f.session, cfg) }()
+
+	// Останавливаем нагрузку, даже если action прервёт тест
+	func() {
+		defer cancel()
+		action()
+	}()
+	stats := <-done
+	Logf(t, "Workload during change: %d reads, %d writes, %d errors (last: %s)", stats.Reads, stats.Writes, stats.Errors, stats.LastError)
+	return stats
+}
+
+// verifyNoDataLoss проверяет, что исходные данные на месте и все подтверждённые записи нагрузки сохранились
+func (f scalingFixture) verifyNoDataLoss(t *testing.T, ctx context.Context, writes int64) {
+	a := Assert(t)
+	users, err := FetchUsers(ctx, f.session)
+	a.NoError(err, "не удалось прочитать данные")
+	a.Equal(10, len(users), "Ожидалось 10 записей")
+
+	var events int64
+	err = f.session.QueryRow(ctx, `SELECT count(*) FROM test_schema.workload_events`, nil, &events)
+	a.NoError(err, "не удалось прочитать данные нагрузки")
+	a.GreaterOrEqual(events, writes, "потеряны подтверждённые записи нагрузки")
+}
+
+// pickLargerFlavor выбирает flavor для увеличения ресурсов: из DBAAS_SCALE_UP_FLAVOR
+// или ближайший по объёму памяти flavor больше текущего
+func pickLargerFlavor(t *testing.T, current Flavor) Flavor {
+	if name := os.Getenv("DBAAS_SCALE_UP_FLAVOR"); name != "" {
+		flavor, ok := FindFlavor(t, name)
+		if !ok {
+			Fatalf(t, "Flavor с именем %s не найден", name)
+		}
+		return flavor
+	}
+	var target Flavor
+	for _, flavor := range ListFlavors(t) {
+		if flavor.Ram > current.Ram && (target.Id == "" || flavor.Ram < target.Ram) {
+			target = flavor
+		}
+	}
+	if target.Id == "" {
+		t.Skipf("В каталоге нет flavor больше %s", current.Name)
+	}
+	return target
+}
+
+func TestClusterVerticalScaling(t *testing.T) {
+	a := Assert(t)
+	RequireAPIEnv(t)
+	Authorize(t)
+
+	ctx := context.Background()
+	request := DefaultClusterRequest(t, "scaling-"+RandomSuffix())
+	baseFlavor, ok := FindFlavor(t, "STD3-1-1")
+	if !ok {
+		Fatalf(t, "Flavor с именем STD3-1-1 не найден")
+	}
+	largerFlavor := pickLargerFlavor(t, baseFlavor)
+
+	f := newScalingFixture(t, ctx, request)
+	baseBuffers, err := ShowSettingBytes(ctx, f.session, "shared_buffers")
+	a.NoError(err, "не удалось прочитать shared_buffers")
+	Logf(t, "shared_buffers on %s: %d bytes", baseFlavor.Name, baseBuffers)
+
+	// Увеличение ресурсов под нагрузкой
+	stats := f.runWithWorkload(t, ctx, func() {
+		cluster := ResizeCluster(t, f.clusterId, largerFlavor.Id)
+		a.Equal(largerFlavor.Id, cluster.FlavorID)
+	})
+	a.NoError(f.session.WaitReady(ctx, statusPollAttempts, statusPollInterval), "база данных недоступна после увеличения ресурсов")
+	scaledBuffers, err := ShowSettingBytes(ctx, f.session, "shared_buffers")
+	a.NoError(err, "не удалось прочитать shared_buffers")
+	Logf(t, "shared_buffers on %s: %d bytes", largerFlavor.Name, scaledBuffers)
+	a.Greater(scaledBuffers, baseBuffers, "shared_buffers не увеличился после смены flavor")
+	writes := stats.Writes
+
+	// Уменьшение ресурсов под нагрузкой
+	stats = f.runWithWorkload(t, ctx, func() {
+		cluster := ResizeCluster(t, f.clusterId, baseFlavor.Id)
+		a.Equal(baseFlavor.Id, cluster.FlavorID)
+	})
+	a.NoError(f.session.WaitReady(ctx, statusPollAttempts, statusPollInterval), "база данных недоступна после уменьшения ресурсов")
+	restoredBuffers, err := ShowSettingBytes(ctx, f.session, "shared_buffers")
+	a.NoError(err, "не удалось прочитать shared_buffers")
+	a.Equal(baseBuffers, restoredBuffers, "shared_buffers не вернулся к исходному значению")
+	writes += stats.Writes
+
+	f.verifyNoDataLoss(t, ctx, writes)
+}
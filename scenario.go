@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Количество попыток и интервал опроса статуса ресурса. Интервал обнуляется при воспроизведении кассеты
+const statusPollAttempts = 30
+
+var statusPollInterval = 5 * time.Second
+
+// RequireAPIEnv пропускает тест, если не заданы переменные окружения для работы с API
+func RequireAPIEnv(t *testing.T) {
+	reportTest(t)
+	if apiBaseURL == "" || login == "" || password == "" {
+		t.Skip("Не заданы API_BASE_URL, API_LOGIN и/или API_PASSWORD")
+	}
+}
+
+// authHeaders возвращает заголовки авторизованного запроса к API
+func authHeaders() map[string]string {
+	return map[string]string{
+		"Authorization": "Bearer " + refreshToken,
+		"Content-Type":  "application/json",
+	}
+}
+
+// apiURL формирует полный адрес метода API
+func apiURL(format string, args ...interface{}) string {
+	return apiBaseURL + fmt.Sprintf(format, args...)
+}
+
+// Генератор суффиксов имён. При записи и воспроизведении кассеты он инициализируется seed
+// из её заголовка, чтобы имена ресурсов совпадали
+var (
+	suffixMu   sync.Mutex
+	suffixRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// seedSuffixes задаёт seed генератора суффиксов имён
+func seedSuffixes(seed int64) {
+	suffixMu.Lock()
+	defer suffixMu.Unlock()
+	suffixRand = rand.New(rand.NewSource(seed))
+}
+
+// RandomSuffix возвращает короткий случайный суффикс для имён создаваемых ресурсов
+func RandomSuffix() string {
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	suffixMu.Lock()
+	defer suffixMu.Unlock()
+	b := make([]byte, 6)
+	for i := range b {
+		b[i] = letters[suffixRand.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+// WaitForStatus опрашивает ресурс по адресу url, пока его статус не станет OK.
+// kind используется только в сообщениях лога
+func WaitForStatus(t *testing.T, kind, url string) {
+	var statusResponse struct {
+		Status string `json:"status"`
+	}
+	for i := 0; i < statusPollAttempts; i++ {
+		resp, _ := makeRequest(t, "GET", url, nil, authHeaders())
+		parseResponseBody(t, resp, &statusResponse)
+
+		if statusResponse.Status == "OK" {
+			return
+		}
+		Logf(t, "%s status is %s, waiting for OK at %s", kind, statusResponse.Status, time.Now().Format("2006-01-02 15:04:05.000"))
+		time.Sleep(statusPollInterval)
+	}
+	Fatalf(t, "%s не перешёл в статус OK, последний статус: %s", kind, statusResponse.Status)
+}
+
+// ConnectionString возвращает строку подключения к мастеру для базы данных dbName от имени пользователя
+func ConnectionString(t *testing.T, clusterId, dbName, user, pass string) string {
+	skipDatabaseOnReplay(t)
+	db, ok := FindDatabase(t, clusterId, dbName)
+	if !ok {
+		Fatalf(t, "База данных %s не найдена в кластере %s", dbName, clusterId)
+	}
+	return connectionString(db, user, pass)
+}
+
+// connectionString подставляет пользователя и пароль в строку подключения к мастеру базы данных db
+func connectionString(db Database, user, pass string) string {
+	conString := strings.Replace(db.MasterConnectionString, "<username>", user, 1)
+	return strings.Replace(conString, "<password>", pass, 1)
+}
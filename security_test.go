@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/base64"
+	"net/http"
+	"os"
+	"testing"
+)
+
+// endpointStatus вызывает метод API с указанными заголовками и возвращает статус ответа
+func endpointStatus(t *testing.T, e Endpoint, ids map[string]string, headers map[string]string) int {
+	var body interface{}
+	if e.Method == "POST" || e.Method == "PATCH" {
+		body = map[string]interface{}{}
+	}
+	resp, _ := makeRequest(t, e.Method, e.URL(ids), body, headers)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// assertConsistentStatus проверяет, что все методы ответили одним и тем же допустимым статусом
+func assertConsistentStatus(t *testing.T, statuses map[Endpoint]int, allowed []int) {
+	a := Assert(t)
+	seen := make(map[int][]string)
+	for e, status := range statuses {
+		a.Contains(allowed, status, "%s: неожиданный статус", e)
+		seen[status] = append(seen[status], e.String())
+	}
+	a.Len(seen, 1, "методы API отвечают разными статусами: %v", seen)
+}
+
+func TestAuthorizeInvalidCredentials(t *testing.T) {
+	RequireAPIEnv(t)
+
+	cases := []struct {
+		name       string
+		user, pass string
+		statuses   []int
+	}{
+		{"wrong password", login, password + "-wrong", []int{http.StatusUnauthorized}},
+		{"unknown login", "unknown-" + RandomSuffix(), password, []int{http.StatusUnauthorized}},
+		{"empty password", login, "", []int{http.StatusBadRequest, http.StatusUnauthorized}},
+		{"empty credentials", "", "", []int{http.StatusBadRequest, http.StatusUnauthorized}},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			a := Assert(t)
+			status, token := TryAuthorize(t, tc.user, tc.pass)
+			a.Contains(tc.statuses, status, "неожиданный статус авторизации")
+			a.Empty(token, "токен выдан при неверных учётных данных")
+		})
+	}
+}
+
+func TestAuthorizationAndTenancy(t *testing.T) {
+	RequireAPIEnv(t)
+	Authorize(t)
+	// Тест намеренно отправляет запросы, нарушающие спецификацию
+	SkipRequestContract(t)
+
+	// Ресурсы первого арендатора, к которым обращаются все методы API
+	clusterId := ProvisionCluster(t, DefaultClusterRequest(t, "security-"+RandomSuffix()))
+	tableSpaceId := GetDefaultTableSpaceID(t, clusterId)
+	dbName := "securityDB"
+	dbId := CreateDatabase(t, clusterId, CreateDBRequest{Name: dbName, TableSpaceID: tableSpaceId})
+	user := CreateClusterUser(t, clusterId, CreateClusterUserRequest{
+		Databases: []string{dbName},
+		Roles:     []string{"pg_read_all_data"},
+		Name:      "security_" + RandomSuffix(),
+		Password:  RandomPassword(),
+	})
+	dumpId := CreateDump(t, clusterId, dbId, "security")
+	t.Cleanup(func() { DeleteDump(t, dumpId) })
+	backupId := CreateBackup(t, clusterId)
+	t.Cleanup(func() { DeleteBackup(t, backupId) })
+
+	ids := map[string]string{
+		"cluster_id":    clusterId,
+		"tablespace_id": tableSpaceId,
+		"database_id":   dbId,
+		"user_id":       user.Id,
+		"dump_id":       dumpId,
+		"backup_id":     backupId,
+	}
+
+	// Запросы без действительного токена должны отклоняться со статусом 401 на всех методах
+	variants := []struct {
+		name          string
+		authorization string
+	}{
+		{"missing token", ""},
+		{"empty bearer", "Bearer "},
+		{"malformed token", "Bearer not-a-token"},
+		{"tampered token", "Bearer " + refreshToken + "x"},
+		{"wrong scheme", "Basic " + base64.StdEncoding.EncodeToString([]byte(login+":"+password))},
+		{"expired token", os.Getenv("API_EXPIRED_TOKEN")},
+	}
+	for _, v := range variants {
+		v := v
+		t.Run(v.name, func(t *testing.T) {
+			if v.name == "expired token" {
+				if v.authorization == "" {
+					t.Skip("Не задана переменная окружения API_EXPIRED_TOKEN")
+				}
+				v.authorization = "Bearer " + v.authorization
+			}
+			headers := map[string]string{"Content-Type": "application/json"}
+			if v.authorization != "" {
+				headers["Authorization"] = v.authorization
+			}
+
+			statuses := make(map[Endpoint]int)
+			for _, e := range KnownEndpoints {
+				statuses[e] = endpointStatus(t, e, ids, headers)
+			}
+			assertConsistentStatus(t, statuses, []int{http.StatusUnauthorized})
+		})
+	}
+
+	// Второй арендатор не должен видеть и менять ресурсы первого
+	t.Run("other tenant", func(t *testing.T) {
+		a := Assert(t)
+		otherLogin, otherPassword := os.Getenv("API_LOGIN_2"), os.Getenv("API_PASSWORD_2")
+		if otherLogin == "" || otherPassword == "" {
+			t.Skip("Не заданы переменные окружения API_LOGIN_2 и/или API_PASSWORD_2")
+		}
+		headers := map[string]string{
+			"Authorization": "Bearer " + AuthorizeAs(t, otherLogin, otherPassword),
+			"Content-Type":  "application/json",
+		}
+
+		statuses := make(map[Endpoint]int)
+		for _, e := range KnownEndpoints {
+			if e.IsTenantScoped() {
+				statuses[e] = endpointStatus(t, e, ids, headers)
+			}
+		}
+		assertConsistentStatus(t, statuses, []int{http.StatusForbidden, http.StatusNotFound})
+
+		// Кластер первого арендатора не должен попадать в список кластеров второго
+		resp, _ := makeRequest(t, "GET", apiURL("/api/clusters"), nil, headers)
+		var clusters []Cluster
+		parseResponseBody(t, resp, &clusters)
+		for _, c := range clusters {
+			a.NotEqual(clusterId, c.Id, "кластер первого арендатора виден второму")
+		}
+
+		// Ресурсы первого арендатора не пострадали
+		a.Equal("OK", GetCluster(t, clusterId).Status)
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PgSetting представляет строку pg_settings.
+type PgSetting struct {
+	Name           string
+	Setting        string
+	Context        string
+	PendingRestart bool
+}
+
+// ShowSettingBytes возвращает значение параметра Postgres, заданного в единицах памяти, в байтах
+func ShowSettingBytes(ctx context.Context, s *DBSession, name string) (int64, error) {
+	var value int64
+	err := s.QueryRow(ctx, `SELECT pg_size_bytes(current_setting($1))`, []interface{}{name}, &value)
+	return value, err
+}
+
+// QueryPgSetting читает параметр Postgres из pg_settings
+func QueryPgSetting(ctx context.Context, s *DBSession, name string) (PgSetting, error) {
+	setting := PgSetting{Name: name}
+	err := s.QueryRow(ctx, `
+		SELECT setting, context, pending_restart
+		FROM pg_settings
+		WHERE name = $1
+	`, []interface{}{name}, &setting.Setting, &setting.Context, &setting.PendingRestart)
+	return setting, err
+}
+
+// PostmasterStartTime возвращает время запуска сервера, по которому определяется факт перезапуска
+func PostmasterStartTime(ctx context.Context, s *DBSession) (time.Time, error) {
+	var started time.Time
+	err := s.QueryRow(ctx, `SELECT pg_postmaster_start_time()`, nil, &started)
+	return started, err
+}
+
+// WaitForSetting опрашивает pg_settings, пока параметр name не удовлетворит условию ready
+func WaitForSetting(ctx context.Context, s *DBSession, name string, attempts int, interval time.Duration, ready func(PgSetting) bool) (PgSetting, error) {
+	var setting PgSetting
+	var err error
+	for i := 0; i < attempts; i++ {
+		// Ошибки возможны во время перезапуска сервера, поэтому продолжаем опрос
+		setting, err = QueryPgSetting(ctx, s, name)
+		if err == nil && ready(setting) {
+			return setting, nil
+		}
+		time.Sleep(interval)
+	}
+	if err != nil {
+		return setting, err
+	}
+	return setting, fmt.Errorf("параметр %s не достиг ожидаемого состояния, текущее: %+v", name, setting)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"dbaas_testing_task/dbaas"
+)
+
+// SoakConfig содержит параметры длительного прогона под нагрузкой.
+type SoakConfig struct {
+	// Duration - длительность прогона; 0 выключает его
+	Duration time.Duration
+	// Window - интервал, за который считается статистика и опрашивается статус кластера
+	Window time.Duration
+	// DumpInterval - период циклов дампа и восстановления; 0 выключает их
+	DumpInterval time.Duration
+	// MaxErrorRate - допустимая доля неудачных операций нагрузки за весь прогон
+	MaxErrorRate float64
+	// MaxAPIErrors - допустимое число неудачных запросов к API за весь прогон
+	MaxAPIErrors int64
+	// SummaryFile - путь к итогам прогона в JSON; пустое значение означает только вывод в лог
+	SummaryFile string
+}
+
+const (
+	// soakReauthorizeInterval - период повторной авторизации, чтобы токен не истёк за время прогона
+	soakReauthorizeInterval = 15 * time.Minute
+	// soakWaitTimeout ограничивает ожидание статуса OK одного ресурса
+	soakWaitTimeout = statusPollAttempts * dbaas.DefaultPollInterval
+	// soakCycleTimeout ограничивает цикл дампа: создание дампа и базы данных, восстановление и выдачу доступа
+	soakCycleTimeout = 4 * soakWaitTimeout
+)
+
+// soakClient возвращает клиент API с текущим токеном. В отличие от помощников на makeRequest
+// он возвращает ошибки, а не завершает тест, поэтому разовый сбой API не прерывает прогон
+func soakClient() *dbaas.Client {
+	client := dbaas.NewClient(apiBaseURL)
+	client.Token = refreshToken
+	client.HTTPClient = &http.Client{Transport: httpTransport, Timeout: time.Minute}
+	return client
+}
+
+// LoadSoakConfig считывает параметры длительного прогона из переменных окружения
+func LoadSoakConfig(t *testing.T) SoakConfig {
+	cfg := SoakConfig{
+		Window:       time.Minute,
+		DumpInterval: 30 * time.Minute,
+		MaxErrorRate: 0.01,
+		MaxAPIErrors: 5,
+		SummaryFile:  os.Getenv("SOAK_SUMMARY"),
+	}
+	for name, target := range map[string]*time.Duration{
+		"SOAK_DURATION":      &cfg.Duration,
+		"SOAK_WINDOW":        &cfg.Window,
+		"SOAK_DUMP_INTERVAL": &cfg.DumpInterval,
+	} {
+		if v := os.Getenv(name); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				Fatalf(t, "Некорректное значение %s: %q", name, v)
+			}
+			*target = d
+		}
+	}
+	if cfg.Window <= 0 {
+		Fatalf(t, "Некорректное значение SOAK_WINDOW: %s", cfg.Window)
+	}
+	if v := os.Getenv("SOAK_MAX_ERROR_RATE"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			Fatalf(t, "Некорректное значение SOAK_MAX_ERROR_RATE: %q", v)
+		}
+		cfg.MaxErrorRate = rate
+	}
+	if v := os.Getenv("SOAK_MAX_API_ERRORS"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			Fatalf(t, "Некорректное значение SOAK_MAX_API_ERRORS: %q", v)
+		}
+		cfg.MaxAPIErrors = n
+	}
+	return cfg
+}
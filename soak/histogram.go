@@ -0,0 +1,98 @@
+package soak
+
+import (
+	"math"
+	"time"
+)
+
+// Границы корзин гистограммы растут в bucketGrowth раз, начиная с minLatency; относительная
+// погрешность перцентилей не превышает 10%
+const (
+	minLatency   = 10 * time.Microsecond
+	bucketGrowth = 1.1
+	bucketCount  = 200
+)
+
+// Histogram хранит распределение задержек в корзинах фиксированного размера, поэтому занимает
+// одинаковую память при любой длительности прогона
+type Histogram struct {
+	counts [bucketCount + 1]int64
+	total  int64
+	sum    time.Duration
+	max    time.Duration
+}
+
+// Add учитывает задержку d
+func (h *Histogram) Add(d time.Duration) {
+	h.counts[bucket(d)]++
+	h.total++
+	h.sum += d
+	if d > h.max {
+		h.max = d
+	}
+}
+
+// Merge добавляет к гистограмме значения other
+func (h *Histogram) Merge(other *Histogram) {
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+	h.total += other.total
+	h.sum += other.sum
+	if other.max > h.max {
+		h.max = other.max
+	}
+}
+
+// Count возвращает число учтённых задержек
+func (h *Histogram) Count() int64 { return h.total }
+
+// Mean возвращает среднюю задержку
+func (h *Histogram) Mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.total)
+}
+
+// Max возвращает наибольшую задержку
+func (h *Histogram) Max() time.Duration { return h.max }
+
+// Quantile возвращает верхнюю границу корзины, в которую попадает перцентиль q (от 0 до 1),
+// но не больше наибольшей задержки
+func (h *Histogram) Quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(q * float64(h.total)))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			// Последняя корзина не ограничена сверху
+			if upper := bucketUpper(i); i < bucketCount && upper < h.max {
+				return upper
+			}
+			return h.max
+		}
+	}
+	return h.max
+}
+
+func bucket(d time.Duration) int {
+	if d <= minLatency {
+		return 0
+	}
+	i := int(math.Ceil(math.Log(float64(d)/float64(minLatency)) / math.Log(bucketGrowth)))
+	if i > bucketCount {
+		return bucketCount
+	}
+	return i
+}
+
+func bucketUpper(i int) time.Duration {
+	return time.Duration(float64(minLatency) * math.Pow(bucketGrowth, float64(i)))
+}
@@ -0,0 +1,190 @@
+// Package soak собирает статистику длительного прогона под нагрузкой: задержки и ошибки операций
+// по интервалам времени, смены статуса кластера и циклы дампа и восстановления.
+package soak
+
+import (
+	"sync"
+	"time"
+)
+
+// Window представляет итоги операций за интервал времени
+type Window struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Reads  int64     `json:"reads"`
+	Writes int64     `json:"writes"`
+	Errors int64     `json:"errors"`
+	// Задержки успешных операций в миллисекундах
+	P50Ms  float64 `json:"p50_ms"`
+	P95Ms  float64 `json:"p95_ms"`
+	P99Ms  float64 `json:"p99_ms"`
+	MaxMs  float64 `json:"max_ms"`
+	Status string  `json:"cluster_status,omitempty"`
+	// LastError хранит текст последней ошибки интервала
+	LastError string `json:"last_error,omitempty"`
+	// APIErrors - число неудачных запросов к API DBaaS: опросов статуса, повторной авторизации и шагов цикла дампа
+	APIErrors    int64  `json:"api_errors"`
+	LastAPIError string `json:"last_api_error,omitempty"`
+}
+
+// Operations возвращает число операций интервала, включая неудачные
+func (w Window) Operations() int64 { return w.Reads + w.Writes + w.Errors }
+
+// ErrorRate возвращает долю неудачных операций
+func (w Window) ErrorRate() float64 {
+	if w.Operations() == 0 {
+		return 0
+	}
+	return float64(w.Errors) / float64(w.Operations())
+}
+
+// StatusChange представляет смену статуса кластера
+type StatusChange struct {
+	At   time.Time `json:"at"`
+	From string    `json:"from"`
+	To   string    `json:"to"`
+}
+
+// Cycle представляет цикл дампа и восстановления в отдельную базу данных
+type Cycle struct {
+	Start     time.Time `json:"start"`
+	DumpMs    float64   `json:"dump_ms"`
+	RestoreMs float64   `json:"restore_ms"`
+	// Rows - число строк нагрузки, найденных в восстановленной базе данных
+	Rows  int64  `json:"rows"`
+	Error string `json:"error,omitempty"`
+	// Aborted означает, что цикл прерван ошибкой API и восстановленная база данных не проверялась
+	Aborted bool `json:"aborted,omitempty"`
+}
+
+// Summary представляет итоги прогона
+type Summary struct {
+	Start         time.Time      `json:"start"`
+	End           time.Time      `json:"end"`
+	Total         Window         `json:"total"`
+	Windows       []Window       `json:"windows"`
+	StatusChanges []StatusChange `json:"status_changes,omitempty"`
+	Cycles        []Cycle        `json:"cycles,omitempty"`
+}
+
+// Recorder собирает статистику прогона. Observe можно вызывать из нескольких горутин
+type Recorder struct {
+	mu      sync.Mutex
+	start   time.Time
+	current Window
+	latency Histogram
+	total   Window
+	overall Histogram
+	status  string
+	summary Summary
+}
+
+// NewRecorder начинает сбор статистики с момента start
+func NewRecorder(start time.Time) *Recorder {
+	return &Recorder{
+		start:   start,
+		current: Window{Start: start},
+		summary: Summary{Start: start},
+	}
+}
+
+// Observe учитывает операцию чтения или записи с задержкой latency; err != nil означает неудачу
+func (r *Recorder) Observe(write bool, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case err != nil:
+		r.current.Errors++
+		r.current.LastError = err.Error()
+		return
+	case write:
+		r.current.Writes++
+	default:
+		r.current.Reads++
+	}
+	r.latency.Add(latency)
+}
+
+// APIError учитывает неудачный запрос к API
+func (r *Recorder) APIError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current.APIErrors++
+	r.current.LastAPIError = err.Error()
+}
+
+// Status учитывает статус кластера, полученный в момент at, и сообщает, изменился ли он
+func (r *Recorder) Status(at time.Time, status string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current.Status = status
+	if status == r.status {
+		return false
+	}
+	if r.status != "" {
+		r.summary.StatusChanges = append(r.summary.StatusChanges, StatusChange{At: at, From: r.status, To: status})
+	}
+	r.status = status
+	return true
+}
+
+// Cycle учитывает цикл дампа и восстановления
+func (r *Recorder) Cycle(c Cycle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.summary.Cycles = append(r.summary.Cycles, c)
+}
+
+// CloseWindow завершает текущий интервал в момент end и возвращает его итоги
+func (r *Recorder) CloseWindow(end time.Time) Window {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	w := r.current
+	w.End = end
+	fillLatency(&w, &r.latency)
+	r.summary.Windows = append(r.summary.Windows, w)
+
+	r.total.Reads += w.Reads
+	r.total.Writes += w.Writes
+	r.total.Errors += w.Errors
+	if w.LastError != "" {
+		r.total.LastError = w.LastError
+	}
+	r.total.APIErrors += w.APIErrors
+	if w.LastAPIError != "" {
+		r.total.LastAPIError = w.LastAPIError
+	}
+	r.overall.Merge(&r.latency)
+
+	r.latency = Histogram{}
+	r.current = Window{Start: end}
+	return w
+}
+
+// Summary возвращает итоги прогона по завершённым интервалам
+func (r *Recorder) Summary() Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	summary := r.summary
+	summary.Windows = append([]Window(nil), r.summary.Windows...)
+	summary.StatusChanges = append([]StatusChange(nil), r.summary.StatusChanges...)
+	summary.Cycles = append([]Cycle(nil), r.summary.Cycles...)
+	summary.End = r.current.Start
+	summary.Total = r.total
+	summary.Total.Start, summary.Total.End = summary.Start, summary.End
+	summary.Total.Status = r.status
+	fillLatency(&summary.Total, &r.overall)
+	return summary
+}
+
+func fillLatency(w *Window, h *Histogram) {
+	w.P50Ms = milliseconds(h.Quantile(0.5))
+	w.P95Ms = milliseconds(h.Quantile(0.95))
+	w.P99Ms = milliseconds(h.Quantile(0.99))
+	w.MaxMs = milliseconds(h.Max())
+}
+
+// milliseconds переводит длительность в миллисекунды с точностью до микросекунды
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,93 @@
+package soak
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	var h Histogram
+	assert.Equal(t, time.Duration(0), h.Quantile(0.5))
+	for i := 1; i <= 1000; i++ {
+		h.Add(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, int64(1000), h.Count())
+	assert.Equal(t, 1000*time.Millisecond, h.Max())
+	assert.Equal(t, 500500*time.Microsecond, h.Mean())
+	for _, q := range []float64{0.5, 0.95, 0.99} {
+		exact := time.Duration(q*1000) * time.Millisecond
+		got := h.Quantile(q)
+		assert.GreaterOrEqual(t, got, exact, q)
+		assert.LessOrEqual(t, float64(got), float64(exact)*bucketGrowth, q)
+	}
+	// Перцентиль не превышает наибольшее значение, даже если граница корзины больше
+	assert.Equal(t, 1000*time.Millisecond, h.Quantile(1))
+
+	var small Histogram
+	small.Add(time.Nanosecond)
+	small.Add(100 * time.Hour)
+	assert.Equal(t, minLatency, small.Quantile(0.5))
+	assert.Equal(t, 100*time.Hour, small.Quantile(1))
+}
+
+func TestRecorder(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	r := NewRecorder(start)
+	assert.True(t, r.Status(start, "OK"))
+	for i := 0; i < 90; i++ {
+		r.Observe(i%3 == 0, 2*time.Millisecond, nil)
+	}
+	r.Observe(true, time.Second, errors.New("connection refused"))
+	first := r.CloseWindow(start.Add(time.Minute))
+	assert.Equal(t, int64(60), first.Reads)
+	assert.Equal(t, int64(30), first.Writes)
+	assert.Equal(t, int64(1), first.Errors)
+	assert.InDelta(t, 1.0/91, first.ErrorRate(), 1e-9)
+	assert.InDelta(t, 2, first.P99Ms, 0.2)
+	assert.Equal(t, "connection refused", first.LastError)
+
+	assert.False(t, r.Status(start.Add(90*time.Second), "OK"))
+	assert.True(t, r.Status(start.Add(100*time.Second), "RESTARTING"))
+	r.Observe(false, 20*time.Millisecond, nil)
+	r.Cycle(Cycle{Start: start.Add(100 * time.Second), DumpMs: 1500, RestoreMs: 2500, Rows: 30})
+	r.APIError(errors.New("GET /api/clusters/1: статус 502"))
+	r.Cycle(Cycle{Start: start.Add(110 * time.Second), Error: "POST /api/dumps: статус 503", Aborted: true})
+	second := r.CloseWindow(start.Add(2 * time.Minute))
+	assert.Equal(t, start.Add(time.Minute), second.Start)
+	assert.Equal(t, "RESTARTING", second.Status)
+	assert.Empty(t, second.LastError)
+	assert.Equal(t, int64(1), second.APIErrors)
+
+	summary := r.Summary()
+	assert.Equal(t, start.Add(2*time.Minute), summary.End)
+	assert.Len(t, summary.Windows, 2)
+	assert.Equal(t, int64(61), summary.Total.Reads)
+	assert.Equal(t, int64(1), summary.Total.Errors)
+	assert.Equal(t, float64(20), summary.Total.MaxMs)
+	assert.Equal(t, []StatusChange{{At: start.Add(100 * time.Second), From: "OK", To: "RESTARTING"}}, summary.StatusChanges)
+	assert.Len(t, summary.Cycles, 2)
+	assert.Equal(t, int64(1), summary.Total.APIErrors)
+	assert.Equal(t, "GET /api/clusters/1: статус 502", summary.Total.LastAPIError)
+
+	var text bytes.Buffer
+	assert.NoError(t, WriteText(&text, summary))
+	assert.Contains(t, text.String(), "10:00:00-10:01:00")
+	assert.Contains(t, text.String(), "OK -> RESTARTING")
+	assert.Contains(t, text.String(), "Последняя ошибка: connection refused")
+	assert.Contains(t, text.String(), "Последняя ошибка API: GET /api/clusters/1: статус 502")
+	assert.Contains(t, text.String(), "прерван: POST /api/dumps: статус 503")
+	assert.True(t, strings.Contains(text.String(), "ВСЕГО"), text.String())
+
+	var data bytes.Buffer
+	assert.NoError(t, WriteJSON(&data, summary))
+	var decoded Summary
+	assert.NoError(t, json.Unmarshal(data.Bytes(), &decoded))
+	assert.Equal(t, summary.Total.Reads, decoded.Total.Reads)
+	assert.Len(t, decoded.Windows, 2)
+}
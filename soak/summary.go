@@ -0,0 +1,56 @@
+package soak
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteText выводит итоги прогона таблицами: интервалы, смены статуса кластера и циклы дампа
+func WriteText(w io.Writer, s Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Прогон %s - %s (%s)\n\n", s.Start.Format(time.DateTime), s.End.Format(time.DateTime), s.End.Sub(s.Start).Round(time.Second))
+	fmt.Fprintln(tw, "ИНТЕРВАЛ\tЧТЕНИЙ\tЗАПИСЕЙ\tОШИБОК\tДОЛЯ ОШИБОК\tP50 МС\tP95 МС\tP99 МС\tMAX МС\tОШИБОК API\tСТАТУС")
+	for _, win := range s.Windows {
+		writeWindow(tw, win.Start.Format(time.TimeOnly)+"-"+win.End.Format(time.TimeOnly), win)
+	}
+	writeWindow(tw, "ВСЕГО", s.Total)
+	if s.Total.LastError != "" {
+		fmt.Fprintf(tw, "\nПоследняя ошибка: %s\n", s.Total.LastError)
+	}
+	if s.Total.LastAPIError != "" {
+		fmt.Fprintf(tw, "Последняя ошибка API: %s\n", s.Total.LastAPIError)
+	}
+
+	if len(s.StatusChanges) > 0 {
+		fmt.Fprintln(tw, "\nВРЕМЯ\tСТАТУС КЛАСТЕРА")
+		for _, c := range s.StatusChanges {
+			fmt.Fprintf(tw, "%s\t%s -> %s\n", c.At.Format(time.TimeOnly), c.From, c.To)
+		}
+	}
+	if len(s.Cycles) > 0 {
+		fmt.Fprintln(tw, "\nДАМП\tСОЗДАНИЕ МС\tВОССТАНОВЛЕНИЕ МС\tСТРОК\tОШИБКА")
+		for _, c := range s.Cycles {
+			message := c.Error
+			if c.Aborted {
+				message = "прерван: " + message
+			}
+			fmt.Fprintf(tw, "%s\t%.0f\t%.0f\t%d\t%s\n", c.Start.Format(time.TimeOnly), c.DumpMs, c.RestoreMs, c.Rows, message)
+		}
+	}
+	return tw.Flush()
+}
+
+func writeWindow(w io.Writer, name string, win Window) {
+	fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.4f\t%.2f\t%.2f\t%.2f\t%.2f\t%d\t%s\n",
+		name, win.Reads, win.Writes, win.Errors, win.ErrorRate(), win.P50Ms, win.P95Ms, win.P99Ms, win.MaxMs, win.APIErrors, win.Status)
+}
+
+// WriteJSON выводит итоги прогона в JSON
+func WriteJSON(w io.Writer, s Summary) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
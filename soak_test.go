@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"dbaas_testing_task/dbaas"
+	"dbaas_testing_task/metrics"
+	"dbaas_testing_task/runstate"
+	"dbaas_testing_task/soak"
+)
+
+func TestSoak(t *testing.T) {
+	RequireAPIEnv(t)
+	cfg := LoadSoakConfig(t)
+	if cfg.Duration == 0 {
+		t.Skip("Длительный прогон выключен: задайте SOAK_DURATION")
+	}
+	if deadline, ok := t.Deadline(); ok && time.Until(deadline) < cfg.Duration {
+		Fatalf(t, "Таймаут go test меньше SOAK_DURATION=%s: запустите с -timeout 0", cfg.Duration)
+	}
+	Authorize(t)
+	authorized := time.Now()
+	client := soakClient()
+
+	ctx := context.Background()
+	f := newScalingFixture(t, ctx, DefaultClusterRequest(t, "soak-"+RandomSuffix()))
+	user, ok := FindClusterUser(t, f.clusterId, f.userName)
+	if !ok {
+		Fatalf(t, "Пользователь %s отсутствует в кластере %s", f.userName, f.clusterId)
+	}
+	recorder := soak.NewRecorder(time.Now())
+	recorder.Status(time.Now(), GetCluster(t, f.clusterId).Status)
+	// Итоги выводятся и при досрочном завершении теста
+	defer finishSoak(t, cfg, recorder)
+
+	workload := LoadWorkloadConfig(t, LoadPoolConfig(t))
+	workload.Duration = cfg.Duration
+	workload.Observe = recorder.Observe
+	workloadCtx, cancel := context.WithCancel(ctx)
+	done := make(chan WorkloadStats, 1)
+	go func() { done <- RunWorkload(workloadCtx, f.session, workload) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	deadline := time.Now().Add(cfg.Duration)
+	nextDump := time.Now().Add(cfg.DumpInterval)
+	ticker := time.NewTicker(cfg.Window)
+	defer ticker.Stop()
+	for cycle := 1; ; {
+		select {
+		case <-ticker.C:
+		case <-time.After(time.Until(deadline)):
+		}
+		// Сбои API учитываются в статистике интервала, решение о провале принимает finishSoak
+		if time.Since(authorized) > soakReauthorizeInterval {
+			if err := client.Login(ctx, login, password); err != nil {
+				recorder.APIError(err)
+				Logf(t, "Soak reauthorization failed: %v", err)
+			} else {
+				// Токен нужен и помощникам на makeRequest, которые удаляют кластер после прогона
+				refreshToken = client.Token
+				authorized = time.Now()
+			}
+		}
+		now := time.Now()
+		if cluster, err := client.GetCluster(ctx, f.clusterId); err != nil {
+			recorder.APIError(err)
+			Logf(t, "Soak cluster status request failed: %v", err)
+		} else if recorder.Status(now, cluster.Status) {
+			Logf(t, "Cluster status changed to %s", cluster.Status)
+		}
+		if !now.Before(deadline) {
+			return
+		}
+		w := recorder.CloseWindow(now)
+		Logf(t, "Soak window %s: %d reads, %d writes, %d errors, p95 %.2f ms, p99 %.2f ms, %d API errors, status %s",
+			now.Format(time.TimeOnly), w.Reads, w.Writes, w.Errors, w.P95Ms, w.P99Ms, w.APIErrors, w.Status)
+
+		if cfg.DumpInterval > 0 && !now.Before(nextDump) {
+			c := soakDumpCycle(t, ctx, client, recorder, f, user.Id, cycle)
+			recorder.Cycle(c)
+			Logf(t, "Soak dump cycle %d: dump %.0f ms, restore %.0f ms, %d rows %s", cycle, c.DumpMs, c.RestoreMs, c.Rows, c.Error)
+			cycle++
+			nextDump = time.Now().Add(cfg.DumpInterval)
+		}
+	}
+}
+
+// finishSoak закрывает последний интервал, выводит итоги прогона и проверяет долю ошибок,
+// итоговый статус кластера и циклы дампа
+func finishSoak(t *testing.T, cfg SoakConfig, recorder *soak.Recorder) {
+	recorder.CloseWindow(time.Now())
+	summary := recorder.Summary()
+
+	var text strings.Builder
+	if err := soak.WriteText(&text, summary); err != nil {
+		Errorf(t, "Не удалось сформировать итоги прогона: %v", err)
+	}
+	Logf(t, "Итоги длительного прогона:\n%s", text.String())
+	if cfg.SummaryFile != "" {
+		if err := writeSoakSummary(cfg.SummaryFile, summary); err != nil {
+			Errorf(t, "%v", err)
+		}
+	}
+
+	if rate := summary.Total.ErrorRate(); rate > cfg.MaxErrorRate {
+		Errorf(t, "Доля ошибок нагрузки %.4f больше SOAK_MAX_ERROR_RATE=%.4f (последняя ошибка: %s)", rate, cfg.MaxErrorRate, summary.Total.LastError)
+	}
+	if summary.Total.APIErrors > cfg.MaxAPIErrors {
+		Errorf(t, "Неудачных запросов к API %d больше SOAK_MAX_API_ERRORS=%d (последняя ошибка: %s)", summary.Total.APIErrors, cfg.MaxAPIErrors, summary.Total.LastAPIError)
+	}
+	if summary.Total.Status != "OK" {
+		Errorf(t, "Кластер завершил прогон в статусе %s", summary.Total.Status)
+	}
+	completed := 0
+	for _, c := range summary.Cycles {
+		switch {
+		case c.Aborted:
+			// Причина уже учтена в числе ошибок API
+			Logf(t, "Soak dump cycle %s aborted: %s", c.Start.Format(time.TimeOnly), c.Error)
+		case c.Error != "":
+			Errorf(t, "Цикл дампа %s: %s", c.Start.Format(time.TimeOnly), c.Error)
+		default:
+			completed++
+		}
+	}
+	if len(summary.Cycles) > 0 && completed == 0 {
+		Errorf(t, "Ни один из %d циклов дампа не завершён", len(summary.Cycles))
+	}
+}
+
+// writeSoakSummary сохраняет итоги прогона в JSON
+func writeSoakSummary(path string, summary soak.Summary) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("не удалось создать файл итогов прогона: %w", err)
+	}
+	err = soak.WriteJSON(file, summary)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("не удалось записать итоги прогона %s: %w", path, err)
+	}
+	return nil
+}
+
+// soakDumpCycle создаёт дамп базы данных под нагрузкой, восстанавливает его в отдельную базу данных,
+// проверяет в ней строки нагрузки и удаляет базу данных и дамп. Ошибки API прерывают цикл
+// и учитываются в recorder, но не завершают тест
+func soakDumpCycle(t *testing.T, ctx context.Context, client *dbaas.Client, recorder *soak.Recorder, f scalingFixture, userId string, n int) soak.Cycle {
+	cycle := soak.Cycle{Start: time.Now()}
+	abort := func(err error) soak.Cycle {
+		recorder.APIError(err)
+		cycle.Error = err.Error()
+		cycle.Aborted = true
+		return cycle
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, soakCycleTimeout)
+	defer cancel()
+
+	dumpName := fmt.Sprintf("soak-%d", n)
+	dump, err := client.CreateDump(ctx, f.clusterId, f.dbId, CreateDumpRequest{Name: dumpName})
+	if err != nil {
+		return abort(err)
+	}
+	JournalResource(t, runstate.KindDump, dump.Id, f.clusterId, dumpName)
+	defer func() {
+		if err := client.DeleteDump(ctx, dump.Id); err != nil && !dbaas.IsNotFound(err) {
+			recorder.APIError(err)
+			Logf(t, "Soak dump %s is left for cleanup: %v", dump.Id, err)
+			return
+		}
+		ForgetResource(t, runstate.KindDump, dump.Id)
+	}()
+	if _, err := client.WaitDump(waitCtx, dump.Id, dbaas.DefaultPollInterval); err != nil {
+		return abort(err)
+	}
+	cycle.DumpMs = float64(time.Since(cycle.Start).Microseconds()) / 1000
+	RecordOperation(t, metrics.OpDumpCreate, cycle.Start)
+
+	tableSpace, err := client.FindTableSpace(ctx, f.clusterId, "")
+	if err != nil {
+		return abort(err)
+	}
+	scratchName := fmt.Sprintf("soakScratch%d", n)
+	createStarted := time.Now()
+	scratch, err := client.CreateDatabase(ctx, f.clusterId, CreateDBRequest{Name: scratchName, TableSpaceID: tableSpace.Id})
+	if err != nil {
+		return abort(err)
+	}
+	JournalResource(t, runstate.KindDatabase, scratch.Id, f.clusterId, scratchName)
+	defer func() {
+		if err := client.DeleteDatabase(ctx, f.clusterId, scratch.Id); err != nil {
+			recorder.APIError(err)
+			Logf(t, "Soak database %s is left for cleanup: %v", scratch.Id, err)
+			return
+		}
+		ForgetResource(t, runstate.KindDatabase, scratch.Id)
+	}()
+	if _, err := client.WaitDatabase(waitCtx, f.clusterId, scratch.Id, dbaas.DefaultPollInterval); err != nil {
+		return abort(err)
+	}
+	RecordOperation(t, metrics.OpDatabaseCreate, createStarted)
+
+	restoreStarted := time.Now()
+	if _, err := client.RestoreDump(ctx, f.clusterId, scratch.Id, RestoreDumpRequest{DumpID: dump.Id, Mode: "full"}); err != nil {
+		return abort(err)
+	}
+	if _, err := client.WaitDump(waitCtx, dump.Id, dbaas.DefaultPollInterval); err != nil {
+		return abort(err)
+	}
+	db, err := client.WaitDatabase(waitCtx, f.clusterId, scratch.Id, dbaas.DefaultPollInterval)
+	if err != nil {
+		return abort(err)
+	}
+	cycle.RestoreMs = float64(time.Since(restoreStarted).Microseconds()) / 1000
+	RecordOperation(t, metrics.OpDumpRestore, restoreStarted)
+
+	if err := soakGrantDatabases(waitCtx, client, f.clusterId, userId, f.dbName, scratchName); err != nil {
+		return abort(err)
+	}
+	defer func() {
+		// Доступ отзывается и после истечения времени цикла
+		revokeCtx, cancel := context.WithTimeout(ctx, soakWaitTimeout)
+		defer cancel()
+		if err := soakGrantDatabases(revokeCtx, client, f.clusterId, userId, f.dbName); err != nil {
+			recorder.APIError(err)
+			Logf(t, "Soak user %s keeps access to %s: %v", f.userName, scratchName, err)
+		}
+	}()
+	session, err := OpenDBSession(ctx, connectionString(db, f.userName, f.userPassword), singleConnPoolConfig())
+	if err == nil {
+		err = session.QueryRow(ctx, `SELECT count(*) FROM test_schema.workload_events`, nil, &cycle.Rows)
+		session.Close()
+	}
+	switch {
+	case err != nil:
+		cycle.Error = fmt.Sprintf("не удалось проверить восстановленную базу данных: %v", err)
+	case cycle.Rows == 0:
+		cycle.Error = "в восстановленной базе данных нет строк нагрузки"
+	}
+	return cycle
+}
+
+// soakGrantDatabases задаёт список баз данных пользователя и дожидается статуса OK
+func soakGrantDatabases(ctx context.Context, client *dbaas.Client, clusterId, userId string, databases ...string) error {
+	if _, err := client.UpdateClusterUser(ctx, clusterId, userId, UpdateClusterUserRequest{Databases: &databases}); err != nil {
+		return err
+	}
+	_, err := client.WaitClusterUser(ctx, clusterId, userId, dbaas.DefaultPollInterval)
+	return err
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"dbaas_testing_task/metrics"
+	"dbaas_testing_task/runstate"
+)
+
+// ListTableSpaces возвращает все tablespace кластера
+func ListTableSpaces(t *testing.T, clusterId string) []TableSpaceResponse {
+	resp, _ := makeRequest(t, "GET", apiURL("/api/clusters/%s/tablespaces", clusterId), nil, authHeaders())
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 200 при получении списка tablespace, получен: %d", resp.StatusCode)
+	}
+	var tableSpaces []TableSpaceResponse
+	parseResponseBody(t, resp, &tableSpaces)
+	return tableSpaces
+}
+
+// GetTableSpace возвращает tablespace кластера по ID
+func GetTableSpace(t *testing.T, clusterId, tableSpaceId string) TableSpaceResponse {
+	resp, _ := makeRequest(t, "GET", apiURL("/api/clusters/%s/tablespaces/%s", clusterId, tableSpaceId), nil, authHeaders())
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 200 при получении tablespace %s, получен: %d", tableSpaceId, resp.StatusCode)
+	}
+	var tableSpace TableSpaceResponse
+	parseResponseBody(t, resp, &tableSpace)
+	return tableSpace
+}
+
+// FindTableSpace ищет tablespace кластера по имени. Второе значение равно false, если tablespace не найден
+func FindTableSpace(t *testing.T, clusterId, name string) (TableSpaceResponse, bool) {
+	for _, tableSpace := range ListTableSpaces(t, clusterId) {
+		if tableSpace.Name == name {
+			return tableSpace, true
+		}
+	}
+	return TableSpaceResponse{}, false
+}
+
+// GetDefaultTableSpace возвращает tablespace кластера, помеченный как default
+func GetDefaultTableSpace(t *testing.T, clusterId string) TableSpaceResponse {
+	for _, tableSpace := range ListTableSpaces(t, clusterId) {
+		if tableSpace.Default {
+			return tableSpace
+		}
+	}
+	Fatalf(t, "У кластера %s нет tablespace по умолчанию", clusterId)
+	return TableSpaceResponse{}
+}
+
+// GetDefaultTableSpaceID возвращает ID tablespace кластера по умолчанию
+func GetDefaultTableSpaceID(t *testing.T, clusterId string) string {
+	return GetDefaultTableSpace(t, clusterId).Id
+}
+
+// CreateTableSpace создаёт tablespace в кластере, дожидается статуса OK и возвращает его ID
+func CreateTableSpace(t *testing.T, clusterId string, request CreateTableSpaceRequest) string {
+	started := time.Now()
+	resp, _ := makeRequest(t, "POST", apiURL("/api/clusters/%s/tablespaces", clusterId), request, authHeaders())
+	if resp.StatusCode != http.StatusCreated {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 201 при создании tablespace, получен: %d", resp.StatusCode)
+	}
+
+	var tableSpace TableSpaceResponse
+	parseResponseBody(t, resp, &tableSpace)
+	Logf(t, "Tablespace %s created with ID: %s", request.Name, tableSpace.Id)
+	JournalResource(t, runstate.KindTableSpace, tableSpace.Id, clusterId, request.Name)
+
+	defer RecordOperation(t, metrics.OpTableSpaceCreate, started)
+	WaitForStatus(t, "Tablespace", apiURL("/api/clusters/%s/tablespaces/%s", clusterId, tableSpace.Id))
+	return tableSpace.Id
+}
+
+// DeleteTableSpace удаляет tablespace кластера
+func DeleteTableSpace(t *testing.T, clusterId, tableSpaceId string) {
+	resp, _ := makeRequest(t, "DELETE", apiURL("/api/clusters/%s/tablespaces/%s", clusterId, tableSpaceId), nil, authHeaders())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		Fatalf(t, "Ожидался статус 204 при удалении tablespace %s, получен: %d", tableSpaceId, resp.StatusCode)
+	}
+	ForgetResource(t, runstate.KindTableSpace, tableSpaceId)
+	Logf(t, "Deleted tablespace with ID: %s", tableSpaceId)
+}
+
+// PgTableSpaceExists проверяет наличие tablespace name в pg_tablespace
+func PgTableSpaceExists(ctx context.Context, s *DBSession, name string) (bool, error) {
+	var count int
+	err := s.QueryRow(ctx, `SELECT count(*) FROM pg_tablespace WHERE spcname = $1`, []interface{}{name}, &count)
+	return count > 0, err
+}
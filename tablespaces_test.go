@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTableSpacePlacement(t *testing.T) {
+	a := Assert(t)
+	RequireAPIEnv(t)
+	Authorize(t)
+
+	ctx := context.Background()
+	suffix := RandomSuffix()
+	clusterId := ProvisionCluster(t, DefaultClusterRequest(t, "tablespaces-"+suffix))
+
+	defaultTableSpace := GetDefaultTableSpace(t, clusterId)
+	a.NotEmpty(defaultTableSpace.Name, "У tablespace по умолчанию пустое имя")
+
+	adminDB := "adminDB"
+	CreateDatabase(t, clusterId, CreateDBRequest{Name: adminDB, TableSpaceID: defaultTableSpace.Id})
+	userName, userPassword := "ts_"+suffix, RandomPassword()
+	CreateClusterUser(t, clusterId, CreateClusterUserRequest{
+		Databases: []string{adminDB},
+		Roles:     []string{"pg_read_all_data"},
+		Name:      userName,
+		Password:  userPassword,
+	})
+	catalog := NewDBSession(t, ctx, ConnectionString(t, clusterId, adminDB, userName, userPassword), singleConnPoolConfig())
+
+	// База в tablespace по умолчанию
+	info, _, err := QueryPgDatabase(ctx, catalog, adminDB)
+	a.NoError(err, "не удалось прочитать pg_database")
+	a.Equal(defaultTableSpace.Name, info.TableSpace, "база создана не в tablespace по умолчанию")
+
+	// Создаём отдельный tablespace и выбираем его по имени
+	tableSpaceName := "ts_" + suffix
+	tableSpaceId := CreateTableSpace(t, clusterId, CreateTableSpaceRequest{Name: tableSpaceName})
+	tableSpace, found := FindTableSpace(t, clusterId, tableSpaceName)
+	a.True(found, "Созданный tablespace отсутствует в списке")
+	a.Equal(tableSpaceId, tableSpace.Id)
+	a.False(tableSpace.Default, "Созданный tablespace не должен быть tablespace по умолчанию")
+	a.Equal(tableSpaceName, GetTableSpace(t, clusterId, tableSpaceId).Name)
+
+	exists, err := PgTableSpaceExists(ctx, catalog, tableSpaceName)
+	a.NoError(err, "не удалось прочитать pg_tablespace")
+	a.True(exists, "tablespace отсутствует в pg_tablespace")
+
+	// База в созданном tablespace
+	dbName := "placedDB"
+	dbId := CreateDatabase(t, clusterId, CreateDBRequest{Name: dbName, TableSpaceID: tableSpace.Id})
+	info, _, err = QueryPgDatabase(ctx, catalog, dbName)
+	a.NoError(err, "не удалось прочитать pg_database")
+	a.Equal(tableSpaceName, info.TableSpace, "dattablespace не указывает на созданный tablespace")
+
+	// Удаление: сначала база, затем tablespace
+	DeleteDatabase(t, clusterId, dbId)
+	DeleteTableSpace(t, clusterId, tableSpaceId)
+	_, found = FindTableSpace(t, clusterId, tableSpaceName)
+	a.False(found, "Удалённый tablespace остался в списке")
+	exists, err = PgTableSpaceExists(ctx, catalog, tableSpaceName)
+	a.NoError(err, "не удалось прочитать pg_tablespace")
+	a.False(exists, "удалённый tablespace остался в pg_tablespace")
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"dbaas_testing_task/metrics"
+)
+
+// Длительность операций от отправки запроса до статуса OK записывается в METRICS_FILE в текстовом
+// формате Prometheus/OpenMetrics. Если задан METRICS_HISTORY, измерения накапливаются в нём между
+// запусками и перцентили считаются по всей истории. Пороги SLO задаются в DBAAS_SLO
+var (
+	metricsFile    = os.Getenv("METRICS_FILE")
+	metricsHistory = os.Getenv("METRICS_HISTORY")
+	operations     metrics.Recorder
+	slos           []metrics.SLO
+)
+
+// loadSLOs разбирает пороги SLO из DBAAS_SLO
+func loadSLOs() error {
+	var err error
+	slos, err = metrics.ParseSLOs(os.Getenv("DBAAS_SLO"))
+	if err != nil {
+		return fmt.Errorf("DBAAS_SLO: %w", err)
+	}
+	return nil
+}
+
+// RecordOperation записывает длительность операции, начатой в started, и проваливает тест,
+// если она превышает порог SLO для каждого измерения этой операции. Хелперы вызывают его через
+// defer перед ожиданием статуса, чтобы измерение попало в метрики и при таймауте ожидания
+func RecordOperation(t *testing.T, operation string, started time.Time) {
+	elapsed := time.Since(started)
+	operations.Add(metrics.Sample{Operation: operation, Seconds: elapsed.Seconds(), Test: t.Name(), At: started.UTC()})
+	Logf(t, "Operation %s took %s", operation, elapsed.Round(time.Millisecond))
+	for _, slo := range slos {
+		if slo.Operation == operation && slo.Quantile == 1 && elapsed > slo.Threshold {
+			Errorf(t, "Нарушен SLO %s: операция заняла %s", slo, elapsed.Round(time.Millisecond))
+		}
+	}
+}
+
+// finishMetrics дописывает измерения запуска в историю, записывает METRICS_FILE и проверяет
+// перцентильные пороги SLO. Пороги для каждого измерения уже проверены в тестах и повторно
+// по истории не проверяются, чтобы старые выбросы не проваливали новые запуски
+func finishMetrics() error {
+	samples := operations.Samples()
+	if metricsHistory != "" {
+		history, err := metrics.LoadHistory(metricsHistory)
+		if err != nil {
+			return err
+		}
+		if err := metrics.AppendHistory(metricsHistory, samples); err != nil {
+			return err
+		}
+		samples = append(history, samples...)
+	}
+	summaries := metrics.Summarize(samples)
+
+	if metricsFile != "" {
+		f, err := os.Create(metricsFile)
+		if err != nil {
+			return fmt.Errorf("не удалось создать файл метрик: %w", err)
+		}
+		err = metrics.WritePrometheus(f, summaries, slos)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("не удалось записать файл метрик: %w", err)
+		}
+	}
+
+	var errs []error
+	for _, v := range metrics.Evaluate(slos, summaries) {
+		if v.SLO.Quantile < 1 {
+			errs = append(errs, v)
+		}
+	}
+	return errors.Join(errs...)
+}
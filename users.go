@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"dbaas_testing_task/metrics"
+	"dbaas_testing_task/runstate"
+)
+
+// CreateClusterUser создаёт пользователя кластера, дожидается статуса OK и возвращает созданного пользователя
+func CreateClusterUser(t *testing.T, clusterId string, request CreateClusterUserRequest) ClusterUser {
+	started := time.Now()
+	resp, _ := makeRequest(t, "POST", apiURL("/api/clusters/%s/users", clusterId), request, authHeaders())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		Fatalf(t, "Ожидался статус 201 при создании пользователя %s, получен: %d", request.Name, resp.StatusCode)
+	}
+
+	user, ok := FindClusterUser(t, clusterId, request.Name)
+	if !ok {
+		Fatalf(t, "Созданный пользователь %s отсутствует в списке пользователей кластера", request.Name)
+	}
+	JournalResource(t, runstate.KindUser, user.Id, clusterId, request.Name)
+	defer RecordOperation(t, metrics.OpUserCreate, started)
+	WaitForStatus(t, "User", apiURL("/api/clusters/%s/users/%s", clusterId, user.Id))
+	Logf(t, "Database user created with login: %s", request.Name)
+	return user
+}
+
+// TryCreateClusterUser отправляет запрос на создание пользователя и возвращает статус ответа и описание ошибки.
+// Если пользователь всё же создан, он удаляется по завершении теста
+func TryCreateClusterUser(t *testing.T, clusterId string, request CreateClusterUserRequest) (int, APIError) {
+	resp, _ := makeRequest(t, "POST", apiURL("/api/clusters/%s/users", clusterId), request, authHeaders())
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusCreated {
+		if user, ok := FindClusterUser(t, clusterId, request.Name); ok {
+			JournalResource(t, runstate.KindUser, user.Id, clusterId, request.Name)
+			t.Cleanup(func() { DeleteClusterUser(t, clusterId, user.Id) })
+		}
+		return resp.StatusCode, APIError{}
+	}
+
+	var apiError APIError
+	if err := json.NewDecoder(resp.Body).Decode(&apiError); err != nil {
+		Logf(t, "Error response is not a JSON object: %v", err)
+	}
+	return resp.StatusCode, apiError
+}
+
+// ListClusterUsers возвращает всех пользователей кластера
+func ListClusterUsers(t *testing.T, clusterId string) []ClusterUser {
+	resp, _ := makeRequest(t, "GET", apiURL("/api/clusters/%s/users", clusterId), nil, authHeaders())
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 200 при получении списка пользователей, получен: %d", resp.StatusCode)
+	}
+	var users []ClusterUser
+	parseResponseBody(t, resp, &users)
+	return users
+}
+
+// GetClusterUser возвращает пользователя кластера по ID
+func GetClusterUser(t *testing.T, clusterId, userId string) ClusterUser {
+	resp, _ := makeRequest(t, "GET", apiURL("/api/clusters/%s/users/%s", clusterId, userId), nil, authHeaders())
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		Fatalf(t, "Ожидался статус 200 при получении пользователя %s, получен: %d", userId, resp.StatusCode)
+	}
+	var user ClusterUser
+	parseResponseBody(t, resp, &user)
+	return user
+}
+
+// FindClusterUser ищет пользователя кластера по имени. Второе значение равно false, если пользователь не найден
+func FindClusterUser(t *testing.T, clusterId, name string) (ClusterUser, bool) {
+	for _, user := range ListClusterUsers(t, clusterId) {
+		if user.Name == name {
+			return user, true
+		}
+	}
+	return ClusterUser{}, false
+}
+
+// UpdateClusterUser изменяет роли, список баз данных и/или пароль пользователя и дожидается статуса OK
+func UpdateClusterUser(t *testing.T, clusterId, userId string, request UpdateClusterUserRequest) {
+	resp, _ := makeRequest(t, "PATCH", apiURL("/api/clusters/%s/users/%s", clusterId, userId), request, authHeaders())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		Fatalf(t, "Ожидался статус 200 или 202 при изменении пользователя %s, получен: %d", userId, resp.StatusCode)
+	}
+	WaitForStatus(t, "User", apiURL("/api/clusters/%s/users/%s", clusterId, userId))
+	Logf(t, "Database user %s updated", userId)
+}
+
+// ChangeClusterUserPassword меняет пароль пользователя кластера
+func ChangeClusterUserPassword(t *testing.T, clusterId, userId, newPassword string) {
+	UpdateClusterUser(t, clusterId, userId, UpdateClusterUserRequest{Password: newPassword})
+}
+
+// DeleteClusterUser удаляет пользователя кластера
+func DeleteClusterUser(t *testing.T, clusterId, userId string) {
+	resp, _ := makeRequest(t, "DELETE", apiURL("/api/clusters/%s/users/%s", clusterId, userId), nil, authHeaders())
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		Fatalf(t, "Ожидался статус 204 при удалении пользователя %s, получен: %d", userId, resp.StatusCode)
+	}
+	ForgetResource(t, runstate.KindUser, userId)
+	Logf(t, "Deleted database user with ID: %s", userId)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClusterUserCRUD(t *testing.T) {
+	a := Assert(t)
+	RequireAPIEnv(t)
+	Authorize(t)
+
+	ctx := context.Background()
+	suffix := RandomSuffix()
+	clusterId := ProvisionCluster(t, DefaultClusterRequest(t, "users-"+suffix))
+
+	tableSpaceId := GetDefaultTableSpaceID(t, clusterId)
+	mainDB, otherDB := "usersDB", "otherDB"
+	CreateDatabase(t, clusterId, CreateDBRequest{Name: mainDB, TableSpaceID: tableSpaceId})
+	CreateDatabase(t, clusterId, CreateDBRequest{Name: otherDB, TableSpaceID: tableSpaceId})
+
+	// canConnect проверяет, удаётся ли подключиться к базе данных с указанными учётными данными
+	canConnect := func(dbName, user, pass string) error {
+		session, err := OpenDBSession(ctx, ConnectionString(t, clusterId, dbName, user, pass), singleConnPoolConfig())
+		if err == nil {
+			session.Close()
+		}
+		return err
+	}
+
+	// Создание
+	userName, oldPassword := "crud_"+suffix, RandomPassword()
+	created := CreateClusterUser(t, clusterId, CreateClusterUserRequest{
+		Databases: []string{mainDB},
+		Roles:     []string{"pg_read_all_data"},
+		Name:      userName,
+		Password:  oldPassword,
+	})
+	a.NotEmpty(created.Id, "У созданного пользователя пустой ID")
+
+	// Чтение списка и одного пользователя
+	_, found := FindClusterUser(t, clusterId, userName)
+	a.True(found, "Пользователь отсутствует в списке пользователей кластера")
+
+	user := GetClusterUser(t, clusterId, created.Id)
+	a.Equal(userName, user.Name)
+	a.ElementsMatch([]string{mainDB}, user.Databases)
+	a.ElementsMatch([]string{"pg_read_all_data"}, user.Roles)
+	a.NoError(canConnect(mainDB, userName, oldPassword), "не удалось подключиться с исходным паролем")
+
+	// Смена пароля: старый пароль перестаёт работать, новый работает
+	newPassword := RandomPassword()
+	ChangeClusterUserPassword(t, clusterId, created.Id, newPassword)
+	a.Error(canConnect(mainDB, userName, oldPassword), "старый пароль продолжает работать после смены")
+	a.NoError(canConnect(mainDB, userName, newPassword), "не удалось подключиться с новым паролем")
+
+	// Изменение ролей и списка баз данных
+	UpdateClusterUser(t, clusterId, created.Id, UpdateClusterUserRequest{
+		Databases: &[]string{mainDB, otherDB},
+		Roles:     &[]string{"pg_read_all_data", "pg_write_all_data"},
+	})
+	user = GetClusterUser(t, clusterId, created.Id)
+	a.ElementsMatch([]string{mainDB, otherDB}, user.Databases)
+	a.ElementsMatch([]string{"pg_read_all_data", "pg_write_all_data"}, user.Roles)
+	a.NoError(canConnect(otherDB, userName, newPassword), "не удалось подключиться к добавленной базе данных")
+
+	session := NewDBSession(t, ctx, ConnectionString(t, clusterId, mainDB, userName, newPassword), singleConnPoolConfig())
+	var canWrite bool
+	err := session.QueryRow(ctx, `SELECT pg_has_role(current_user, 'pg_write_all_data', 'MEMBER')`, nil, &canWrite)
+	a.NoError(err, "не удалось проверить членство в роли")
+	a.True(canWrite, "роль pg_write_all_data не выдана пользователю")
+	session.Close()
+
+	// Пустой список ролей отзывает все роли, не заданный список баз данных не изменяется
+	UpdateClusterUser(t, clusterId, created.Id, UpdateClusterUserRequest{Roles: &[]string{}})
+	user = GetClusterUser(t, clusterId, created.Id)
+	a.Empty(user.Roles, "роли не отозваны")
+	a.ElementsMatch([]string{mainDB, otherDB}, user.Databases, "список баз данных изменился без запроса")
+
+	// Удаление: пользователь пропадает из списка, новые подключения отклоняются
+	DeleteClusterUser(t, clusterId, created.Id)
+	_, found = FindClusterUser(t, clusterId, userName)
+	a.False(found, "Удалённый пользователь остался в списке пользователей кластера")
+	a.Error(canConnect(mainDB, userName, newPassword), "удалённый пользователь может подключиться к базе данных")
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// withoutField возвращает тело запроса без поля field
+func withoutField(t *testing.T, request CreateClusterRequest, field string) map[string]interface{} {
+	raw, err := json.Marshal(request)
+	if err != nil {
+		Fatalf(t, "Ошибка при сериализации запроса: %v", err)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		Fatalf(t, "Ошибка при разборе запроса: %v", err)
+	}
+	delete(body, field)
+	return body
+}
+
+func TestCreateClusterValidation(t *testing.T) {
+	RequireAPIEnv(t)
+	Authorize(t)
+	// Тест намеренно отправляет запросы, нарушающие спецификацию
+	SkipRequestContract(t)
+
+	valid := DefaultClusterRequest(t, "validation-"+RandomSuffix())
+	// Существующий кластер для проверки уникальности имени
+	duplicateName := "duplicate-" + RandomSuffix()
+	existing := valid
+	existing.Name = duplicateName
+	ProvisionCluster(t, existing)
+
+	badRequest := []int{http.StatusBadRequest, http.StatusUnprocessableEntity}
+	notFound := []int{http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity}
+
+	cases := []struct {
+		name string
+		// body строит тело запроса из корректного запроса; t - подтест, в котором оно отправляется
+		body     func(t *testing.T, r CreateClusterRequest) interface{}
+		statuses []int
+		// field - имя поля, которое должно упоминаться в ошибке
+		field string
+	}{
+		{"unknown flavor", func(t *testing.T, r CreateClusterRequest) interface{} {
+			r.FlavorID = "00000000-0000-0000-0000-000000000000"
+			return r
+		}, notFound, "flavor_id"},
+		{"malformed flavor", func(t *testing.T, r CreateClusterRequest) interface{} {
+			r.FlavorID = "not-a-flavor"
+			return r
+		}, badRequest, "flavor_id"},
+		{"unknown type", func(t *testing.T, r CreateClusterRequest) interface{} {
+			r.TypeID = "00000000-0000-0000-0000-000000000000"
+			return r
+		}, notFound, "type_id"},
+		{"malformed type", func(t *testing.T, r CreateClusterRequest) interface{} {
+			r.TypeID = "not-a-type"
+			return r
+		}, badRequest, "type_id"},
+		{"disk below minimum", func(t *testing.T, r CreateClusterRequest) interface{} {
+			r.DiskSize = 1
+			return r
+		}, badRequest, "disk_size"},
+		{"disk above maximum", func(t *testing.T, r CreateClusterRequest) interface{} {
+			r.DiskSize = 1 << 50
+			return r
+		}, badRequest, "disk_size"},
+		{"negative disk", func(t *testing.T, r CreateClusterRequest) interface{} {
+			r.DiskSize = -1
+			return r
+		}, badRequest, "disk_size"},
+		{"unknown az", func(t *testing.T, r CreateClusterRequest) interface{} {
+			r.Az = "NO-SUCH-AZ"
+			return r
+		}, badRequest, "az"},
+		{"negative replicas", func(t *testing.T, r CreateClusterRequest) interface{} {
+			r.ReplicasCount = -1
+			return r
+		}, badRequest, "replicas_count"},
+		{"too many replicas", func(t *testing.T, r CreateClusterRequest) interface{} {
+			r.ReplicasCount = 100
+			return r
+		}, badRequest, "replicas_count"},
+		{"unknown creation mode", func(t *testing.T, r CreateClusterRequest) interface{} {
+			r.CreationMode = "no-such-mode"
+			return r
+		}, badRequest, "creation_mode"},
+		{"missing name", func(t *testing.T, r CreateClusterRequest) interface{} { return withoutField(t, r, "name") }, badRequest, "name"},
+		{"missing type", func(t *testing.T, r CreateClusterRequest) interface{} { return withoutField(t, r, "type_id") }, badRequest, "type_id"},
+		{"missing flavor", func(t *testing.T, r CreateClusterRequest) interface{} { return withoutField(t, r, "flavor_id") }, badRequest, "flavor_id"},
+		{"missing disk size", func(t *testing.T, r CreateClusterRequest) interface{} { return withoutField(t, r, "disk_size") }, badRequest, "disk_size"},
+		{"missing az", func(t *testing.T, r CreateClusterRequest) interface{} { return withoutField(t, r, "az") }, badRequest, "az"},
+		{"empty body", func(t *testing.T, r CreateClusterRequest) interface{} { return map[string]interface{}{} }, badRequest, ""},
+		{"duplicate name", func(t *testing.T, r CreateClusterRequest) interface{} {
+			r.Name = duplicateName
+			return r
+		}, []int{http.StatusConflict}, "name"},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			a := Assert(t)
+			status, apiError := TryCreateCluster(t, tc.body(t, valid))
+			a.Contains(tc.statuses, status, "неожиданный статус ответа")
+			a.NotEmpty(apiError.Message, "в ответе нет описания ошибки")
+			if tc.field != "" {
+				a.True(apiError.Field == tc.field || strings.Contains(apiError.Message, tc.field),
+					"ошибка не указывает на поле %s: %+v", tc.field, apiError)
+			}
+		})
+	}
+}
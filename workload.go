@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+// TestUser представляет строку таблицы test_schema.users.
+type TestUser struct {
+	Name  string
+	Email string
+	Age   int
+}
+
+// CreateTestSchema создаёт схему test_schema и таблицу users
+func CreateTestSchema(ctx context.Context, s *DBSession) error {
+	if _, err := s.Exec(ctx, `CREATE SCHEMA IF NOT EXISTS test_schema;`); err != nil {
+		return fmt.Errorf("не удалось создать схему данных: %w", err)
+	}
+	_, err := s.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS test_schema.users (
+			id SERIAL PRIMARY KEY,
+			name VARCHAR(100) NOT NULL,
+			email VARCHAR(100) NOT NULL,
+			age INT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу: %w", err)
+	}
+	return nil
+}
+
+// SeedUsers добавляет в test_schema.users count пользователей со случайным возрастом
+func SeedUsers(ctx context.Context, s *DBSession, r *rand.Rand, count int) error {
+	for i := 1; i <= count; i++ {
+		name := fmt.Sprintf("Пользователь %d", i)
+		email := fmt.Sprintf("user%d@example.com", i)
+		age := r.Intn(50) + 18
+
+		_, err := s.Exec(ctx, `
+			INSERT INTO test_schema.users (name, email, age)
+			VALUES ($1, $2, $3)
+		`, name, email, age)
+		if err != nil {
+			return fmt.Errorf("не удалось вставить данные: %w", err)
+		}
+	}
+	return nil
+}
+
+// FetchUsers возвращает все строки test_schema.users
+func FetchUsers(ctx context.Context, s *DBSession) ([]TestUser, error) {
+	var users []TestUser
+	err := s.Query(ctx, `
+		SELECT name, email, age
+		FROM test_schema.users;
+	`, nil, func(rows pgx.Rows) error {
+		var user TestUser
+		if err := rows.Scan(&user.Name, &user.Email, &user.Age); err != nil {
+			return err
+		}
+		users = append(users, user)
+		return nil
+	})
+	return users, err
+}
+
+// WorkloadConfig содержит параметры конкурентной нагрузки на базу данных.
+type WorkloadConfig struct {
+	Clients  int
+	Duration time.Duration
+	// WriteRatio задаёт долю операций записи от 0 до 1
+	WriteRatio float64
+	// Observe, если задан, вызывается после каждой операции с её задержкой; вызывается из нескольких горутин
+	Observe func(write bool, latency time.Duration, err error)
+}
+
+// WorkloadStats содержит итоги конкурентной нагрузки.
+type WorkloadStats struct {
+	Reads  int64
+	Writes int64
+	Errors int64
+	// LastError хранит текст последней ошибки для диагностики
+	LastError string
+}
+
+// LoadWorkloadConfig считывает параметры нагрузки из переменных окружения
+func LoadWorkloadConfig(t *testing.T, pool PoolConfig) WorkloadConfig {
+	cfg := WorkloadConfig{
+		Clients:    int(pool.MaxConns),
+		Duration:   10 * time.Second,
+		WriteRatio: 0.5,
+	}
+	if v := os.Getenv("DB_WORKLOAD_CLIENTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			Fatalf(t, "Некорректное значение DB_WORKLOAD_CLIENTS: %q", v)
+		}
+		cfg.Clients = n
+	}
+	if v := os.Getenv("DB_WORKLOAD_DURATION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			Fatalf(t, "Некорректное значение DB_WORKLOAD_DURATION: %q", v)
+		}
+		cfg.Duration = d
+	}
+	return cfg
+}
+
+// CreateWorkloadTable создаёт таблицу, в которую пишет генератор нагрузки
+func CreateWorkloadTable(ctx context.Context, s *DBSession) error {
+	_, err := s.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS test_schema.workload_events (
+			id BIGSERIAL PRIMARY KEY,
+			client_id INT NOT NULL,
+			payload TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("не удалось создать таблицу нагрузки: %w", err)
+	}
+	return nil
+}
+
+// RunWorkload запускает cfg.Clients конкурентных клиентов, выполняющих чтение и запись
+// в test_schema.workload_events, пока не истечёт cfg.Duration или не будет отменён ctx
+func RunWorkload(ctx context.Context, s *DBSession, cfg WorkloadConfig) WorkloadStats {
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	var stats WorkloadStats
+	var lastErr atomic.Value
+	var wg sync.WaitGroup
+
+	for c := 0; c < cfg.Clients; c++ {
+		wg.Add(1)
+		go func(clientID int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(time.Now().UnixNano() + int64(clientID)))
+
+			for ctx.Err() == nil {
+				var err error
+				started := time.Now()
+				write := r.Float64() < cfg.WriteRatio
+				if write {
+					_, err = s.Exec(ctx, `
+						INSERT INTO test_schema.workload_events (client_id, payload)
+						VALUES ($1, $2)
+					`, clientID, fmt.Sprintf("event-%d", r.Int63()))
+					if err == nil {
+						atomic.AddInt64(&stats.Writes, 1)
+					}
+				} else {
+					var count int64
+					err = s.QueryRow(ctx, `
+						SELECT count(*) FROM test_schema.workload_events WHERE client_id = $1
+					`, []interface{}{clientID}, &count)
+					if err == nil {
+						atomic.AddInt64(&stats.Reads, 1)
+					}
+				}
+				// Ошибки из-за окончания нагрузки не считаем
+				if cfg.Observe != nil && (err == nil || ctx.Err() == nil) {
+					cfg.Observe(write, time.Since(started), err)
+				}
+				if err != nil && ctx.Err() == nil {
+					atomic.AddInt64(&stats.Errors, 1)
+					lastErr.Store(err.Error())
+					// Не перегружаем недоступную базу, например во время перезапуска кластера
+					time.Sleep(100 * time.Millisecond)
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+
+	if v, ok := lastErr.Load().(string); ok {
+		stats.LastError = v
+	}
+	return stats
+}